@@ -5,14 +5,21 @@ import (
 	"fmt"
 	"log"
 	"os/exec"
+	"path/filepath"
 	goruntime "runtime"
 	"sync"
+	"time"
 
 	"strava-overlay/internal/auth"
+	"strava-overlay/internal/cache"
 	"strava-overlay/internal/config"
+	"strava-overlay/internal/export"
+	"strava-overlay/internal/gps"
 	"strava-overlay/internal/handlers"
+	"strava-overlay/internal/overlay"
 	"strava-overlay/internal/services"
 	"strava-overlay/internal/strava"
+	"strava-overlay/internal/video"
 
 	"github.com/gen2brain/beeep"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -34,6 +41,11 @@ type App struct {
 
 	processingCancel context.CancelFunc
 	processingMutex  sync.Mutex
+
+	// Cache da conta ativa, recriado quando a conta muda (troca, inclusão ou logout)
+	cacheMutex sync.Mutex
+	cache      *cache.CacheManager
+	cacheKey   string
 }
 
 func NewApp() *App {
@@ -44,10 +56,74 @@ func NewApp() *App {
 		log.Fatal("STRAVA_CLIENT_ID and STRAVA_CLIENT_SECRET must be set")
 	}
 
-	stravaAuth := auth.NewStravaAuth(clientID, clientSecret)
+	dataDir := config.GetDataDir()
+	newTokenStore := func(key string) auth.TokenStore {
+		return auth.NewTokenStore(auth.TokenStoreOptions{
+			Backend:    config.AppConfig.TokenStorage,
+			Dir:        dataDir,
+			Account:    key,
+			Passphrase: config.AppConfig.TokenPassphrase,
+		})
+	}
+
+	legacyStore := newTokenStore(auth.Account{}.Key())
+	if err := auth.MigrateLegacyTokenFile(filepath.Join(dataDir, "token.json"), legacyStore); err != nil {
+		log.Printf("⚠️ Falha ao migrar token legado: %v", err)
+	}
+	log.Printf("🔐 Tokens armazenados em: %s", legacyStore.Name())
+
+	accounts := auth.NewAccountManager(dataDir, newTokenStore)
+	stravaAuth := auth.NewStravaAuth(clientID, clientSecret, accounts)
+
+	callbackOptions, err := auth.ParseCallbackOptions(config.AppConfig.OAuthCallbackPorts, config.AppConfig.OAuthTimeout)
+	if err != nil {
+		log.Printf("⚠️ %v - usando configuração padrão do callback OAuth", err)
+	}
+	stravaAuth.SetCallbackOptions(callbackOptions)
 
 	videoService := services.NewVideoService()
+	videoService.SetGMeterStyle(config.AppConfig.OverlayGMeterStyle)
+	videoService.SetOverlayWidgets(overlay.ParseWidgets(config.AppConfig.OverlayWidgets))
+	videoService.SetStopBehavior(config.AppConfig.OverlayStopBehavior)
+	videoService.SetSegmentBanner(config.AppConfig.OverlaySegmentBanner)
+	videoService.SetShowPaused(config.AppConfig.OverlayShowPaused)
+	videoService.SetTrackExportFormats(export.ParseFormats(config.AppConfig.TrackExportFormats))
+	videoService.SetSubtitleFormats(export.ParseSubtitleFormats(config.AppConfig.SubtitleFormats))
+	videoService.SetMuxSubtitles(config.AppConfig.SubtitleMux)
+	videoService.SetOutputContainer(config.AppConfig.OutputContainer)
+	videoService.SetEmbedTelemetry(config.AppConfig.EmbedTelemetry)
+	draftSettings, err := video.ParseDraftSettings(config.AppConfig.DraftHeight, config.AppConfig.DraftFrameRate, config.AppConfig.DraftDuration)
+	if err != nil {
+		log.Printf("⚠️ %v - usando rascunho padrão (480p, 15 fps, 30s)", err)
+	}
+	videoService.SetDraftSettings(draftSettings)
+	overlayScale, err := overlay.ParseScale(config.AppConfig.OverlayScale)
+	if err != nil {
+		log.Printf("⚠️ %v - usando escala 1", err)
+	}
+	videoService.SetOverlayScale(overlayScale)
+	if _, err := export.NewSubtitles(config.AppConfig.SubtitleTemplate); err != nil {
+		log.Printf("⚠️ %v - usando o modelo padrão", err)
+	} else {
+		videoService.SetSubtitleTemplate(config.AppConfig.SubtitleTemplate)
+	}
+
 	gpsService := services.NewGPSService()
+	streamOptions, err := strava.ParseStreamOptions(config.AppConfig.StreamResolution, config.AppConfig.StreamSeriesType)
+	if err != nil {
+		log.Printf("⚠️ %v - usando streams na resolução original", err)
+	}
+	gpsService.SetStreamOptions(streamOptions)
+	highlightConfig, err := gps.ParseHighlightConfig(config.AppConfig.HighlightCount, config.AppConfig.HighlightWindow)
+	if err != nil {
+		log.Printf("⚠️ %v - usando destaques padrão (5 de 20s)", err)
+	}
+	gpsService.SetHighlightConfig(highlightConfig)
+	filterConfig, err := gps.ParseFilterConfig(config.AppConfig.FilterMaxSpeed, config.AppConfig.FilterKalman)
+	if err != nil {
+		log.Printf("⚠️ %v - usando os filtros padrão", err)
+	}
+	gpsService.SetFilterConfig(filterConfig)
 
 	app := &App{
 		stravaAuth:   stravaAuth,
@@ -60,6 +136,7 @@ func NewApp() *App {
 	app.videoHandler = handlers.NewVideoHandler(app.getStravaClient, videoService, gpsService)
 	app.gpsHandler = handlers.NewGPSHandler(app.getStravaClient, gpsService)
 	app.configHandler = handlers.NewConfigHandler()
+	gpsService.SetCacheProvider(app.accountCache)
 
 	return app
 }
@@ -76,22 +153,66 @@ func (a *App) Startup(ctx context.Context) {
 		})
 	})
 
-	a.videoService.SetCompletionCallback(func(success bool, outputPath string, err error) {
+	a.videoService.SetCompletionCallback(func(success bool, outputPath string, draft bool, err error) {
 		if success {
 			runtime.EventsEmit(ctx, "video:completed", map[string]interface{}{
 				"success":    true,
 				"outputPath": outputPath,
+				"draft":      draft,
 			})
 		} else {
 			runtime.EventsEmit(ctx, "video:completed", map[string]interface{}{
 				"success": false,
 				"error":   err.Error(),
+				"draft":   draft,
 			})
 		}
 	})
 }
 
 func (a *App) ProcessVideoOverlay(activityID int64, videoPath string, manualStartTimeStr string, overlayPosition string) (string, error) {
+	return a.processVideo(activityID, videoPath, manualStartTimeStr, overlayPosition, services.RenderOptions{})
+}
+
+// ProcessVideoWindows renderiza apenas os trechos informados; vários trechos são concatenados
+// em um único vídeo (highlight reel)
+func (a *App) ProcessVideoWindows(activityID int64, videoPath string, manualStartTimeStr string, overlayPosition string, windows []handlers.FrontendTimeWindow) (string, error) {
+	parsed, err := handlers.ParseTimeWindows(windows)
+	if err != nil {
+		return "", err
+	}
+	return a.processVideo(activityID, videoPath, manualStartTimeStr, overlayPosition, services.RenderOptions{Windows: parsed})
+}
+
+// ProcessVideoDraft gera um rascunho rápido (baixa resolução, ultrafast) de durationSeconds a
+// partir de startSeconds do vídeo; duração zero usa a duração padrão dos rascunhos. Com
+// trechos, o rascunho cobre os trechos em vez da janela.
+func (a *App) ProcessVideoDraft(activityID int64, videoPath string, manualStartTimeStr string, overlayPosition string, startSeconds, durationSeconds float64, windows []handlers.FrontendTimeWindow) (string, error) {
+	draft := a.videoService.DraftSettings()
+	draft.Start = time.Duration(startSeconds * float64(time.Second))
+	if durationSeconds > 0 {
+		draft.Duration = time.Duration(durationSeconds * float64(time.Second))
+	}
+
+	parsed, err := handlers.ParseTimeWindows(windows)
+	if err != nil {
+		return "", err
+	}
+	return a.processVideo(activityID, videoPath, manualStartTimeStr, overlayPosition, services.RenderOptions{Windows: parsed, Draft: &draft})
+}
+
+// ProcessVideoHighlights detecta automaticamente até count destaques na telemetria do trecho
+// filmado e os corta, em ordem cronológica, em um vídeo de destaques com overlay. count zero
+// usa o número configurado.
+func (a *App) ProcessVideoHighlights(activityID int64, videoPath string, manualStartTimeStr string, overlayPosition string, count int) (string, error) {
+	if count <= 0 {
+		count = a.gpsService.HighlightConfig().MaxHighlights
+	}
+	return a.processVideo(activityID, videoPath, manualStartTimeStr, overlayPosition, services.RenderOptions{Highlights: count})
+}
+
+// processVideo executa a renderização com um contexto cancelável por CancelVideoProcessing
+func (a *App) processVideo(activityID int64, videoPath string, manualStartTimeStr string, overlayPosition string, options services.RenderOptions) (string, error) {
 	a.processingMutex.Lock()
 
 	// Cria contexto cancelável
@@ -118,6 +239,7 @@ func (a *App) ProcessVideoOverlay(activityID int64, videoPath string, manualStar
 		manualStartTimeStr,
 		overlayPosition,
 		a.gpsService,
+		options,
 	)
 }
 
@@ -142,6 +264,31 @@ func (a *App) getStravaClient() *strava.Client {
 	return a.stravaClient
 }
 
+// accountCache retorna o cache da conta ativa, trocando de diretório quando a conta muda.
+// Sem conta ativa não há cache.
+func (a *App) accountCache() *cache.CacheManager {
+	account, ok := a.stravaAuth.Accounts().Active()
+	if !ok {
+		return nil
+	}
+
+	a.cacheMutex.Lock()
+	defer a.cacheMutex.Unlock()
+
+	if a.cache == nil || a.cacheKey != account.Key() {
+		a.cache = cache.NewCacheManager(account.Key())
+		a.cacheKey = account.Key()
+		log.Printf("🗄️ Cache da conta %s em %s", account.DisplayName(), a.cache.Dir())
+	}
+	return a.cache
+}
+
+// PreviewOverlayFrame retorna um quadro do vídeo com o overlay aplicado, para ajustar posição
+// e estilo sem renderizar o vídeo inteiro
+func (a *App) PreviewOverlayFrame(activityID int64, videoPath string, manualStartTimeStr string, overlayPosition string, videoTimeSeconds float64) (string, error) {
+	return a.videoHandler.PreviewOverlayFrame(activityID, videoPath, manualStartTimeStr, overlayPosition, videoTimeSeconds)
+}
+
 func (a *App) SelectVideoFile() (string, error) {
 	return runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
 		Title:   "Selecione um arquivo de vídeo",
@@ -171,6 +318,26 @@ func (a *App) AuthenticateStrava() error {
 	return a.authHandler.AuthenticateStrava(a.ctx)
 }
 
+func (a *App) Logout() handlers.AuthStatus {
+	return a.authHandler.Logout(a.ctx)
+}
+
+func (a *App) RequestPrivateActivitiesAccess() handlers.AuthStatus {
+	return a.authHandler.RequestPrivateActivitiesAccess(a.ctx)
+}
+
+func (a *App) ListAccounts() []handlers.FrontendAccount {
+	return a.authHandler.ListAccounts()
+}
+
+func (a *App) SwitchAccount(athleteID int64) handlers.AuthStatus {
+	return a.authHandler.SwitchAccount(a.ctx, athleteID)
+}
+
+func (a *App) AddAccount() handlers.AuthStatus {
+	return a.authHandler.AddAccount(a.ctx)
+}
+
 func (a *App) GetActivitiesPage(page int) (*handlers.PaginatedActivities, error) {
 	return a.activityHandler.GetActivitiesPage(page)
 }
@@ -187,10 +354,34 @@ func (a *App) GetGPSPointForVideoTime(activityID int64, videoPath string) (handl
 	return a.gpsHandler.GetGPSPointForVideoTime(activityID, videoPath)
 }
 
+func (a *App) GetTrackSegments(activityID int64) ([]handlers.FrontendTrackSegment, error) {
+	return a.gpsHandler.GetTrackSegments(activityID)
+}
+
+func (a *App) GetActivityLaps(activityID int64) ([]handlers.FrontendLap, error) {
+	return a.gpsHandler.GetActivityLaps(activityID)
+}
+
+func (a *App) GetSegmentEfforts(activityID int64) ([]handlers.FrontendSegmentEffort, error) {
+	return a.gpsHandler.GetSegmentEfforts(activityID)
+}
+
+func (a *App) DetectHighlights(activityID int64, clips []handlers.FrontendHighlightClip) ([]handlers.FrontendHighlight, error) {
+	return a.gpsHandler.DetectHighlights(activityID, clips)
+}
+
+func (a *App) FindActivitiesForVideo(videoPath string) ([]handlers.FrontendActivityMatch, error) {
+	return a.gpsHandler.FindActivitiesForVideo(videoPath)
+}
+
 func (a *App) GetGPSPointForMapClick(activityID int64, lat, lng float64) (handlers.FrontendGPSPoint, error) {
 	return a.gpsHandler.GetGPSPointForMapClick(activityID, lat, lng)
 }
 
+func (a *App) GetGPSPointForSegmentClick(activityID int64, lat, lng float64, from, to string) (handlers.FrontendGPSPoint, error) {
+	return a.gpsHandler.GetGPSPointForSegmentClick(activityID, lat, lng, from, to)
+}
+
 func (a *App) GetAllGPSPoints(activityID int64) ([]handlers.FrontendGPSPoint, error) {
 	return a.gpsHandler.GetAllGPSPoints(activityID)
 }
@@ -203,6 +394,14 @@ func (a *App) GetGPSPointsWithDensity(activityID int64, density string) ([]handl
 	return a.gpsHandler.GetGPSPointsWithDensity(activityID, density)
 }
 
+func (a *App) GetSimplifiedTrajectory(activityID int64, options handlers.FrontendSimplifyOptions) ([]handlers.FrontendGPSPoint, error) {
+	return a.gpsHandler.GetSimplifiedTrajectory(activityID, options)
+}
+
+func (a *App) GetEncodedGPSTrajectory(activityID int64, density string) (string, error) {
+	return a.gpsHandler.GetEncodedGPSTrajectory(activityID, density)
+}
+
 // SendDesktopNotification envia notificação nativa
 func (a *App) SendDesktopNotification(title, body string) {
 	runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
@@ -118,6 +118,7 @@ function createActivityCard(activity) {
         : `<span class="gps-badge no-gps">${window.t('activities.noGPS', 'Sem GPS')}</span>`;
     
     const activityIcon = getActivityIcon(activity.type);
+    const thumbnail = createRouteThumbnail(activity.thumbnail);
 
     card.innerHTML = `
         <div style="display: flex; justify-content: space-between; align-items: flex-start; margin-bottom: 8px;">
@@ -135,11 +136,31 @@ function createActivityCard(activity) {
                 <strong style="color: var(--primary-text);">🕒 ${timeStr}</strong>
             </div>
         </div>
+        ${thumbnail}
     `;
     
     return card;
 }
 
+/**
+ * Desenha a miniatura do trajeto (pontos normalizados enviados pelo backend) como SVG
+ */
+function createRouteThumbnail(points) {
+    if (!points || points.length < 2) return '';
+
+    const path = points
+        .map(([x, y], i) => `${i === 0 ? 'M' : 'L'}${(x * 100).toFixed(1)} ${(y * 100).toFixed(1)}`)
+        .join(' ');
+
+    return `
+        <svg class="route-thumbnail" viewBox="-5 -5 110 110" preserveAspectRatio="xMidYMid meet"
+             style="display: block; width: 100%; height: 70px; margin-top: 8px;">
+            <path d="${path}" fill="none" stroke="var(--accent-color, #fc4c02)" stroke-width="3"
+                  stroke-linejoin="round" stroke-linecap="round" vector-effect="non-scaling-stroke" />
+        </svg>
+    `;
+}
+
 /**
  * Seleciona uma atividade
  */
@@ -154,6 +154,12 @@ function initializeDOMElements() {
 function addEventListeners() {
     if (selectVideoBtn) selectVideoBtn.addEventListener('click', selectVideo);
     if (processBtn) processBtn.addEventListener('click', processVideo);
+    const previewBtn = document.getElementById('previewBtn');
+    if (previewBtn) previewBtn.addEventListener('click', previewOverlayFrame);
+    const draftBtn = document.getElementById('draftBtn');
+    if (draftBtn) draftBtn.addEventListener('click', processDraft);
+    const highlightsBtn = document.getElementById('highlightsBtn');
+    if (highlightsBtn) highlightsBtn.addEventListener('click', detectHighlights);
     if (loadMoreBtn) loadMoreBtn.addEventListener('click', loadMoreActivities);
     if (filterGPSCheckbox) filterGPSCheckbox.addEventListener('change', handleFilterChange);
     if (refreshActivitiesBtn) refreshActivitiesBtn.addEventListener('click', refreshActivities);
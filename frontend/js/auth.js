@@ -43,6 +43,7 @@ function handleAuthSuccess(response) {
 
     updateHeaderStatus('connected', 'Conectado ao Strava');
     hideAuthButton();
+    showLogoutButton();
     if (activitiesSection) activitiesSection.classList.remove('hidden');
 
     loadActivitiesPage(1); // Carrega a primeira página de atividades
@@ -87,6 +88,7 @@ async function authenticateStrava() {
 
         isAuthenticated = true;
         updateHeaderStatus('connected', 'Conectado ao Strava');
+        showLogoutButton();
         if (activitiesSection) activitiesSection.classList.remove('hidden');
 
         loadActivitiesPage(1);
@@ -101,6 +103,38 @@ async function authenticateStrava() {
     }
 }
 
+/**
+ * Desconecta a conta ativa: revoga o acesso no Strava e remove o token salvo. Se outra
+ * conta conectada assumir, passa a exibir as atividades dela.
+ */
+async function logoutStrava() {
+    if (isCheckingAuth) return;
+
+    try {
+        isCheckingAuth = true;
+        hideLogoutButton();
+
+        const response = await window.go.main.App.Logout();
+        if (response?.error) {
+            console.warn('⚠️ Logout com erro:', response.error);
+        }
+
+        // Outra conta conectada assumiu: mostra as atividades dela
+        if (response?.is_authenticated) {
+            handleAuthSuccess(response);
+            return;
+        }
+    } catch (error) {
+        console.error('❌ Erro ao sair:', error);
+    } finally {
+        isCheckingAuth = false;
+    }
+
+    isAuthenticated = false;
+    if (activitiesSection) activitiesSection.classList.add('hidden');
+    handleAuthFailure();
+}
+
 // === FUNÇÕES PARA MANIPULAR O HEADER ===
 
 /**
@@ -147,4 +181,26 @@ function hideAuthButton() {
         authBtn.classList.remove('show');
         authBtn.onclick = null;
     }
-}
\ No newline at end of file
+}
+
+/**
+ * Mostra o botão de logout.
+ */
+function showLogoutButton() {
+    const logoutBtn = document.getElementById('logoutBtn');
+    if (logoutBtn) {
+        logoutBtn.classList.add('show');
+        logoutBtn.onclick = logoutStrava;
+    }
+}
+
+/**
+ * Esconde o botão de logout.
+ */
+function hideLogoutButton() {
+    const logoutBtn = document.getElementById('logoutBtn');
+    if (logoutBtn) {
+        logoutBtn.classList.remove('show');
+        logoutBtn.onclick = null;
+    }
+}
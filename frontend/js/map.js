@@ -133,6 +133,30 @@ async function displayMap(activity) {
     }
 }
 
+// Limite de pontos do trajeto exibido; cada ponto vira um segmento colorido no mapa
+const MAX_TRAJECTORY_POINTS = 3000;
+
+/**
+ * Retorna o zoom em que o trajeto será exibido: o do enquadramento da polilinha resumida ou,
+ * sem ela, o zoom atual do mapa.
+ * @param {object} activity - A atividade a exibir.
+ * @returns {number} Nível de zoom do Leaflet.
+ */
+function trajectoryZoom(activity) {
+    try {
+        if (activity.map && activity.map.summary_polyline) {
+            const latlngs = decodePolyline(activity.map.summary_polyline);
+            if (latlngs.length > 1) {
+                // Mesmo padding de 20px por lado usado no fitBounds
+                return activityMap.getBoundsZoom(L.latLngBounds(latlngs), false, L.point(40, 40));
+            }
+        }
+    } catch (error) {
+        console.warn('⚠️ Não foi possível calcular o zoom do trajeto:', error);
+    }
+    return Math.round(activityMap.getZoom());
+}
+
 /**
  * Carrega e exibe a trajetória interpolada com gradiente de velocidade.
  * @param {object} activity - A atividade para a qual carregar a trajetória.
@@ -142,7 +166,14 @@ async function loadInterpolatedTrajectory(activity) {
         console.log("📈 Carregando trajeto detalhado...");
         showMessage(result, window.t('video.messages.loadingTrajectory', 'Carregando trajeto detalhado...'), 'info');
 
-        const fullTrajectory = await window.go.main.App.GetFullGPSTrajectory(activity.id);
+        // Trajeto simplificado no zoom em que o mapa vai exibi-lo: mantém o formato, paradas e
+        // curvas com bem menos pontos, sem ultrapassar o limite de segmentos
+        const fullTrajectory = await window.go.main.App.GetSimplifiedTrajectory(activity.id, {
+            zoom: trajectoryZoom(activity),
+            tolerance_meters: 0,
+            target_points: MAX_TRAJECTORY_POINTS,
+            algorithm: 'douglas-peucker'
+        });
 
         if (!fullTrajectory || fullTrajectory.length === 0) {
             console.log("⚠️ Sem dados de trajeto completo, usando trajeto simplificado");
@@ -168,6 +199,15 @@ async function loadInterpolatedTrajectory(activity) {
         L.marker([endPoint.lat, endPoint.lng]).addTo(activityMap)
            .bindPopup(window.t('map.markers.end', '🏆 Fim da atividade'));
 
+        // Marca paradas, pausas automáticas e falhas de dados
+        await addTrackSegmentMarkers(activity);
+
+        // Destaca os segmentos do Strava percorridos na atividade
+        await addSegmentEffortLines(activity);
+
+        // Lista as voltas com navegação até o início de cada uma
+        await displayLapList(activity);
+
         // Ajusta a visualização para mostrar toda a trajetória
         const bounds = L.latLngBounds(fullTrajectory.map(p => [p.lat, p.lng]));
         activityMap.fitBounds(bounds, { padding: [20, 20] });
@@ -186,6 +226,146 @@ async function loadInterpolatedTrajectory(activity) {
     }
 }
 
+/**
+ * Adiciona marcadores para as paradas, pausas automáticas e falhas de dados da atividade.
+ * @param {object} activity - A atividade exibida no mapa.
+ */
+async function addTrackSegmentMarkers(activity) {
+    try {
+        const segments = await window.go.main.App.GetTrackSegments(activity.id);
+        if (!segments || segments.length === 0) {
+            return;
+        }
+
+        const styles = {
+            stop: { color: '#f0ad4e', label: window.t('map.markers.stop', 'Parada') },
+            auto_pause: { color: '#5bc0de', label: window.t('map.markers.autoPause', 'Pausa automática') },
+            gap: { color: '#d9534f', label: window.t('map.markers.gap', 'Falha de dados') }
+        };
+
+        segments.forEach(segment => {
+            const style = styles[segment.type] || styles.stop;
+            const minutes = Math.floor(segment.duration_seconds / 60);
+            const seconds = Math.round(segment.duration_seconds % 60).toString().padStart(2, '0');
+
+            L.circleMarker([segment.lat, segment.lng], {
+                radius: 7,
+                color: '#ffffff',
+                weight: 2,
+                fillColor: style.color,
+                fillOpacity: 0.9
+            }).addTo(activityMap).bindPopup(`
+                <div style="font-size: 12px;">
+                    <strong>⏸️ ${style.label}</strong><br>
+                    ⏰ ${new Date(segment.start).toLocaleTimeString()} - ${new Date(segment.end).toLocaleTimeString()}<br>
+                    ⌛ ${window.t('map.markers.segmentDuration', 'Duração')}: ${minutes}:${seconds}
+                </div>
+            `);
+        });
+
+        console.log(`⏸️ ${segments.length} paradas/pausas/falhas marcadas no mapa`);
+    } catch (error) {
+        console.warn('⚠️ Não foi possível carregar as paradas da atividade:', error);
+    }
+}
+
+/**
+ * Desenha os trechos dos segmentos do Strava percorridos na atividade.
+ * @param {object} activity - A atividade exibida no mapa.
+ */
+async function addSegmentEffortLines(activity) {
+    try {
+        const efforts = await window.go.main.App.GetSegmentEfforts(activity.id);
+        if (!efforts || efforts.length === 0) {
+            return;
+        }
+
+        // Painel abaixo do trajeto para não bloquear o clique de sincronização
+        if (!activityMap.getPane('segmentEfforts')) {
+            activityMap.createPane('segmentEfforts').style.zIndex = 390;
+        }
+
+        efforts.forEach(effort => {
+            if (!effort.polyline || effort.polyline.length < 2) return;
+
+            let rank = '';
+            if (effort.kom_rank > 0) {
+                rank = effort.kom_rank === 1 ? '👑 KOM' : `🏆 Top ${effort.kom_rank}`;
+            } else if (effort.pr_rank > 0) {
+                rank = effort.pr_rank === 1 ? '⭐ PR' : `⭐ PR #${effort.pr_rank}`;
+            }
+
+            L.polyline(effort.polyline, {
+                pane: 'segmentEfforts',
+                color: '#fc4c02',
+                weight: 10,
+                opacity: 0.45
+            }).addTo(activityMap).bindPopup(`
+                <div style="font-size: 12px;">
+                    <strong>🏔️ ${window.t('map.markers.segment', 'Segmento')}: ${effort.name}</strong><br>
+                    📏 ${(effort.distance / 1000).toFixed(2)} km · ${effort.average_grade.toFixed(1)}%<br>
+                    ⏱️ ${window.t('map.markers.segmentTime', 'Tempo')}: ${formatDuration(effort.elapsed_time)}
+                    ${rank ? `<br>${window.t('map.markers.segmentRank', 'Posição')}: ${rank}` : ''}
+                </div>
+            `);
+        });
+
+        console.log(`🏔️ ${efforts.length} segmentos destacados no mapa`);
+    } catch (error) {
+        console.warn('⚠️ Não foi possível carregar os segmentos da atividade:', error);
+    }
+}
+
+/**
+ * Lista as voltas da atividade nos detalhes; clicar numa volta centraliza o mapa no seu início.
+ * @param {object} activity - A atividade exibida no mapa.
+ */
+async function displayLapList(activity) {
+    const infoGrid = activityInfo ? activityInfo.querySelector('.info-grid') : null;
+    if (!infoGrid) return;
+
+    const previous = infoGrid.querySelector('.laps-item');
+    if (previous) previous.remove();
+
+    try {
+        const laps = await window.go.main.App.GetActivityLaps(activity.id);
+        if (!laps || laps.length < 2) {
+            return;
+        }
+
+        const lapLabel = window.t('activityDetail.laps.lap', 'Volta');
+        const item = document.createElement('div');
+        item.className = 'info-item laps-item';
+        item.innerHTML = `
+            <h4>${window.t('activityDetail.laps.title', 'Voltas')}</h4>
+            ${laps.map((lap, index) => `
+                <p class="lap-entry" data-index="${index}" style="cursor: pointer;">
+                    <strong>${lapLabel} ${lap.number}:</strong>
+                    ${(lap.distance / 1000).toFixed(2)} km · ${formatDuration(lap.elapsed_time)}
+                </p>
+            `).join('')}
+        `;
+
+        item.querySelectorAll('.lap-entry').forEach(entry => {
+            entry.addEventListener('click', () => {
+                const lap = laps[Number(entry.dataset.index)];
+                if (!activityMap || (!lap.lat && !lap.lng)) return;
+
+                activityMap.setView([lap.lat, lap.lng], 16);
+                L.popup()
+                    .setLatLng([lap.lat, lap.lng])
+                    .setContent(`🏁 ${lapLabel} ${lap.number} · ${new Date(lap.start).toLocaleTimeString()}`)
+                    .openOn(activityMap);
+            });
+        });
+
+        infoGrid.appendChild(item);
+        console.log(`🏁 ${laps.length} voltas listadas`);
+    } catch (error) {
+        console.warn('⚠️ Não foi possível carregar as voltas da atividade:', error);
+    }
+}
+
 /**
  * Cria a polilinha no mapa colorida pela velocidade - VERSÃO CORRIGIDA.
  * @param {Array} trajectoryPoints - Os pontos da trajetória.
@@ -235,7 +415,7 @@ function createSpeedGradientTrajectory(trajectoryPoints) {
         
         // Adiciona handler de clique para sincronização
         segmentLine.on('click', (e) => {
-            handleSegmentClick(e, currentPoint);
+            handleSegmentClick(e, currentPoint, nextPoint);
         });
         
         // Adiciona ao grupo
@@ -261,9 +441,21 @@ function createSpeedGradientTrajectory(trajectoryPoints) {
 /**
  * Handler de clique otimizado para segmentos individuais.
  * @param {L.LeafletMouseEvent} e - Evento de clique
- * @param {object} point - Ponto GPS do segmento
+ * @param {object} point - Ponto GPS do início do segmento
+ * @param {object} nextPoint - Ponto GPS do fim do segmento
  */
-function handleSegmentClick(e, point) {
+async function handleSegmentClick(e, point, nextPoint) {
+    // O trajeto exibido é simplificado: refina o clique na trilha completa, mas só entre os
+    // instantes das pontas do segmento, para não cair em outra passagem pelo mesmo lugar
+    if (selectedActivity && e && e.latlng && nextPoint) {
+        try {
+            point = await window.go.main.App.GetGPSPointForSegmentClick(
+                selectedActivity.id, e.latlng.lat, e.latlng.lng, point.time, nextPoint.time);
+        } catch (error) {
+            console.warn('⚠️ Usando o ponto do segmento para sincronização:', error);
+        }
+    }
+
     console.log(`🖱️ Clique no segmento: ${point.time}`);
     
     manualSyncTime = point.time;
@@ -420,7 +612,7 @@ function loadFallbackTrajectory(activity) {
     
     try {
         if (activity.map && activity.map.summary_polyline) {
-            const latlngs = L.Polyline.fromEncoded(activity.map.summary_polyline).getLatLngs();
+            const latlngs = decodePolyline(activity.map.summary_polyline);
             
             activityPolyline = L.polyline(latlngs, { 
                 color: '#f85149', 
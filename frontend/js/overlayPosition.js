@@ -39,6 +39,10 @@ function handlePositionSelection(event) {
     
     // Feedback visual adicional
     showMessage(result, `Overlay será posicionado no ${getPositionLabel(position)}`, 'info');
+
+    if (typeof refreshOverlayPreview === 'function') {
+        refreshOverlayPreview();
+    }
 }
 
 /**
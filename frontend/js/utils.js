@@ -23,6 +23,32 @@ function formatTime(date) {
     });
 }
 
+/**
+ * Decodifica uma polilinha no formato do Google em pares [lat, lng]
+ */
+function decodePolyline(encoded) {
+    const coords = [];
+    let index = 0, lat = 0, lng = 0;
+
+    const nextValue = () => {
+        let result = 0, shift = 0, byte;
+        do {
+            if (index >= encoded.length) throw new Error('Polilinha truncada');
+            byte = encoded.charCodeAt(index++) - 63;
+            result |= (byte & 0x1f) << shift;
+            shift += 5;
+        } while (byte >= 0x20);
+        return (result & 1) ? ~(result >> 1) : (result >> 1);
+    };
+
+    while (index < encoded.length) {
+        lat += nextValue();
+        lng += nextValue();
+        coords.push([lat / 1e5, lng / 1e5]);
+    }
+    return coords;
+}
+
 /**
  * Converte segundos em uma string de duração (ex: "1h 30m" ou "45m 10s")
  */
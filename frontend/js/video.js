@@ -35,6 +35,7 @@ async function selectVideo() {
         if (window.overlayPosition) {
             window.overlayPosition.show();
         }
+        showOverlayPreviewControl();
 
         console.log("Buscando ponto GPS para sincronização automática...");
         const point = await window.go.main.App.GetGPSPointForVideoTime(selectedActivity.id, path);
@@ -50,15 +51,205 @@ async function selectVideo() {
     }
 }
 
+/**
+ * Exibe o controle de pré-visualização para o vídeo recém-selecionado.
+ */
+function showOverlayPreviewControl() {
+    const control = document.getElementById('overlayPreviewControl');
+    const image = document.getElementById('overlayPreviewImage');
+    const highlightsList = document.getElementById('highlightsList');
+    if (control) control.classList.remove('hidden');
+    if (highlightsList) {
+        highlightsList.classList.add('hidden');
+        highlightsList.innerHTML = '';
+    }
+    if (image) {
+        image.classList.add('hidden');
+        image.removeAttribute('src');
+    }
+}
+
+/**
+ * Gera um único quadro do vídeo com o overlay aplicado, no instante informado,
+ * para ajustar posição e sincronização sem renderizar o vídeo inteiro.
+ */
+async function previewOverlayFrame() {
+    if (!selectedActivity || !selectedVideoPath) {
+        showMessage(result, 'Selecione uma atividade e um vídeo primeiro.', 'error');
+        return;
+    }
+
+    const previewBtn = document.getElementById('previewBtn');
+    const image = document.getElementById('overlayPreviewImage');
+    const seconds = parseFloat(document.getElementById('previewTime')?.value) || 0;
+    const overlayPosition = window.overlayPosition ? window.overlayPosition.getPosition() : 'bottom-left';
+
+    try {
+        if (previewBtn) {
+            previewBtn.disabled = true;
+            previewBtn.textContent = window.t('video.preview.loading', 'Gerando pré-visualização...');
+        }
+
+        const dataURL = await window.go.main.App.PreviewOverlayFrame(
+            selectedActivity.id,
+            selectedVideoPath,
+            manualSyncTime,
+            overlayPosition,
+            seconds
+        );
+
+        if (image) {
+            image.src = dataURL;
+            image.classList.remove('hidden');
+        }
+    } catch (error) {
+        showMessage(result, `${window.t('video.preview.error', 'Erro na pré-visualização')}: ${error}`, 'error');
+    } finally {
+        if (previewBtn) {
+            previewBtn.disabled = false;
+            previewBtn.textContent = window.t('video.preview.button', 'Pré-visualizar Quadro');
+        }
+    }
+}
+
+/**
+ * Atualiza a pré-visualização já exibida (ex.: após trocar a posição do overlay).
+ */
+function refreshOverlayPreview() {
+    const image = document.getElementById('overlayPreviewImage');
+    if (image && !image.classList.contains('hidden')) {
+        previewOverlayFrame();
+    }
+}
+
+/**
+ * Converte "m:ss" ou segundos em segundos.
+ */
+function parseVideoTime(text) {
+    const parts = text.trim().split(':').map(Number);
+    if (parts.some(isNaN)) return NaN;
+    return parts.reduce((total, part) => total * 60 + part, 0);
+}
+
+/**
+ * Lê os trechos a renderizar ("0:10-0:25, 1:30-1:45") em segundos do vídeo.
+ * Vazio renderiza o vídeo inteiro.
+ */
+function getRenderWindows() {
+    const text = document.getElementById('renderWindows')?.value || '';
+    const windows = [];
+
+    for (const range of text.split(',')) {
+        if (!range.trim()) continue;
+
+        const [start, end] = range.split('-').map(parseVideoTime);
+        if (isNaN(start) || isNaN(end) || end <= start) {
+            throw new Error(`Trecho inválido: ${range.trim()}`);
+        }
+        windows.push({ start, end });
+    }
+    return windows;
+}
+
+/**
+ * Formata segundos como "m:ss", o formato aceito no campo de trechos.
+ */
+function formatVideoTime(seconds) {
+    const total = Math.round(seconds);
+    return `${Math.floor(total / 60)}:${String(total % 60).padStart(2, '0')}`;
+}
+
+/**
+ * Detecta os melhores momentos da telemetria no trecho filmado e preenche o campo de
+ * trechos com eles em ordem cronológica, para renderizar um vídeo de destaques.
+ */
+async function detectHighlights() {
+    if (!selectedActivity || !selectedVideoPath) {
+        showMessage(result, 'Selecione uma atividade e um vídeo primeiro.', 'error');
+        return;
+    }
+
+    const highlightsBtn = document.getElementById('highlightsBtn');
+    const list = document.getElementById('highlightsList');
+
+    try {
+        if (highlightsBtn) {
+            highlightsBtn.disabled = true;
+            highlightsBtn.textContent = window.t('video.highlights.loading', 'Analisando telemetria...');
+        }
+
+        const highlights = await window.go.main.App.DetectHighlights(selectedActivity.id, [
+            { video_path: selectedVideoPath, manual_start_time: manualSyncTime || '' }
+        ]);
+
+        const ranges = (highlights || [])
+            .flatMap(highlight => highlight.clips.map(clip => ({ ...clip, highlight })))
+            .sort((a, b) => a.start - b.start);
+
+        if (ranges.length === 0) {
+            showMessage(result, window.t('video.highlights.none', 'Nenhum destaque encontrado no vídeo.'), 'info');
+            return;
+        }
+
+        document.getElementById('renderWindows').value = ranges
+            .map(range => `${formatVideoTime(range.start)}-${formatVideoTime(range.end)}`)
+            .join(', ');
+
+        if (list) {
+            list.innerHTML = '';
+            for (const range of ranges) {
+                const { highlight } = range;
+                const item = document.createElement('li');
+                const reason = window.t(`video.highlights.reason.${highlight.reason}`, highlight.reason);
+                item.textContent = `${formatVideoTime(range.start)}-${formatVideoTime(range.end)} · ${reason}` +
+                    ` · ${highlight.peak_speed.toFixed(1)} km/h` +
+                    (highlight.segment ? ` · ${highlight.segment}` : '') +
+                    ` (${Math.round(highlight.score * 100)}%)`;
+                list.appendChild(item);
+            }
+            list.classList.remove('hidden');
+        }
+    } catch (error) {
+        showMessage(result, `${window.t('video.highlights.error', 'Erro ao detectar destaques')}: ${error}`, 'error');
+    } finally {
+        if (highlightsBtn) {
+            highlightsBtn.disabled = false;
+            highlightsBtn.textContent = window.t('video.highlights.button', 'Destaques Automáticos');
+        }
+    }
+}
+
 /**
  * Envia a atividade e o vídeo para o backend para processamento do overlay.
  */
-async function processVideo() {
+function processVideo() {
+    return renderVideo(false);
+}
+
+/**
+ * Gera um rascunho rápido em baixa resolução do trecho escolhido na pré-visualização.
+ */
+function processDraft() {
+    return renderVideo(true);
+}
+
+/**
+ * Renderiza o vídeo final ou, com draft, um rascunho curto em baixa resolução.
+ */
+async function renderVideo(draft) {
     if (!selectedActivity || !selectedVideoPath) {
         showMessage(result, 'Selecione uma atividade e um vídeo primeiro.', 'error');
         return;
     }
 
+    let windows;
+    try {
+        windows = getRenderWindows();
+    } catch (error) {
+        showMessage(result, error.message, 'error');
+        return;
+    }
+
     try {
         isProcessing = true;
         
@@ -67,6 +258,8 @@ async function processVideo() {
             processBtn.disabled = true;
             processBtn.style.display = 'none';
         }
+        const draftBtn = document.getElementById('draftBtn');
+        if (draftBtn) draftBtn.disabled = true;
         
         showCancelButton();
         
@@ -85,7 +278,7 @@ async function processVideo() {
             if (data.success) {
                 const fileName = data.outputPath.split(/[\\/]/).pop();
                 window.go.main.App.SendNotification(
-                    '✅ Vídeo Processado!',
+                    data.draft ? '📝 Rascunho Pronto!' : '✅ Vídeo Processado!',
                     `Arquivo pronto: ${fileName}`
                 );
             } else {
@@ -100,12 +293,36 @@ async function processVideo() {
         const overlayPosition = window.overlayPosition ? window.overlayPosition.getPosition() : 'bottom-left';
         console.log(`📍 Processando vídeo com overlay na posição: ${overlayPosition}`);
 
-        const outputPath = await window.go.main.App.ProcessVideoOverlay(
-            selectedActivity.id, 
-            selectedVideoPath, 
-            manualSyncTime,
-            overlayPosition
-        );
+        let outputPath;
+        if (draft) {
+            const start = parseFloat(document.getElementById('previewTime')?.value) || 0;
+            const duration = parseFloat(document.getElementById('draftDuration')?.value) || 0;
+            outputPath = await window.go.main.App.ProcessVideoDraft(
+                selectedActivity.id,
+                selectedVideoPath,
+                manualSyncTime,
+                overlayPosition,
+                start,
+                duration,
+                windows
+            );
+        } else if (windows.length > 0) {
+            console.log(`✂️ Renderizando ${windows.length} trecho(s)`);
+            outputPath = await window.go.main.App.ProcessVideoWindows(
+                selectedActivity.id,
+                selectedVideoPath,
+                manualSyncTime,
+                overlayPosition,
+                windows
+            );
+        } else {
+            outputPath = await window.go.main.App.ProcessVideoOverlay(
+                selectedActivity.id, 
+                selectedVideoPath, 
+                manualSyncTime,
+                overlayPosition
+            );
+        }
         
         if (progressUnsubscribe) {
             progressUnsubscribe();
@@ -113,10 +330,14 @@ async function processVideo() {
         }
         
         updateProgress(100);
-        showMessage(result, `Vídeo processado com sucesso!<br><strong>Local:</strong> ${outputPath}`, 'success');
-        
-        if (window.overlayPosition) {
-            window.overlayPosition.hide();
+        if (draft) {
+            showMessage(result, `Rascunho gerado!<br><strong>Local:</strong> ${outputPath}`, 'success');
+        } else {
+            showMessage(result, `Vídeo processado com sucesso!<br><strong>Local:</strong> ${outputPath}`, 'success');
+
+            if (window.overlayPosition) {
+                window.overlayPosition.hide();
+            }
         }
     } catch (error) {
         if (progressUnsubscribe) {
@@ -142,6 +363,8 @@ async function processVideo() {
             processBtn.style.display = 'inline-block';
             processBtn.textContent = 'Processar com Overlay';
         }
+        const draftBtn = document.getElementById('draftBtn');
+        if (draftBtn) draftBtn.disabled = false;
         
         setTimeout(() => {
             if (progress) progress.classList.add('hidden');
@@ -3,26 +3,38 @@
 import {handlers} from '../models';
 import {strava} from '../models';
 
+export function AddAccount():Promise<handlers.AuthStatus>;
+
 export function AuthenticateStrava():Promise<void>;
 
 export function CancelVideoProcessing():Promise<void>;
 
 export function CheckAuthenticationStatus():Promise<handlers.AuthStatus>;
 
+export function DetectHighlights(arg1:number,arg2:Array<handlers.FrontendHighlightClip>):Promise<Array<handlers.FrontendHighlight>>;
+
+export function FindActivitiesForVideo(arg1:string):Promise<Array<handlers.FrontendActivityMatch>>;
+
 export function GetActivities():Promise<Array<handlers.FrontendActivity>>;
 
 export function GetActivitiesPage(arg1:number):Promise<handlers.PaginatedActivities>;
 
 export function GetActivityDetail(arg1:number):Promise<strava.ActivityDetail>;
 
+export function GetActivityLaps(arg1:number):Promise<Array<handlers.FrontendLap>>;
+
 export function GetAllGPSPoints(arg1:number):Promise<Array<handlers.FrontendGPSPoint>>;
 
+export function GetEncodedGPSTrajectory(arg1:number,arg2:string):Promise<string>;
+
 export function GetFrontendConfig():Promise<handlers.FrontendConfig>;
 
 export function GetFullGPSTrajectory(arg1:number):Promise<Array<handlers.FrontendGPSPoint>>;
 
 export function GetGPSPointForMapClick(arg1:number,arg2:number,arg3:number):Promise<handlers.FrontendGPSPoint>;
 
+export function GetGPSPointForSegmentClick(arg1:number,arg2:number,arg3:number,arg4:string,arg5:string):Promise<handlers.FrontendGPSPoint>;
+
 export function GetGPSPointForVideoTime(arg1:number,arg2:string):Promise<handlers.FrontendGPSPoint>;
 
 export function GetGPSPointsWithDensity(arg1:number,arg2:string):Promise<Array<handlers.FrontendGPSPoint>>;
@@ -31,10 +43,32 @@ export function GetMapProviderConfig():Promise<Record<string, any>>;
 
 export function GetSecureAPIKeys():Promise<Record<string, string>>;
 
+export function GetSegmentEfforts(arg1:number):Promise<Array<handlers.FrontendSegmentEffort>>;
+
+export function GetSimplifiedTrajectory(arg1:number,arg2:handlers.FrontendSimplifyOptions):Promise<Array<handlers.FrontendGPSPoint>>;
+
+export function GetTrackSegments(arg1:number):Promise<Array<handlers.FrontendTrackSegment>>;
+
+export function ListAccounts():Promise<Array<handlers.FrontendAccount>>;
+
+export function Logout():Promise<handlers.AuthStatus>;
+
+export function PreviewOverlayFrame(arg1:number,arg2:string,arg3:string,arg4:string,arg5:number):Promise<string>;
+
+export function ProcessVideoDraft(arg1:number,arg2:string,arg3:string,arg4:string,arg5:number,arg6:number,arg7:Array<handlers.FrontendTimeWindow>):Promise<string>;
+
+export function ProcessVideoHighlights(arg1:number,arg2:string,arg3:string,arg4:string,arg5:number):Promise<string>;
+
 export function ProcessVideoOverlay(arg1:number,arg2:string,arg3:string,arg4:string):Promise<string>;
 
+export function ProcessVideoWindows(arg1:number,arg2:string,arg3:string,arg4:string,arg5:Array<handlers.FrontendTimeWindow>):Promise<string>;
+
+export function RequestPrivateActivitiesAccess():Promise<handlers.AuthStatus>;
+
 export function SelectVideoFile():Promise<string>;
 
 export function SendDesktopNotification(arg1:string,arg2:string):Promise<void>;
 
 export function SendNotification(arg1:string,arg2:string):Promise<void>;
+
+export function SwitchAccount(arg1:number):Promise<handlers.AuthStatus>;
@@ -2,6 +2,10 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddAccount() {
+  return window['go']['main']['App']['AddAccount']();
+}
+
 export function AuthenticateStrava() {
   return window['go']['main']['App']['AuthenticateStrava']();
 }
@@ -14,6 +18,14 @@ export function CheckAuthenticationStatus() {
   return window['go']['main']['App']['CheckAuthenticationStatus']();
 }
 
+export function DetectHighlights(arg1, arg2) {
+  return window['go']['main']['App']['DetectHighlights'](arg1, arg2);
+}
+
+export function FindActivitiesForVideo(arg1) {
+  return window['go']['main']['App']['FindActivitiesForVideo'](arg1);
+}
+
 export function GetActivities() {
   return window['go']['main']['App']['GetActivities']();
 }
@@ -26,10 +38,18 @@ export function GetActivityDetail(arg1) {
   return window['go']['main']['App']['GetActivityDetail'](arg1);
 }
 
+export function GetActivityLaps(arg1) {
+  return window['go']['main']['App']['GetActivityLaps'](arg1);
+}
+
 export function GetAllGPSPoints(arg1) {
   return window['go']['main']['App']['GetAllGPSPoints'](arg1);
 }
 
+export function GetEncodedGPSTrajectory(arg1, arg2) {
+  return window['go']['main']['App']['GetEncodedGPSTrajectory'](arg1, arg2);
+}
+
 export function GetFrontendConfig() {
   return window['go']['main']['App']['GetFrontendConfig']();
 }
@@ -42,6 +62,10 @@ export function GetGPSPointForMapClick(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetGPSPointForMapClick'](arg1, arg2, arg3);
 }
 
+export function GetGPSPointForSegmentClick(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['GetGPSPointForSegmentClick'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function GetGPSPointForVideoTime(arg1, arg2) {
   return window['go']['main']['App']['GetGPSPointForVideoTime'](arg1, arg2);
 }
@@ -58,10 +82,50 @@ export function GetSecureAPIKeys() {
   return window['go']['main']['App']['GetSecureAPIKeys']();
 }
 
+export function GetSegmentEfforts(arg1) {
+  return window['go']['main']['App']['GetSegmentEfforts'](arg1);
+}
+
+export function GetSimplifiedTrajectory(arg1, arg2) {
+  return window['go']['main']['App']['GetSimplifiedTrajectory'](arg1, arg2);
+}
+
+export function GetTrackSegments(arg1) {
+  return window['go']['main']['App']['GetTrackSegments'](arg1);
+}
+
+export function ListAccounts() {
+  return window['go']['main']['App']['ListAccounts']();
+}
+
+export function Logout() {
+  return window['go']['main']['App']['Logout']();
+}
+
+export function PreviewOverlayFrame(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['PreviewOverlayFrame'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function ProcessVideoDraft(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['main']['App']['ProcessVideoDraft'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+}
+
+export function ProcessVideoHighlights(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['ProcessVideoHighlights'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function ProcessVideoOverlay(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['ProcessVideoOverlay'](arg1, arg2, arg3, arg4);
 }
 
+export function ProcessVideoWindows(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['ProcessVideoWindows'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function RequestPrivateActivitiesAccess() {
+  return window['go']['main']['App']['RequestPrivateActivitiesAccess']();
+}
+
 export function SelectVideoFile() {
   return window['go']['main']['App']['SelectVideoFile']();
 }
@@ -73,3 +137,7 @@ export function SendDesktopNotification(arg1, arg2) {
 export function SendNotification(arg1, arg2) {
   return window['go']['main']['App']['SendNotification'](arg1, arg2);
 }
+
+export function SwitchAccount(arg1) {
+  return window['go']['main']['App']['SwitchAccount'](arg1);
+}
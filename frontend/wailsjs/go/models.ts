@@ -4,6 +4,10 @@ export namespace handlers {
 	    is_authenticated: boolean;
 	    message: string;
 	    error?: string;
+	    athlete_id?: number;
+	    athlete_name?: string;
+	    scopes?: string[];
+	    can_read_private: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new AuthStatus(source);
@@ -14,6 +18,28 @@ export namespace handlers {
 	        this.is_authenticated = source["is_authenticated"];
 	        this.message = source["message"];
 	        this.error = source["error"];
+	        this.athlete_id = source["athlete_id"];
+	        this.athlete_name = source["athlete_name"];
+	        this.scopes = source["scopes"];
+	        this.can_read_private = source["can_read_private"];
+	    }
+	}
+	export class FrontendAccount {
+	    athlete_id: number;
+	    name: string;
+	    username: string;
+	    active: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FrontendAccount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.athlete_id = source["athlete_id"];
+	        this.name = source["name"];
+	        this.username = source["username"];
+	        this.active = source["active"];
 	    }
 	}
 	export class FrontendActivity {
@@ -28,6 +54,7 @@ export namespace handlers {
 	    end_latlng: number[];
 	    map: strava.Map;
 	    has_gps: boolean;
+	    thumbnail: number[][];
 	
 	    static createFrom(source: any = {}) {
 	        return new FrontendActivity(source);
@@ -46,6 +73,47 @@ export namespace handlers {
 	        this.end_latlng = source["end_latlng"];
 	        this.map = this.convertValues(source["map"], strava.Map);
 	        this.has_gps = source["has_gps"];
+	        this.thumbnail = source["thumbnail"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FrontendActivityMatch {
+	    activity: FrontendActivity;
+	    timezone_model: string;
+	    video_start: string;
+	    video_end: string;
+	    overlap_seconds: number;
+	    overlap_percent: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FrontendActivityMatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.activity = this.convertValues(source["activity"], FrontendActivity);
+	        this.timezone_model = source["timezone_model"];
+	        this.video_start = source["video_start"];
+	        this.video_end = source["video_end"];
+	        this.overlap_seconds = source["overlap_seconds"];
+	        this.overlap_percent = source["overlap_percent"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -102,6 +170,16 @@ export namespace handlers {
 	    altitude: number;
 	    bearing: number;
 	    gForce: number;
+	    longitudinalG: number;
+	    lateralG: number;
+	    distance: number;
+	    elapsedTime: number;
+	    movingTime: number;
+	    ascent: number;
+	    descent: number;
+	    grade: number;
+	    paused: boolean;
+	    heartRate: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new FrontendGPSPoint(source);
@@ -116,6 +194,211 @@ export namespace handlers {
 	        this.altitude = source["altitude"];
 	        this.bearing = source["bearing"];
 	        this.gForce = source["gForce"];
+	        this.longitudinalG = source["longitudinalG"];
+	        this.lateralG = source["lateralG"];
+	        this.distance = source["distance"];
+	        this.elapsedTime = source["elapsedTime"];
+	        this.movingTime = source["movingTime"];
+	        this.ascent = source["ascent"];
+	        this.descent = source["descent"];
+	        this.grade = source["grade"];
+	        this.paused = source["paused"];
+	        this.heartRate = source["heartRate"];
+	    }
+	}
+	export class FrontendHighlightRange {
+	    video_path: string;
+	    start: number;
+	    end: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FrontendHighlightRange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.video_path = source["video_path"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+	export class FrontendHighlight {
+	    start: string;
+	    end: string;
+	    score: number;
+	    reason: string;
+	    peak_speed: number;
+	    max_gforce: number;
+	    steepest_grade: number;
+	    heart_rate_rise: number;
+	    segment?: string;
+	    clips: FrontendHighlightRange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FrontendHighlight(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.score = source["score"];
+	        this.reason = source["reason"];
+	        this.peak_speed = source["peak_speed"];
+	        this.max_gforce = source["max_gforce"];
+	        this.steepest_grade = source["steepest_grade"];
+	        this.heart_rate_rise = source["heart_rate_rise"];
+	        this.segment = source["segment"];
+	        this.clips = this.convertValues(source["clips"], FrontendHighlightRange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FrontendHighlightClip {
+	    video_path: string;
+	    manual_start_time?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FrontendHighlightClip(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.video_path = source["video_path"];
+	        this.manual_start_time = source["manual_start_time"];
+	    }
+	}
+	
+	export class FrontendLap {
+	    number: number;
+	    name: string;
+	    start: string;
+	    elapsed_time: number;
+	    moving_time: number;
+	    distance: number;
+	    average_speed: number;
+	    lat: number;
+	    lng: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FrontendLap(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.number = source["number"];
+	        this.name = source["name"];
+	        this.start = source["start"];
+	        this.elapsed_time = source["elapsed_time"];
+	        this.moving_time = source["moving_time"];
+	        this.distance = source["distance"];
+	        this.average_speed = source["average_speed"];
+	        this.lat = source["lat"];
+	        this.lng = source["lng"];
+	    }
+	}
+	export class FrontendSegmentEffort {
+	    id: number;
+	    segment_id: number;
+	    name: string;
+	    start: string;
+	    elapsed_time: number;
+	    distance: number;
+	    average_grade: number;
+	    kom_rank: number;
+	    pr_rank: number;
+	    polyline: number[][];
+	
+	    static createFrom(source: any = {}) {
+	        return new FrontendSegmentEffort(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.segment_id = source["segment_id"];
+	        this.name = source["name"];
+	        this.start = source["start"];
+	        this.elapsed_time = source["elapsed_time"];
+	        this.distance = source["distance"];
+	        this.average_grade = source["average_grade"];
+	        this.kom_rank = source["kom_rank"];
+	        this.pr_rank = source["pr_rank"];
+	        this.polyline = source["polyline"];
+	    }
+	}
+	export class FrontendSimplifyOptions {
+	    zoom: number;
+	    tolerance_meters: number;
+	    target_points: number;
+	    algorithm: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FrontendSimplifyOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.zoom = source["zoom"];
+	        this.tolerance_meters = source["tolerance_meters"];
+	        this.target_points = source["target_points"];
+	        this.algorithm = source["algorithm"];
+	    }
+	}
+	export class FrontendTimeWindow {
+	    start: number;
+	    end: number;
+	    gpsStart?: string;
+	    gpsEnd?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FrontendTimeWindow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.gpsStart = source["gpsStart"];
+	        this.gpsEnd = source["gpsEnd"];
+	    }
+	}
+	export class FrontendTrackSegment {
+	    type: string;
+	    start: string;
+	    end: string;
+	    duration_seconds: number;
+	    lat: number;
+	    lng: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FrontendTrackSegment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.duration_seconds = source["duration_seconds"];
+	        this.lat = source["lat"];
+	        this.lng = source["lng"];
 	    }
 	}
 	export class PaginatedActivities {
@@ -161,6 +444,209 @@ export namespace handlers {
 
 export namespace strava {
 	
+	export class SummarySegment {
+	    id: number;
+	    name: string;
+	    distance: number;
+	    average_grade: number;
+	    climb_category: number;
+	    start_latlng: number[];
+	    end_latlng: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SummarySegment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.distance = source["distance"];
+	        this.average_grade = source["average_grade"];
+	        this.climb_category = source["climb_category"];
+	        this.start_latlng = source["start_latlng"];
+	        this.end_latlng = source["end_latlng"];
+	    }
+	}
+	export class SegmentEffort {
+	    id: number;
+	    name: string;
+	    // Go type: time
+	    start_date: any;
+	    elapsed_time: number;
+	    moving_time: number;
+	    distance: number;
+	    start_index: number;
+	    end_index: number;
+	    kom_rank?: number;
+	    pr_rank?: number;
+	    segment: SummarySegment;
+	
+	    static createFrom(source: any = {}) {
+	        return new SegmentEffort(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.start_date = this.convertValues(source["start_date"], null);
+	        this.elapsed_time = source["elapsed_time"];
+	        this.moving_time = source["moving_time"];
+	        this.distance = source["distance"];
+	        this.start_index = source["start_index"];
+	        this.end_index = source["end_index"];
+	        this.kom_rank = source["kom_rank"];
+	        this.pr_rank = source["pr_rank"];
+	        this.segment = this.convertValues(source["segment"], SummarySegment);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BestEffort {
+	    id: number;
+	    name: string;
+	    // Go type: time
+	    start_date: any;
+	    elapsed_time: number;
+	    moving_time: number;
+	    distance: number;
+	    start_index: number;
+	    end_index: number;
+	    pr_rank?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BestEffort(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.start_date = this.convertValues(source["start_date"], null);
+	        this.elapsed_time = source["elapsed_time"];
+	        this.moving_time = source["moving_time"];
+	        this.distance = source["distance"];
+	        this.start_index = source["start_index"];
+	        this.end_index = source["end_index"];
+	        this.pr_rank = source["pr_rank"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Split {
+	    split: number;
+	    distance: number;
+	    elapsed_time: number;
+	    moving_time: number;
+	    elevation_difference: number;
+	    average_speed: number;
+	    average_heartrate: number;
+	    pace_zone: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Split(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.split = source["split"];
+	        this.distance = source["distance"];
+	        this.elapsed_time = source["elapsed_time"];
+	        this.moving_time = source["moving_time"];
+	        this.elevation_difference = source["elevation_difference"];
+	        this.average_speed = source["average_speed"];
+	        this.average_heartrate = source["average_heartrate"];
+	        this.pace_zone = source["pace_zone"];
+	    }
+	}
+	export class Lap {
+	    id: number;
+	    name: string;
+	    lap_index: number;
+	    // Go type: time
+	    start_date: any;
+	    elapsed_time: number;
+	    moving_time: number;
+	    distance: number;
+	    start_index: number;
+	    end_index: number;
+	    total_elevation_gain: number;
+	    average_speed: number;
+	    max_speed: number;
+	    average_heartrate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Lap(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.lap_index = source["lap_index"];
+	        this.start_date = this.convertValues(source["start_date"], null);
+	        this.elapsed_time = source["elapsed_time"];
+	        this.moving_time = source["moving_time"];
+	        this.distance = source["distance"];
+	        this.start_index = source["start_index"];
+	        this.end_index = source["end_index"];
+	        this.total_elevation_gain = source["total_elevation_gain"];
+	        this.average_speed = source["average_speed"];
+	        this.max_speed = source["max_speed"];
+	        this.average_heartrate = source["average_heartrate"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Map {
 	    id: string;
 	    polyline: string;
@@ -186,6 +672,7 @@ export namespace strava {
 	    timezone: string;
 	    distance: number;
 	    moving_time: number;
+	    elapsed_time: number;
 	    max_speed: number;
 	    has_heartrate: boolean;
 	    start_latlng: number[];
@@ -193,6 +680,11 @@ export namespace strava {
 	    map: Map;
 	    calories: number;
 	    total_elevation_gain: number;
+	    laps: Lap[];
+	    splits_metric: Split[];
+	    splits_standard: Split[];
+	    best_efforts: BestEffort[];
+	    segment_efforts: SegmentEffort[];
 	
 	    static createFrom(source: any = {}) {
 	        return new ActivityDetail(source);
@@ -207,6 +699,7 @@ export namespace strava {
 	        this.timezone = source["timezone"];
 	        this.distance = source["distance"];
 	        this.moving_time = source["moving_time"];
+	        this.elapsed_time = source["elapsed_time"];
 	        this.max_speed = source["max_speed"];
 	        this.has_heartrate = source["has_heartrate"];
 	        this.start_latlng = source["start_latlng"];
@@ -214,6 +707,11 @@ export namespace strava {
 	        this.map = this.convertValues(source["map"], Map);
 	        this.calories = source["calories"];
 	        this.total_elevation_gain = source["total_elevation_gain"];
+	        this.laps = this.convertValues(source["laps"], Lap);
+	        this.splits_metric = this.convertValues(source["splits_metric"], Split);
+	        this.splits_standard = this.convertValues(source["splits_standard"], Split);
+	        this.best_efforts = this.convertValues(source["best_efforts"], BestEffort);
+	        this.segment_efforts = this.convertValues(source["segment_efforts"], SegmentEffort);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -234,6 +732,11 @@ export namespace strava {
 		    return a;
 		}
 	}
+	
+	
+	
+	
+	
 
 }
 
@@ -0,0 +1,263 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// legacyAccountKey é a chave do token salvo antes do suporte a múltiplas contas
+const legacyAccountKey = "default"
+
+// Account representa uma conta Strava conectada na estação de trabalho
+type Account struct {
+	AthleteID int64     `json:"athlete_id"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"firstname"`
+	LastName  string    `json:"lastname"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// HasScope informa se o Strava concedeu o escopo à conta
+func (a Account) HasScope(scope string) bool {
+	for _, s := range a.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// DisplayName retorna o nome do atleta para exibição
+func (a Account) DisplayName() string {
+	name := strings.TrimSpace(a.FirstName + " " + a.LastName)
+	if name != "" {
+		return name
+	}
+	if a.Username != "" {
+		return a.Username
+	}
+	if a.AthleteID == 0 {
+		return "Conta Strava"
+	}
+	return fmt.Sprintf("Atleta %d", a.AthleteID)
+}
+
+// Key identifica a conta no armazenamento de tokens e nos diretórios por conta
+func (a Account) Key() string {
+	if a.AthleteID == 0 {
+		return legacyAccountKey
+	}
+	return strconv.FormatInt(a.AthleteID, 10)
+}
+
+type accountsFile struct {
+	ActiveAthleteID int64     `json:"active_athlete_id"`
+	Accounts        []Account `json:"accounts"`
+}
+
+// AccountManager mantém a lista de contas conectadas e qual delas está ativa
+type AccountManager struct {
+	mutex    sync.Mutex
+	path     string
+	newStore func(key string) TokenStore
+	data     accountsFile
+}
+
+// NewAccountManager carrega as contas de dir/accounts.json. newStore cria o armazenamento de
+// tokens de cada conta a partir de Account.Key.
+func NewAccountManager(dir string, newStore func(key string) TokenStore) *AccountManager {
+	m := &AccountManager{
+		path:     filepath.Join(dir, "accounts.json"),
+		newStore: newStore,
+	}
+
+	data, err := os.ReadFile(m.path)
+	if err == nil {
+		if err := json.Unmarshal(data, &m.data); err != nil {
+			log.Printf("⚠️ accounts.json inválido, ignorando: %v", err)
+		}
+		return m
+	}
+
+	// Instalações antigas têm apenas um token sem atleta associado; ele vira uma conta
+	// provisória que é identificada no primeiro acesso à API
+	if _, err := newStore(legacyAccountKey).Load(); err == nil {
+		m.data.Accounts = []Account{{Scopes: DefaultScopes, AddedAt: time.Now()}}
+		m.data.ActiveAthleteID = 0
+		if err := m.save(); err != nil {
+			log.Printf("⚠️ Não foi possível salvar accounts.json: %v", err)
+		}
+	}
+
+	return m
+}
+
+// List retorna todas as contas conectadas
+func (m *AccountManager) List() []Account {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	accounts := make([]Account, len(m.data.Accounts))
+	copy(accounts, m.data.Accounts)
+	return accounts
+}
+
+// Active retorna a conta ativa
+func (m *AccountManager) Active() (Account, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	idx := m.indexOf(m.data.ActiveAthleteID)
+	if idx == -1 {
+		return Account{}, false
+	}
+	return m.data.Accounts[idx], true
+}
+
+// StoreFor retorna o armazenamento de tokens da conta
+func (m *AccountManager) StoreFor(account Account) TokenStore {
+	return m.newStore(account.Key())
+}
+
+// SetActive troca a conta ativa
+func (m *AccountManager) SetActive(athleteID int64) (Account, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	idx := m.indexOf(athleteID)
+	if idx == -1 {
+		return Account{}, fmt.Errorf("conta %d não encontrada", athleteID)
+	}
+
+	m.data.ActiveAthleteID = athleteID
+	return m.data.Accounts[idx], m.save()
+}
+
+// Upsert registra (ou atualiza) a conta, salva seu token e a torna ativa
+func (m *AccountManager) Upsert(account Account, token *oauth2.Token) error {
+	if err := m.StoreFor(account).Save(token); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if idx := m.indexOf(account.AthleteID); idx != -1 {
+		account.AddedAt = m.data.Accounts[idx].AddedAt
+		m.data.Accounts[idx] = account
+	} else {
+		if account.AddedAt.IsZero() {
+			account.AddedAt = time.Now()
+		}
+		m.data.Accounts = append(m.data.Accounts, account)
+	}
+
+	m.data.ActiveAthleteID = account.AthleteID
+	return m.save()
+}
+
+// Identify associa a conta provisória (token legado) ao atleta informado, movendo o token
+func (m *AccountManager) Identify(account Account) error {
+	legacy := Account{}
+	token, err := m.StoreFor(legacy).Load()
+	if err != nil {
+		return err
+	}
+
+	if len(account.Scopes) == 0 {
+		m.mutex.Lock()
+		if idx := m.indexOf(legacy.AthleteID); idx != -1 {
+			account.Scopes = m.data.Accounts[idx].Scopes
+		}
+		m.mutex.Unlock()
+	}
+
+	if err := m.Upsert(account, token); err != nil {
+		return err
+	}
+
+	if err := m.Remove(legacy.AthleteID); err != nil {
+		return err
+	}
+
+	log.Printf("👤 Token legado associado ao atleta %s (%d)", account.DisplayName(), account.AthleteID)
+	return nil
+}
+
+// Remove apaga o token e o registro da conta. Se era a conta ativa, a próxima conta da lista
+// passa a ser a ativa.
+func (m *AccountManager) Remove(athleteID int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	idx := m.indexOf(athleteID)
+	if idx == -1 {
+		return fmt.Errorf("conta %d não encontrada", athleteID)
+	}
+
+	if err := m.newStore(m.data.Accounts[idx].Key()).Delete(); err != nil {
+		return err
+	}
+
+	m.data.Accounts = append(m.data.Accounts[:idx], m.data.Accounts[idx+1:]...)
+
+	if m.data.ActiveAthleteID == athleteID {
+		m.data.ActiveAthleteID = 0
+		if len(m.data.Accounts) > 0 {
+			m.data.ActiveAthleteID = m.data.Accounts[0].AthleteID
+		}
+	}
+
+	return m.save()
+}
+
+func (m *AccountManager) indexOf(athleteID int64) int {
+	for i, account := range m.data.Accounts {
+		if account.AthleteID == athleteID {
+			return i
+		}
+	}
+	return -1
+}
+
+// save persiste a lista de contas; deve ser chamado com o mutex travado
+func (m *AccountManager) save() error {
+	data, err := json.MarshalIndent(m.data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(m.path, data, 0600)
+}
+
+// accountFromToken extrai o atleta que o Strava envia junto com a resposta do token
+func accountFromToken(token *oauth2.Token) Account {
+	account := Account{AddedAt: time.Now()}
+
+	athlete, ok := token.Extra("athlete").(map[string]interface{})
+	if !ok {
+		return account
+	}
+
+	if id, ok := athlete["id"].(float64); ok {
+		account.AthleteID = int64(id)
+	}
+	account.Username, _ = athlete["username"].(string)
+	account.FirstName, _ = athlete["firstname"].(string)
+	account.LastName, _ = athlete["lastname"].(string)
+
+	return account
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrAccessDenied indica que o usuário recusou a autorização na página do Strava
+var ErrAccessDenied = errors.New("autorização negada pelo usuário no Strava")
+
+// CallbackOptions controla o servidor local que recebe o redirecionamento do OAuth
+type CallbackOptions struct {
+	// FirstPort e LastPort delimitam as portas tentadas; 0 deixa o sistema escolher uma porta livre
+	FirstPort int
+	LastPort  int
+	// Timeout limita o fluxo inteiro, da abertura do navegador até a troca do código
+	Timeout time.Duration
+}
+
+// DefaultCallbackOptions usa uma porta efêmera e espera até 5 minutos pelo usuário
+var DefaultCallbackOptions = CallbackOptions{Timeout: 5 * time.Minute}
+
+// ParseCallbackOptions interpreta a configuração de portas ("", "8080" ou "8080-8090") e o
+// timeout no formato de time.ParseDuration
+func ParseCallbackOptions(ports, timeout string) (CallbackOptions, error) {
+	opts := DefaultCallbackOptions
+
+	if ports = strings.TrimSpace(ports); ports != "" {
+		first, last, found := strings.Cut(ports, "-")
+		if !found {
+			last = first
+		}
+
+		var err error
+		if opts.FirstPort, err = strconv.Atoi(strings.TrimSpace(first)); err != nil {
+			return DefaultCallbackOptions, fmt.Errorf("porta de callback inválida '%s'", ports)
+		}
+		if opts.LastPort, err = strconv.Atoi(strings.TrimSpace(last)); err != nil {
+			return DefaultCallbackOptions, fmt.Errorf("porta de callback inválida '%s'", ports)
+		}
+		if opts.FirstPort < 0 || opts.LastPort > 65535 || opts.FirstPort > opts.LastPort {
+			return DefaultCallbackOptions, fmt.Errorf("faixa de portas de callback inválida '%s'", ports)
+		}
+	}
+
+	if timeout = strings.TrimSpace(timeout); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return DefaultCallbackOptions, fmt.Errorf("timeout de autenticação inválido '%s'", timeout)
+		}
+		opts.Timeout = d
+	}
+
+	return opts, nil
+}
+
+// listenLoopback abre o servidor de callback apenas na interface de loopback, tentando cada
+// porta da faixa configurada
+func listenLoopback(opts CallbackOptions) (net.Listener, error) {
+	if opts.FirstPort == 0 {
+		return net.Listen("tcp", "127.0.0.1:0")
+	}
+
+	var lastErr error
+	for port := opts.FirstPort; port <= opts.LastPort; port++ {
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err == nil {
+			return listener, nil
+		}
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("nenhuma porta livre entre %d e %d: %w", opts.FirstPort, opts.LastPort, lastErr)
+}
+
+var callbackPage = template.Must(template.New("callback").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<title>Strava Add Overlay</title>
+<style>
+  body { margin: 0; min-height: 100vh; display: flex; align-items: center; justify-content: center;
+         background: #0d1117; color: #c9d1d9; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; }
+  .card { background: #161b22; border: 1px solid #30363d; border-radius: 8px; padding: 32px 40px;
+          max-width: 420px; text-align: center; }
+  .icon { font-size: 48px; margin-bottom: 12px; }
+  h1 { font-size: 1.3em; margin: 0 0 8px; color: {{if .Success}}#fc4c02{{else}}#f85149{{end}}; }
+  p { margin: 0; line-height: 1.5; color: #8b949e; }
+</style>
+</head>
+<body>
+  <div class="card">
+    <div class="icon">{{if .Success}}✅{{else}}⚠️{{end}}</div>
+    <h1>{{.Title}}</h1>
+    <p>{{.Message}}</p>
+  </div>
+</body>
+</html>`))
+
+// renderCallbackPage responde ao navegador com a página de sucesso ou falha
+func renderCallbackPage(w http.ResponseWriter, status int, success bool, title, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	callbackPage.Execute(w, struct {
+		Success        bool
+		Title, Message string
+	}{success, title, message})
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/oauth2"
+)
+
+// EncryptedFileTokenStore guarda o token em um arquivo cifrado com AES-GCM. A chave é derivada
+// com scrypt da passphrase do usuário ou, na ausência dela, de identificadores da máquina.
+type EncryptedFileTokenStore struct {
+	path       string
+	passphrase string
+}
+
+type encryptedTokenFile struct {
+	Version    int    `json:"version"`
+	KeySource  string `json:"key_source"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// NewEncryptedFileTokenStore cria um armazenamento em dir/tokens/<account>.enc
+func NewEncryptedFileTokenStore(dir, account, passphrase string) *EncryptedFileTokenStore {
+	return &EncryptedFileTokenStore{
+		path:       filepath.Join(dir, "tokens", account+".enc"),
+		passphrase: passphrase,
+	}
+}
+
+func (f *EncryptedFileTokenStore) Load() (*oauth2.Token, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	var file encryptedTokenFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("arquivo de token corrompido: %w", err)
+	}
+
+	if file.KeySource != f.keySource() {
+		return nil, fmt.Errorf("token cifrado com chave '%s', mas a configuração atual usa '%s'", file.KeySource, f.keySource())
+	}
+
+	gcm, err := f.cipher(file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível decifrar o token (passphrase incorreta?): %w", err)
+	}
+
+	var tokenData TokenData
+	if err := json.Unmarshal(plaintext, &tokenData); err != nil {
+		return nil, err
+	}
+	if tokenData.Token == nil {
+		return nil, ErrTokenNotFound
+	}
+
+	return tokenData.Token, nil
+}
+
+func (f *EncryptedFileTokenStore) Save(token *oauth2.Token) error {
+	plaintext, err := json.Marshal(TokenData{Token: token, ExpiresAt: token.Expiry})
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	gcm, err := f.cipher(salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(encryptedTokenFile{
+		Version:    1,
+		KeySource:  f.keySource(),
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+
+	// Escreve em arquivo temporário e renomeia para não deixar um token truncado
+	tmpPath := f.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, f.path)
+}
+
+func (f *EncryptedFileTokenStore) Delete() error {
+	if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (f *EncryptedFileTokenStore) Name() string {
+	return "arquivo criptografado"
+}
+
+func (f *EncryptedFileTokenStore) keySource() string {
+	if f.passphrase != "" {
+		return "passphrase"
+	}
+	return "machine"
+}
+
+// cipher deriva a chave AES-256 a partir do segredo e do salt
+func (f *EncryptedFileTokenStore) cipher(salt []byte) (cipher.AEAD, error) {
+	secret := f.passphrase
+	if secret == "" {
+		secret = machineSecret()
+	}
+
+	key, err := scrypt.Key([]byte(secret), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao derivar chave: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// machineSecret combina identificadores estáveis da máquina e do usuário. Não substitui uma
+// passphrase, mas impede que o arquivo seja lido se copiado para outra máquina.
+func machineSecret() string {
+	parts := []string{"strava-overlay"}
+
+	for _, idPath := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(idPath); err == nil {
+			parts = append(parts, strings.TrimSpace(string(data)))
+			break
+		}
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		parts = append(parts, hostname)
+	}
+
+	if current, err := user.Current(); err == nil {
+		parts = append(parts, current.Uid, current.Username)
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return fmt.Sprintf("%x", sum)
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+)
+
+const keyringService = "strava-overlay"
+
+// KeyringTokenStore guarda o token no keyring do sistema (Secret Service, Keychain ou
+// Windows Credential Manager)
+type KeyringTokenStore struct {
+	account string
+}
+
+// NewKeyringTokenStore cria um armazenamento no keyring para a conta informada
+func NewKeyringTokenStore(account string) *KeyringTokenStore {
+	return &KeyringTokenStore{account: account}
+}
+
+// Available verifica se o keyring do sistema responde
+func (k *KeyringTokenStore) Available() bool {
+	_, err := keyring.Get(keyringService, "__probe__")
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+func (k *KeyringTokenStore) Load() (*oauth2.Token, error) {
+	secret, err := keyring.Get(keyringService, k.account)
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("erro ao ler token do keyring: %w", err)
+	}
+
+	var tokenData TokenData
+	if err := json.Unmarshal([]byte(secret), &tokenData); err != nil {
+		return nil, fmt.Errorf("token inválido no keyring: %w", err)
+	}
+	if tokenData.Token == nil {
+		return nil, ErrTokenNotFound
+	}
+
+	return tokenData.Token, nil
+}
+
+func (k *KeyringTokenStore) Save(token *oauth2.Token) error {
+	data, err := json.Marshal(TokenData{Token: token, ExpiresAt: token.Expiry})
+	if err != nil {
+		return err
+	}
+
+	if err := keyring.Set(keyringService, k.account, string(data)); err != nil {
+		return fmt.Errorf("erro ao salvar token no keyring: %w", err)
+	}
+	return nil
+}
+
+func (k *KeyringTokenStore) Delete() error {
+	err := keyring.Delete(keyringService, k.account)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("erro ao remover token do keyring: %w", err)
+	}
+	return nil
+}
+
+func (k *KeyringTokenStore) Name() string {
+	return "keyring do sistema"
+}
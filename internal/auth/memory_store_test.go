@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// MemoryTokenStore mantém o token apenas em memória nos testes
+type MemoryTokenStore struct {
+	mutex sync.Mutex
+	token *oauth2.Token
+}
+
+// NewMemoryTokenStore cria um armazenamento em memória, opcionalmente com um token inicial
+func NewMemoryTokenStore(token *oauth2.Token) *MemoryTokenStore {
+	return &MemoryTokenStore{token: token}
+}
+
+func (m *MemoryTokenStore) Load() (*oauth2.Token, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.token == nil {
+		return nil, ErrTokenNotFound
+	}
+	token := *m.token
+	return &token, nil
+}
+
+func (m *MemoryTokenStore) Save(token *oauth2.Token) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	saved := *token
+	m.token = &saved
+	return nil
+}
+
+func (m *MemoryTokenStore) Delete() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.token = nil
+	return nil
+}
+
+func (m *MemoryTokenStore) Name() string {
+	return "memória"
+}
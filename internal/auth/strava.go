@@ -3,22 +3,37 @@ package auth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
-	"os"
-	"path/filepath"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/browser"
 	"golang.org/x/oauth2"
 )
 
+// Escopos OAuth do Strava
+const (
+	ScopeRead            = "read"
+	ScopeActivityRead    = "activity:read"
+	ScopeActivityReadAll = "activity:read_all"
+)
+
+// DefaultScopes são os escopos pedidos no login normal
+var DefaultScopes = []string{ScopeRead, ScopeActivityRead}
+
 type StravaAuth struct {
-	config     *oauth2.Config
-	tokenFile  string
-	httpServer *http.Server
+	config         *oauth2.Config
+	deauthorizeURL string
+	accounts       *AccountManager
+	callback       CallbackOptions
+	openBrowser    func(url string) error
 }
 
 type TokenData struct {
@@ -26,10 +41,7 @@ type TokenData struct {
 	ExpiresAt time.Time     `json:"expires_at"`
 }
 
-func NewStravaAuth(clientID, clientSecret string) *StravaAuth {
-	homeDir, _ := os.UserHomeDir()
-	tokenFile := filepath.Join(homeDir, ".strava-overlay", "token.json")
-
+func NewStravaAuth(clientID, clientSecret string, accounts *AccountManager) *StravaAuth {
 	config := &oauth2.Config{
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
@@ -37,114 +49,260 @@ func NewStravaAuth(clientID, clientSecret string) *StravaAuth {
 			AuthURL:  "https://www.strava.com/oauth/authorize",
 			TokenURL: "https://www.strava.com/oauth/token",
 		},
-		RedirectURL: "http://localhost:8080/callback",
-		Scopes:      []string{"read,activity:read"},
 	}
 
 	return &StravaAuth{
-		config:    config,
-		tokenFile: tokenFile,
+		config:         config,
+		deauthorizeURL: "https://www.strava.com/oauth/deauthorize",
+		accounts:       accounts,
+		callback:       DefaultCallbackOptions,
+		openBrowser:    browser.OpenURL,
 	}
 }
 
+// SetCallbackOptions define as portas e o timeout do servidor local de callback
+func (sa *StravaAuth) SetCallbackOptions(opts CallbackOptions) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultCallbackOptions.Timeout
+	}
+	sa.callback = opts
+}
+
+// Accounts retorna o gerenciador de contas conectadas
+func (sa *StravaAuth) Accounts() *AccountManager {
+	return sa.accounts
+}
+
+// GetValidToken retorna um token válido da conta ativa, renovando-o ou pedindo autorização
+// quando necessário
 func (sa *StravaAuth) GetValidToken(ctx context.Context) (*oauth2.Token, error) {
-	if token, err := sa.loadToken(); err == nil {
-		if token.Valid() {
-			return token, nil
-		}
-		if refreshed, err := sa.config.TokenSource(ctx, token).Token(); err == nil {
-			sa.saveToken(refreshed)
-			return refreshed, nil
+	if token, err := sa.ActiveToken(ctx); err == nil {
+		return token, nil
+	}
+	return sa.authorizeUser(ctx, DefaultScopes, false)
+}
+
+// ActiveToken retorna o token salvo da conta ativa, renovando-o se expirado, sem nunca abrir
+// o navegador para uma nova autorização
+func (sa *StravaAuth) ActiveToken(ctx context.Context) (*oauth2.Token, error) {
+	account, ok := sa.accounts.Active()
+	if !ok {
+		return nil, fmt.Errorf("nenhuma conta conectada")
+	}
+
+	store := sa.accounts.StoreFor(account)
+	token, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if token.Valid() {
+		return token, nil
+	}
+
+	refreshed, err := sa.config.TokenSource(ctx, token).Token()
+	if err != nil {
+		return nil, fmt.Errorf("token refresh failed: %w", err)
+	}
+	store.Save(refreshed)
+	return refreshed, nil
+}
+
+// AddAccount autoriza uma nova conta Strava e a torna ativa
+func (sa *StravaAuth) AddAccount(ctx context.Context) (*oauth2.Token, error) {
+	return sa.authorizeUser(ctx, DefaultScopes, true)
+}
+
+// UpgradeScope pede novamente autorização incluindo activity:read_all, necessário para
+// listar atividades privadas
+func (sa *StravaAuth) UpgradeScope(ctx context.Context) (*oauth2.Token, error) {
+	return sa.authorizeUser(ctx, []string{ScopeRead, ScopeActivityRead, ScopeActivityReadAll}, true)
+}
+
+// Logout revoga o acesso da conta ativa no Strava e remove o token salvo. A revogação é
+// feita em melhor esforço: o token local é removido mesmo se o Strava estiver inacessível.
+func (sa *StravaAuth) Logout(ctx context.Context) (Account, error) {
+	account, ok := sa.accounts.Active()
+	if !ok {
+		return Account{}, fmt.Errorf("nenhuma conta conectada")
+	}
+
+	if token, err := sa.accounts.StoreFor(account).Load(); err == nil {
+		if err := sa.revokeToken(ctx, token); err != nil {
+			log.Printf("⚠️ Falha ao revogar o token no Strava: %v", err)
 		}
 	}
-	return sa.authorizeUser(ctx)
+
+	if err := sa.accounts.Remove(account.AthleteID); err != nil {
+		return account, fmt.Errorf("failed to delete stored token: %w", err)
+	}
+
+	return account, nil
 }
 
-func (sa *StravaAuth) authorizeUser(ctx context.Context) (*oauth2.Token, error) {
-	state := sa.generateState()
+// revokeToken chama o endpoint de deauthorize do Strava, renovando o token antes se expirado
+func (sa *StravaAuth) revokeToken(ctx context.Context, token *oauth2.Token) error {
+	valid, err := sa.config.TokenSource(ctx, token).Token()
+	if err != nil {
+		return fmt.Errorf("token refresh failed: %w", err)
+	}
 
-	mux := http.NewServeMux()
-	tokenChan := make(chan *oauth2.Token, 1)
-	errChan := make(chan error, 1)
+	form := url.Values{"access_token": {valid.AccessToken}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sa.deauthorizeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("deauthorize retornou HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (sa *StravaAuth) authorizeUser(ctx context.Context, scopes []string, forcePrompt bool) (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, sa.callback.Timeout)
+	defer cancel()
+
+	state, err := generateState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate OAuth state: %w", err)
+	}
+
+	listener, err := listenLoopback(sa.callback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start OAuth callback server: %w", err)
+	}
 
+	// Cada fluxo usa a porta que conseguiu abrir como redirect_uri
+	config := *sa.config
+	config.RedirectURL = fmt.Sprintf("http://%s/callback", listener.Addr().String())
+
+	results := make(chan callbackResult, 1)
+	var stateMutex sync.Mutex
+	stateUsed := false
+
+	mux := http.NewServeMux()
 	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Query().Get("state") != state {
-			http.Error(w, "Invalid state", http.StatusBadRequest)
-			errChan <- fmt.Errorf("invalid state")
+		query := r.URL.Query()
+
+		// O state vale para uma única resposta; requisições forjadas são recusadas sem
+		// interromper o login legítimo
+		stateMutex.Lock()
+		validState := !stateUsed && subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state)) == 1
+		if validState {
+			stateUsed = true
+		}
+		stateMutex.Unlock()
+
+		if !validState {
+			renderCallbackPage(w, http.StatusBadRequest, false, "Requisição inválida",
+				"Esta resposta de autorização não corresponde ao login em andamento.")
 			return
 		}
 
-		code := r.URL.Query().Get("code")
-		token, err := sa.config.Exchange(ctx, code)
+		if errCode := query.Get("error"); errCode != "" {
+			err := fmt.Errorf("strava returned error: %s", errCode)
+			if errCode == "access_denied" {
+				err = ErrAccessDenied
+			}
+			renderCallbackPage(w, http.StatusOK, false, "Autorização não concedida",
+				"O acesso ao Strava foi negado. Volte ao aplicativo para tentar novamente.")
+			results <- callbackResult{err: err}
+			return
+		}
+
+		token, err := config.Exchange(ctx, query.Get("code"))
 		if err != nil {
-			http.Error(w, "Token exchange failed", http.StatusInternalServerError)
-			errChan <- err
+			renderCallbackPage(w, http.StatusBadGateway, false, "Falha na autenticação",
+				"Não foi possível obter o token do Strava. Volte ao aplicativo para tentar novamente.")
+			results <- callbackResult{err: fmt.Errorf("token exchange failed: %w", err)}
 			return
 		}
 
-		fmt.Fprintf(w, "Authorization successful! You can close this window.")
-		tokenChan <- token
+		renderCallbackPage(w, http.StatusOK, true, "Conectado ao Strava",
+			"Autorização concluída. Você já pode fechar esta janela e voltar ao aplicativo.")
+		results <- callbackResult{token: token, scope: query.Get("scope")}
 	})
 
-	sa.httpServer = &http.Server{Addr: ":8080", Handler: mux}
-	go sa.httpServer.ListenAndServe()
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+	defer func() {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancelShutdown()
+		server.Shutdown(shutdownCtx)
+	}()
 
-	authURL := sa.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	// O Strava espera os escopos separados por vírgula, não por espaço como o oauth2 gera
+	authOptions := []oauth2.AuthCodeOption{
+		oauth2.AccessTypeOffline,
+		oauth2.SetAuthURLParam("scope", strings.Join(scopes, ",")),
+	}
+	if forcePrompt {
+		authOptions = append(authOptions, oauth2.SetAuthURLParam("approval_prompt", "force"))
+	}
+	authURL := config.AuthCodeURL(state, authOptions...)
 	fmt.Printf("Visit this URL to authorize: %s\n", authURL)
 
 	// Abre a URL de autorização no navegador padrão do usuário
-	err := browser.OpenURL(authURL)
-	if err != nil {
+	if err := sa.openBrowser(authURL); err != nil {
 		// Se não conseguir abrir o navegador, retorna o erro para que o usuário possa usar a URL do console
 		return nil, fmt.Errorf("failed to open browser for authentication: %w. Please visit the URL printed in the console", err)
 	}
 
 	select {
-	case token := <-tokenChan:
-		sa.httpServer.Shutdown(ctx)
-		sa.saveToken(token)
-		return token, nil
-	case err := <-errChan:
-		sa.httpServer.Shutdown(ctx)
-		return nil, err
+	case result := <-results:
+		if result.err != nil {
+			return nil, result.err
+		}
+		account := accountFromToken(result.token)
+		account.Scopes = parseScopes(result.scope)
+		if err := sa.accounts.Upsert(account, result.token); err != nil {
+			return nil, fmt.Errorf("failed to store token: %w", err)
+		}
+		return result.token, nil
+	case err := <-serveErr:
+		return nil, fmt.Errorf("OAuth callback server failed: %w", err)
 	case <-ctx.Done():
-		sa.httpServer.Shutdown(ctx)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("tempo esgotado aguardando a autorização no Strava (%s)", sa.callback.Timeout)
+		}
 		return nil, ctx.Err()
 	}
 }
 
-func (sa *StravaAuth) generateState() string {
-	b := make([]byte, 32)
-	rand.Read(b)
-	return base64.URLEncoding.EncodeToString(b)
+// callbackResult é o desfecho do redirecionamento recebido pelo servidor local
+type callbackResult struct {
+	token *oauth2.Token
+	scope string
+	err   error
 }
 
-func (sa *StravaAuth) loadToken() (*oauth2.Token, error) {
-	data, err := os.ReadFile(sa.tokenFile)
-	if err != nil {
-		return nil, err
-	}
-
-	var tokenData TokenData
-	if err := json.Unmarshal(data, &tokenData); err != nil {
-		return nil, err
+func generateState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
-
-	return tokenData.Token, nil
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
-func (sa *StravaAuth) saveToken(token *oauth2.Token) error {
-	os.MkdirAll(filepath.Dir(sa.tokenFile), 0755)
-
-	tokenData := TokenData{
-		Token:     token,
-		ExpiresAt: token.Expiry,
-	}
-
-	data, err := json.MarshalIndent(tokenData, "", "  ")
-	if err != nil {
-		return err
+// parseScopes converte o parâmetro scope do callback ("read,activity:read") em lista
+func parseScopes(scope string) []string {
+	var scopes []string
+	for _, s := range strings.Split(scope, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
 	}
-
-	return os.WriteFile(sa.tokenFile, data, 0600)
+	return scopes
 }
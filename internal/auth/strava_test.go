@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProvider simula o servidor OAuth do Strava: o endpoint de token troca o código por um
+// token com o atleta, como a API real
+type fakeProvider struct {
+	server *httptest.Server
+
+	mutex sync.Mutex
+	codes []string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	p := &fakeProvider{}
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/token" {
+			http.NotFound(w, r)
+			return
+		}
+		r.ParseForm()
+		p.mutex.Lock()
+		p.codes = append(p.codes, r.Form.Get("code"))
+		p.mutex.Unlock()
+
+		if r.Form.Get("code") != "valid-code" {
+			http.Error(w, `{"message":"Bad Request"}`, http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token_type":    "Bearer",
+			"access_token":  "access-token",
+			"refresh_token": "refresh-token",
+			"expires_in":    21600,
+			"athlete":       map[string]interface{}{"id": 4242, "username": "ciclista", "firstname": "Ana", "lastname": "Silva"},
+		})
+	}))
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *fakeProvider) exchangedCodes() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]string(nil), p.codes...)
+}
+
+// newTestAuth cria um StravaAuth apontando para o provedor falso, com tokens em memória.
+// browser recebe a URL de autorização no lugar do navegador do usuário.
+func newTestAuth(t *testing.T, provider *fakeProvider, opts CallbackOptions, browser func(authURL string) error) *StravaAuth {
+	stores := map[string]*MemoryTokenStore{}
+	var mutex sync.Mutex
+	accounts := NewAccountManager(t.TempDir(), func(key string) TokenStore {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if stores[key] == nil {
+			stores[key] = NewMemoryTokenStore(nil)
+		}
+		return stores[key]
+	})
+
+	sa := NewStravaAuth("client-id", "client-secret", accounts)
+	sa.config.Endpoint.AuthURL = provider.server.URL + "/oauth/authorize"
+	sa.config.Endpoint.TokenURL = provider.server.URL + "/oauth/token"
+	sa.SetCallbackOptions(opts)
+	sa.openBrowser = browser
+	return sa
+}
+
+// redirect simula o Strava redirecionando o navegador para o callback com os parâmetros dados.
+// state vazio usa o state da URL de autorização.
+func redirect(t *testing.T, authURL string, params url.Values) (int, string) {
+	t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Errorf("URL de autorização inválida: %v", err)
+		return 0, ""
+	}
+	if !params.Has("state") {
+		params.Set("state", parsed.Query().Get("state"))
+	}
+
+	resp, err := http.Get(parsed.Query().Get("redirect_uri") + "?" + params.Encode())
+	if err != nil {
+		t.Errorf("callback inacessível: %v", err)
+		return 0, ""
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestAuthorizeSuccess(t *testing.T) {
+	provider := newFakeProvider(t)
+	var authURL string
+	done := make(chan struct{})
+	sa := newTestAuth(t, provider, CallbackOptions{Timeout: 5 * time.Second}, func(u string) error {
+		authURL = u
+		go func() {
+			defer close(done)
+			status, body := redirect(t, u, url.Values{"code": {"valid-code"}, "scope": {"read,activity:read"}})
+			if status != http.StatusOK || !strings.Contains(body, "Conectado ao Strava") {
+				t.Errorf("página de sucesso: HTTP %d %s", status, body)
+			}
+		}()
+		return nil
+	})
+
+	token, err := sa.AddAccount(context.Background())
+	<-done
+	if err != nil {
+		t.Fatalf("AddAccount: %v", err)
+	}
+	if token.AccessToken != "access-token" {
+		t.Errorf("AccessToken = %q", token.AccessToken)
+	}
+
+	query, _ := url.ParseQuery(strings.SplitN(authURL, "?", 2)[1])
+	if query.Get("scope") != "read,activity:read" {
+		t.Errorf("scope = %q, esperado separado por vírgula", query.Get("scope"))
+	}
+	if query.Get("approval_prompt") != "force" {
+		t.Errorf("AddAccount deveria forçar a tela de aprovação")
+	}
+	if redirectURI := query.Get("redirect_uri"); !strings.HasPrefix(redirectURI, "http://127.0.0.1:") {
+		t.Errorf("redirect_uri fora do loopback: %s", redirectURI)
+	}
+
+	account, ok := sa.Accounts().Active()
+	if !ok || account.AthleteID != 4242 || account.FirstName != "Ana" {
+		t.Fatalf("conta ativa = %+v, %v", account, ok)
+	}
+	if !account.HasScope(ScopeActivityRead) || account.HasScope(ScopeActivityReadAll) {
+		t.Errorf("escopos = %v", account.Scopes)
+	}
+	stored, err := sa.Accounts().StoreFor(account).Load()
+	if err != nil || stored.RefreshToken != "refresh-token" {
+		t.Errorf("token não salvo: %+v %v", stored, err)
+	}
+}
+
+func TestAuthorizeAccessDenied(t *testing.T) {
+	provider := newFakeProvider(t)
+	done := make(chan struct{})
+	sa := newTestAuth(t, provider, CallbackOptions{Timeout: 5 * time.Second}, func(u string) error {
+		go func() {
+			defer close(done)
+			status, body := redirect(t, u, url.Values{"error": {"access_denied"}})
+			if status != http.StatusOK || !strings.Contains(body, "Autorização não concedida") {
+				t.Errorf("página de acesso negado: HTTP %d %s", status, body)
+			}
+		}()
+		return nil
+	})
+
+	_, err := sa.AddAccount(context.Background())
+	<-done
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("erro = %v, esperado ErrAccessDenied", err)
+	}
+	if len(provider.exchangedCodes()) != 0 {
+		t.Error("nenhum código deveria ser trocado após a recusa")
+	}
+	if _, ok := sa.Accounts().Active(); ok {
+		t.Error("nenhuma conta deveria ser registrada")
+	}
+}
+
+func TestAuthorizeStateMismatch(t *testing.T) {
+	provider := newFakeProvider(t)
+	done := make(chan struct{})
+	sa := newTestAuth(t, provider, CallbackOptions{Timeout: 5 * time.Second}, func(u string) error {
+		go func() {
+			defer close(done)
+			// Uma resposta forjada é recusada sem encerrar o login em andamento
+			status, _ := redirect(t, u, url.Values{"code": {"forged-code"}, "state": {"outro-state"}})
+			if status != http.StatusBadRequest {
+				t.Errorf("state inválido: HTTP %d, esperado 400", status)
+			}
+
+			redirect(t, u, url.Values{"code": {"valid-code"}, "scope": {"read"}})
+		}()
+		return nil
+	})
+
+	token, err := sa.AddAccount(context.Background())
+	<-done
+	if err != nil {
+		t.Fatalf("AddAccount: %v", err)
+	}
+	if token.AccessToken != "access-token" {
+		t.Errorf("AccessToken = %q", token.AccessToken)
+	}
+	for _, code := range provider.exchangedCodes() {
+		if code == "forged-code" {
+			t.Error("código com state inválido foi trocado")
+		}
+	}
+}
+
+func TestAuthorizeTimeout(t *testing.T) {
+	provider := newFakeProvider(t)
+	sa := newTestAuth(t, provider, CallbackOptions{Timeout: 200 * time.Millisecond}, func(string) error {
+		return nil // O usuário nunca conclui a autorização
+	})
+
+	start := time.Now()
+	_, err := sa.AddAccount(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "tempo esgotado") {
+		t.Fatalf("erro = %v, esperado tempo esgotado", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("timeout demorou %s", elapsed)
+	}
+}
+
+func TestAuthorizePortRangeExhausted(t *testing.T) {
+	// Ocupa duas portas consecutivas para esgotar a faixa configurada
+	first, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	port := first.Addr().(*net.TCPAddr).Port
+	second, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port+1))
+	if err != nil {
+		t.Skipf("porta %d indisponível para o teste: %v", port+1, err)
+	}
+	defer second.Close()
+
+	provider := newFakeProvider(t)
+	browserOpened := false
+	sa := newTestAuth(t, provider, CallbackOptions{FirstPort: port, LastPort: port + 1, Timeout: time.Second}, func(string) error {
+		browserOpened = true
+		return nil
+	})
+
+	_, err = sa.AddAccount(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "nenhuma porta livre") {
+		t.Fatalf("erro = %v, esperado faixa de portas esgotada", err)
+	}
+	if browserOpened {
+		t.Error("o navegador não deveria ser aberto sem servidor de callback")
+	}
+}
+
+func TestAuthorizePortRange(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	port := busy.Addr().(*net.TCPAddr).Port
+
+	listener, err := listenLoopback(CallbackOptions{FirstPort: port, LastPort: port + 5})
+	if err != nil {
+		t.Skipf("nenhuma porta livre perto de %d: %v", port, err)
+	}
+	defer listener.Close()
+
+	got := listener.Addr().(*net.TCPAddr).Port
+	if got <= port || got > port+5 {
+		t.Errorf("porta %d fora da faixa %d-%d ou ocupada", got, port, port+5)
+	}
+}
+
+func TestParseCallbackOptions(t *testing.T) {
+	tests := []struct {
+		ports, timeout string
+		first, last    int
+		wantErr        bool
+	}{
+		{"", "", 0, 0, false},
+		{"8080", "", 8080, 8080, false},
+		{"8080-8090", "2m", 8080, 8090, false},
+		{"8090-8080", "", 0, 0, true},
+		{"abc", "", 0, 0, true},
+		{"8080", "-1s", 0, 0, true},
+	}
+	for _, tt := range tests {
+		opts, err := ParseCallbackOptions(tt.ports, tt.timeout)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCallbackOptions(%q, %q) erro = %v", tt.ports, tt.timeout, err)
+			continue
+		}
+		if !tt.wantErr && (opts.FirstPort != tt.first || opts.LastPort != tt.last) {
+			t.Errorf("ParseCallbackOptions(%q) = %d-%d", tt.ports, opts.FirstPort, opts.LastPort)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrTokenNotFound indica que nenhum token foi salvo no armazenamento
+var ErrTokenNotFound = errors.New("token não encontrado")
+
+// TokenStore abstrai onde o token OAuth do Strava é persistido
+type TokenStore interface {
+	Load() (*oauth2.Token, error)
+	Save(token *oauth2.Token) error
+	Delete() error
+	Name() string
+}
+
+// TokenStoreOptions controla a escolha do armazenamento de tokens
+type TokenStoreOptions struct {
+	// Backend pode ser "auto", "keyring" ou "file"
+	Backend string
+	// Dir é o diretório base das credenciais (~/.strava-overlay)
+	Dir string
+	// Account identifica a entrada no keyring e o nome do arquivo criptografado
+	Account string
+	// Passphrase opcional para o arquivo criptografado; vazio usa chave derivada da máquina
+	Passphrase string
+}
+
+// NewTokenStore escolhe o armazenamento de tokens conforme as opções, preferindo o keyring do
+// sistema e caindo para o arquivo criptografado quando o keyring não está disponível
+func NewTokenStore(opts TokenStoreOptions) TokenStore {
+	if opts.Account == "" {
+		opts.Account = "default"
+	}
+
+	fileStore := NewEncryptedFileTokenStore(opts.Dir, opts.Account, opts.Passphrase)
+
+	switch opts.Backend {
+	case "file":
+		return fileStore
+	case "keyring":
+		return NewKeyringTokenStore(opts.Account)
+	default:
+		keyringStore := NewKeyringTokenStore(opts.Account)
+		if keyringStore.Available() {
+			return keyringStore
+		}
+		log.Printf("⚠️ Keyring do sistema indisponível, usando arquivo criptografado")
+		return fileStore
+	}
+}
+
+// MigrateLegacyTokenFile move um token.json em texto puro para o armazenamento seguro e remove o
+// arquivo antigo. Não faz nada se o arquivo não existir.
+func MigrateLegacyTokenFile(legacyPath string, store TokenStore) error {
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("erro ao ler token legado: %w", err)
+	}
+
+	var tokenData TokenData
+	if err := json.Unmarshal(data, &tokenData); err != nil {
+		return fmt.Errorf("token legado inválido: %w", err)
+	}
+	if tokenData.Token == nil {
+		return fmt.Errorf("token legado vazio")
+	}
+
+	if err := store.Save(tokenData.Token); err != nil {
+		return fmt.Errorf("erro ao migrar token para %s: %w", store.Name(), err)
+	}
+
+	if err := os.Remove(legacyPath); err != nil {
+		return fmt.Errorf("token migrado mas não foi possível remover %s: %w", legacyPath, err)
+	}
+
+	log.Printf("🔐 Token legado migrado para %s", store.Name())
+	return nil
+}
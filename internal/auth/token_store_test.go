@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func testToken() *oauth2.Token {
+	return &oauth2.Token{
+		AccessToken:  "access-123",
+		RefreshToken: "refresh-456",
+		TokenType:    "Bearer",
+		Expiry:       time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestEncryptedFileTokenStoreRoundTrip(t *testing.T) {
+	for _, passphrase := range []string{"", "segredo"} {
+		dir := t.TempDir()
+		store := NewEncryptedFileTokenStore(dir, "athlete_1", passphrase)
+
+		if _, err := store.Load(); !errors.Is(err, ErrTokenNotFound) {
+			t.Fatalf("Load sem arquivo = %v, esperado ErrTokenNotFound", err)
+		}
+
+		if err := store.Save(testToken()); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		loaded, err := store.Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if loaded.AccessToken != "access-123" || loaded.RefreshToken != "refresh-456" || !loaded.Expiry.Equal(testToken().Expiry) {
+			t.Errorf("token carregado = %+v", loaded)
+		}
+
+		// O arquivo não pode conter o token em texto puro
+		data, err := os.ReadFile(filepath.Join(dir, "tokens", "athlete_1.enc"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "access-123") || strings.Contains(string(data), "refresh-456") {
+			t.Errorf("arquivo cifrado contém o token em texto puro")
+		}
+
+		if err := store.Delete(); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.Load(); !errors.Is(err, ErrTokenNotFound) {
+			t.Errorf("Load após Delete = %v, esperado ErrTokenNotFound", err)
+		}
+	}
+}
+
+func TestEncryptedFileTokenStoreWrongPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewEncryptedFileTokenStore(dir, "a", "certa").Save(testToken()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewEncryptedFileTokenStore(dir, "a", "errada").Load(); err == nil {
+		t.Error("Load com passphrase errada deveria falhar")
+	}
+	if _, err := NewEncryptedFileTokenStore(dir, "a", "").Load(); err == nil {
+		t.Error("Load com chave da máquina deveria recusar arquivo cifrado com passphrase")
+	}
+}
+
+func TestMigrateLegacyTokenFile(t *testing.T) {
+	dir := t.TempDir()
+	legacyPath := filepath.Join(dir, "token.json")
+	data, err := json.Marshal(TokenData{Token: testToken(), ExpiresAt: testToken().Expiry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacyPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewMemoryTokenStore(nil)
+	if err := MigrateLegacyTokenFile(legacyPath, store); err != nil {
+		t.Fatalf("MigrateLegacyTokenFile: %v", err)
+	}
+
+	token, err := store.Load()
+	if err != nil {
+		t.Fatalf("token não migrado: %v", err)
+	}
+	if token.AccessToken != "access-123" {
+		t.Errorf("AccessToken = %q", token.AccessToken)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Errorf("arquivo legado não foi removido: %v", err)
+	}
+}
+
+func TestMigrateLegacyTokenFileMissing(t *testing.T) {
+	store := NewMemoryTokenStore(nil)
+	if err := MigrateLegacyTokenFile(filepath.Join(t.TempDir(), "token.json"), store); err != nil {
+		t.Fatalf("sem arquivo legado não deveria falhar: %v", err)
+	}
+	if _, err := store.Load(); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("nenhum token deveria ser salvo, Load = %v", err)
+	}
+}
+
+func TestMigrateLegacyTokenFileInvalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"invalid.json": "{not json",
+		"empty.json":   `{"token": null}`,
+	} {
+		legacyPath := filepath.Join(dir, name)
+		if err := os.WriteFile(legacyPath, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := MigrateLegacyTokenFile(legacyPath, NewMemoryTokenStore(nil)); err == nil {
+			t.Errorf("%s: esperado erro", name)
+		}
+		// O arquivo original é mantido quando a migração falha
+		if _, err := os.Stat(legacyPath); err != nil {
+			t.Errorf("%s: arquivo legado removido após falha", name)
+		}
+	}
+}
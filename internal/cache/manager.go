@@ -25,9 +25,14 @@ type ActivityCache struct {
 	Hash         string                 `json:"hash"`
 }
 
-func NewCacheManager() *CacheManager {
+// NewCacheManager cria o cache da conta informada (auth.Account.Key). Cada atleta tem seu próprio
+// diretório para que atividades de contas diferentes nunca se misturem.
+func NewCacheManager(accountKey string) *CacheManager {
 	homeDir, _ := os.UserHomeDir()
 	cacheDir := filepath.Join(homeDir, ".strava-overlay", "cache")
+	if accountKey != "" {
+		cacheDir = filepath.Join(cacheDir, accountKey)
+	}
 	os.MkdirAll(cacheDir, 0755)
 
 	return &CacheManager{
@@ -95,6 +100,54 @@ func (cm *CacheManager) GetCachedGPSData(activityID int64, streams map[string]in
 	return &cache, true
 }
 
+// cachedStreams guarda os streams brutos de uma atividade numa resolução (variant)
+type cachedStreams struct {
+	ActivityID int64             `json:"activity_id"`
+	Variant    string            `json:"variant"`
+	CachedAt   time.Time         `json:"cached_at"`
+	Streams    *strava.StreamSet `json:"streams"`
+}
+
+// CacheStreams guarda os streams da atividade, evitando baixá-los de novo do Strava a cada
+// pré-visualização, clique no mapa ou renderização
+func (cm *CacheManager) CacheStreams(activityID int64, variant string, streams *strava.StreamSet) error {
+	data, err := json.Marshal(cachedStreams{
+		ActivityID: activityID,
+		Variant:    variant,
+		CachedAt:   time.Now(),
+		Streams:    streams,
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(cm.cacheDir, cm.GetCacheKey(activityID, "streams_"+variant)), data, 0644)
+}
+
+// GetCachedStreams retorna os streams guardados por CacheStreams, válidos por 7 dias
+func (cm *CacheManager) GetCachedStreams(activityID int64, variant string) (*strava.StreamSet, bool) {
+	data, err := os.ReadFile(filepath.Join(cm.cacheDir, cm.GetCacheKey(activityID, "streams_"+variant)))
+	if err != nil {
+		return nil, false
+	}
+
+	var cache cachedStreams
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Streams == nil || cache.Streams.Time == nil || cache.Streams.LatLng == nil {
+		return nil, false
+	}
+
+	if time.Since(cache.CachedAt) > 7*24*time.Hour {
+		return nil, false
+	}
+
+	return cache.Streams, true
+}
+
+// Dir retorna o diretório do cache
+func (cm *CacheManager) Dir() string {
+	return cm.cacheDir
+}
+
 // Cache de overlays gerados
 type OverlayCache struct {
 	ActivityID   int64           `json:"activity_id"`
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+
+	"strava-overlay/internal/strava"
+)
+
+func testStreams() *strava.StreamSet {
+	return &strava.StreamSet{
+		Time:     &strava.IntStream{Data: []int{0, 1, 2}},
+		LatLng:   &strava.LatLngStream{StreamMeta: strava.StreamMeta{Resolution: "high"}, Data: [][2]float64{{-23.5, -46.6}, {-23.50001, -46.60001}, {-23.50002, -46.60002}}},
+		Velocity: &strava.FloatStream{Data: []float64{0, 4.5, 5}},
+		Moving:   &strava.BoolStream{Data: []bool{false, true, true}},
+	}
+}
+
+func TestCacheStreamsRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cm := NewCacheManager("123")
+
+	if _, ok := cm.GetCachedStreams(42, "original_time"); ok {
+		t.Fatal("cache vazio não deveria ter streams")
+	}
+	if err := cm.CacheStreams(42, "original_time", testStreams()); err != nil {
+		t.Fatalf("CacheStreams: %v", err)
+	}
+
+	streams, ok := cm.GetCachedStreams(42, "original_time")
+	if !ok {
+		t.Fatal("streams não encontrados no cache")
+	}
+	if streams.Len() != 3 || streams.LatLng.Data[2] != [2]float64{-23.50002, -46.60002} || streams.Resolution() != "high" {
+		t.Errorf("streams diferentes após o cache: %+v", streams)
+	}
+	if streams.Altitude != nil || streams.HeartRate != nil {
+		t.Error("streams ausentes deveriam continuar nil")
+	}
+	if !streams.Moving.Data[1] || streams.Velocity.Data[2] != 5 {
+		t.Errorf("moving/velocity diferentes: %+v %+v", streams.Moving, streams.Velocity)
+	}
+
+	// Outra resolução é outra entrada
+	if _, ok := cm.GetCachedStreams(42, "low_time"); ok {
+		t.Error("resolução diferente não deveria usar o mesmo cache")
+	}
+}
+
+func TestCachePerAccount(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	first := NewCacheManager("111")
+	second := NewCacheManager("222")
+	if first.Dir() == second.Dir() {
+		t.Fatalf("contas diferentes compartilham o diretório %s", first.Dir())
+	}
+	if want := filepath.Join(home, ".strava-overlay", "cache", "111"); first.Dir() != want {
+		t.Errorf("Dir = %s, esperado %s", first.Dir(), want)
+	}
+
+	if err := first.CacheStreams(42, "original_time", testStreams()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := second.GetCachedStreams(42, "original_time"); ok {
+		t.Error("atividade de uma conta visível no cache de outra")
+	}
+	if _, ok := NewCacheManager("111").GetCachedStreams(42, "original_time"); !ok {
+		t.Error("cache da conta não foi reaproveitado")
+	}
+}
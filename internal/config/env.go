@@ -22,6 +22,57 @@ type Config struct {
 	MapboxPublicToken string
 	MapboxSecretToken string
 
+	// Armazenamento do token: "auto", "keyring" ou "file"
+	TokenStorage    string
+	TokenPassphrase string
+
+	// Servidor local do callback OAuth: porta ou faixa ("8080-8090"); vazio escolhe uma porta livre
+	OAuthCallbackPorts string
+	OAuthTimeout       string
+
+	// Estilo do widget de G-force: "text" ou "friction-circle"
+	OverlayGMeterStyle string
+	// Widgets da pilha do overlay, separados por vírgula (ex.: "gforce,distance,elapsed,ascent")
+	OverlayWidgets string
+	// Overlay durante paradas: "none", "freeze" ou "hide"
+	OverlayStopBehavior string
+	// Banner com tempo e rank ao passar por segmentos do Strava
+	OverlaySegmentBanner bool
+	// Indicação "PAUSED" nos trechos em que o Strava marcou o atleta como parado
+	OverlayShowPaused bool
+	// Fator sobre o tamanho automático do overlay, que acompanha a resolução do vídeo ("1", "1.5")
+	OverlayScale string
+
+	// Formatos da trilha do clipe gravados junto ao vídeo: desativado por padrão ("none"),
+	// "all" ou uma lista ("gpx,fit,csv,geojson")
+	TrackExportFormats string
+
+	// Legendas de telemetria: formatos ("srt,vtt,ass" ou "none"), modelo do texto e inclusão no vídeo
+	SubtitleFormats  string
+	SubtitleTemplate string
+	SubtitleMux      bool
+
+	// Container do vídeo de saída ("mp4" ou "mkv") e telemetria embutida no próprio arquivo
+	OutputContainer string
+	EmbedTelemetry  bool
+
+	// Rascunho: lado menor em pixels ("480"), quadros por segundo e duração padrão ("30s")
+	DraftHeight    string
+	DraftFrameRate string
+	DraftDuration  string
+
+	// Destaques automáticos: quantidade padrão ("5") e duração de cada destaque ("20s")
+	HighlightCount  string
+	HighlightWindow string
+
+	// Filtros da trilha: velocidade máxima em m/s ("40", "0" desativa) e suavização por Kalman
+	FilterMaxSpeed string
+	FilterKalman   string
+
+	// Streams do Strava: resolução ("original", "low", "medium" ou "high") e eixo ("time" ou "distance")
+	StreamResolution string
+	StreamSeriesType string
+
 	// App
 	AppVersion         string
 	Environment        string
@@ -49,6 +100,51 @@ func LoadConfig() error {
 		MapboxPublicToken: getEnv("MAPBOX_PUBLIC_TOKEN", ""),
 		MapboxSecretToken: getEnv("MAPBOX_SECRET_TOKEN", ""),
 
+		// Token (opcional)
+		TokenStorage:    getEnv("TOKEN_STORAGE", "auto"),
+		TokenPassphrase: getEnv("TOKEN_PASSPHRASE", ""),
+
+		// OAuth (opcional)
+		OAuthCallbackPorts: getEnv("OAUTH_CALLBACK_PORTS", ""),
+		OAuthTimeout:       getEnv("OAUTH_TIMEOUT", "5m"),
+
+		// Overlay (opcional)
+		OverlayGMeterStyle:   getEnv("OVERLAY_GMETER_STYLE", "text"),
+		OverlayWidgets:       getEnv("OVERLAY_WIDGETS", "gforce,altitude,cadence,heart"),
+		OverlayStopBehavior:  getEnv("OVERLAY_STOP_BEHAVIOR", "none"),
+		OverlaySegmentBanner: getEnv("OVERLAY_SEGMENT_BANNER", "true") == "true",
+		OverlayShowPaused:    getEnv("OVERLAY_SHOW_PAUSED", "false") == "true",
+		OverlayScale:         getEnv("OVERLAY_SCALE", "1"),
+
+		// Exportação (opcional)
+		TrackExportFormats: getEnv("TRACK_EXPORT_FORMATS", "none"),
+
+		// Legendas (opcional)
+		SubtitleFormats:  getEnv("SUBTITLE_FORMATS", "none"),
+		SubtitleTemplate: getEnv("SUBTITLE_TEMPLATE", ""),
+		SubtitleMux:      getEnv("SUBTITLE_MUX", "false") == "true",
+
+		// Saída (opcional)
+		OutputContainer: getEnv("OUTPUT_CONTAINER", "mp4"),
+		EmbedTelemetry:  getEnv("EMBED_TELEMETRY", "false") == "true",
+
+		// Rascunho (opcional)
+		DraftHeight:    getEnv("DRAFT_HEIGHT", "480"),
+		DraftFrameRate: getEnv("DRAFT_FRAME_RATE", "15"),
+		DraftDuration:  getEnv("DRAFT_DURATION", "30s"),
+
+		// Destaques (opcional)
+		HighlightCount:  getEnv("HIGHLIGHT_COUNT", "5"),
+		HighlightWindow: getEnv("HIGHLIGHT_WINDOW", "20s"),
+
+		// Filtros (opcional)
+		FilterMaxSpeed: getEnv("GPS_FILTER_MAX_SPEED", "40"),
+		FilterKalman:   getEnv("GPS_FILTER_KALMAN", "true"),
+
+		// Streams (opcional)
+		StreamResolution: getEnv("STRAVA_STREAM_RESOLUTION", "original"),
+		StreamSeriesType: getEnv("STRAVA_STREAM_SERIES_TYPE", "time"),
+
 		// App
 		AppVersion:         getEnv("APP_VERSION", "1.0.0"),
 		Environment:        getEnv("APP_ENV", "development"),
@@ -96,8 +192,13 @@ func maskString(s string) string {
 	return s[:4] + "****" + s[len(s)-4:]
 }
 
+// GetDataDir retorna o diretório de dados da aplicação
+func GetDataDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".strava-overlay")
+}
+
 // GetConfigPath retorna o caminho para o arquivo de configuração
 func GetConfigPath() string {
-	homeDir, _ := os.UserHomeDir()
-	return filepath.Join(homeDir, ".strava-overlay", "config")
+	return filepath.Join(GetDataDir(), "config")
 }
@@ -0,0 +1,48 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+var csvHeader = []string{
+	"video_time_s", "time_utc", "lat", "lng", "altitude_m", "speed_kmh", "distance_m",
+	"bearing", "gforce", "longitudinal_g", "lateral_g", "grade_pct",
+}
+
+// WriteCSV grava o clipe como CSV, um ponto por linha. A primeira coluna é a posição no vídeo
+// em segundos, pronta para editores que posicionam dados pela linha do tempo.
+func WriteCSV(w io.Writer, clip Clip) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, point := range clip.Points {
+		record := []string{
+			formatFloat(clip.VideoOffset(point).Seconds(), 3),
+			gpxTime(point.Time),
+			formatFloat(point.Lat, 7),
+			formatFloat(point.Lng, 7),
+			formatFloat(point.Altitude, 1),
+			formatFloat(point.Velocity*3.6, 2),
+			formatFloat(clip.clipDistance(point), 1),
+			formatFloat(point.Bearing, 1),
+			formatFloat(point.GForce, 3),
+			formatFloat(point.LongitudinalG, 3),
+			formatFloat(point.LateralG, 3),
+			formatFloat(point.Grade, 1),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatFloat(value float64, precision int) string {
+	return strconv.FormatFloat(value, 'f', precision, 64)
+}
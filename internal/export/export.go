@@ -0,0 +1,132 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"strava-overlay/internal/gps"
+)
+
+// Formatos de exportação da trilha do clipe
+const (
+	FormatGPX     = "gpx"
+	FormatFIT     = "fit"
+	FormatCSV     = "csv"
+	FormatGeoJSON = "geojson"
+)
+
+// AllFormats são os formatos gerados com "all"; a exportação fica desativada até ser pedida
+var AllFormats = []string{FormatGPX, FormatFIT, FormatCSV, FormatGeoJSON}
+
+// ClipWriter grava o clipe em um formato de arquivo
+type ClipWriter func(w io.Writer, clip Clip) error
+
+var writers = map[string]ClipWriter{
+	FormatGPX:     WriteGPX,
+	FormatFIT:     WriteFIT,
+	FormatCSV:     WriteCSV,
+	FormatGeoJSON: WriteGeoJSON,
+}
+
+// Clip é o trecho da atividade coberto por um vídeo. Os pontos vêm de
+// GPSProcessor.GetPointsForTimeRange e VideoStart é o instante GPS do primeiro quadro, de modo
+// que cada ponto fica a point.Time - VideoStart do início do vídeo.
+type Clip struct {
+	Name         string
+	ActivityID   int64
+	ActivityType string
+	VideoStart   time.Time
+	Points       []gps.GPSPoint
+}
+
+// VideoOffset retorna a posição do ponto na linha do tempo do vídeo
+func (c Clip) VideoOffset(point gps.GPSPoint) time.Duration {
+	return point.Time.Sub(c.VideoStart)
+}
+
+// clipDistance retorna a distância percorrida desde o início do clipe até o ponto
+func (c Clip) clipDistance(point gps.GPSPoint) float64 {
+	if len(c.Points) == 0 {
+		return 0
+	}
+	return point.Distance - c.Points[0].Distance
+}
+
+// ParseFormats interpreta uma lista separada por vírgulas ("gpx,csv"), ignorando formatos
+// desconhecidos ou repetidos. "none" ou vazio desativa a exportação e "all" ativa todos.
+func ParseFormats(list string) []string {
+	var formats []string
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if name == "none" {
+			return nil
+		}
+		if name == "all" {
+			return append([]string(nil), AllFormats...)
+		}
+		if writers[name] == nil {
+			log.Printf("⚠️ Formato de exportação desconhecido ignorado: %s", name)
+			continue
+		}
+		seen[name] = true
+		formats = append(formats, name)
+	}
+
+	return formats
+}
+
+// WriteClip grava a trilha do clipe em cada formato, ao lado de basePath (o caminho do vídeo
+// renderizado), trocando a extensão. Retorna os arquivos gerados.
+func WriteClip(basePath string, clip Clip, formats []string) ([]string, error) {
+	return writeFormats(basePath, clip, formats, writers, "exportação")
+}
+
+// writeFormats grava o clipe com o writer de cada formato em basePath com a extensão do
+// formato. kind nomeia o tipo de arquivo nas mensagens de erro.
+func writeFormats(basePath string, clip Clip, formats []string, formatWriters map[string]ClipWriter, kind string) ([]string, error) {
+	if len(clip.Points) == 0 {
+		return nil, fmt.Errorf("nenhum ponto GPS no intervalo do vídeo")
+	}
+
+	base := strings.TrimSuffix(basePath, filepath.Ext(basePath))
+	var paths []string
+
+	for _, format := range formats {
+		write := formatWriters[format]
+		if write == nil {
+			return paths, fmt.Errorf("formato de %s desconhecido: %s", kind, format)
+		}
+
+		path := base + "." + format
+		if err := writeFile(path, clip, write); err != nil {
+			return paths, fmt.Errorf("erro ao gerar %s: %w", filepath.Base(path), err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+func writeFile(path string, clip Clip, write ClipWriter) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file, clip); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,201 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"strava-overlay/internal/gps"
+)
+
+var clipStart = time.Date(2024, 3, 10, 11, 0, 0, 0, time.UTC)
+
+// testClip gera 60 pontos de 1 Hz a 10 m/s, com o vídeo começando 2 s antes do primeiro
+func testClip() Clip {
+	points := make([]gps.GPSPoint, 60)
+	for i := range points {
+		points[i] = gps.GPSPoint{
+			Time:     clipStart.Add(time.Duration(i) * time.Second),
+			Lat:      -23.55 + float64(i)*0.0001,
+			Lng:      -46.63 - float64(i)*0.00005,
+			Altitude: 760 + float64(i)/10,
+			Velocity: 10,
+			Bearing:  335,
+			Distance: 5000 + 10*float64(i),
+		}
+	}
+	return Clip{
+		Name:         "Pedal de domingo",
+		ActivityID:   987654321,
+		ActivityType: "Ride",
+		VideoStart:   clipStart.Add(-2 * time.Second),
+		Points:       points,
+	}
+}
+
+func TestParseFormats(t *testing.T) {
+	tests := map[string][]string{
+		"":                 nil,
+		"none":             nil,
+		"all":              AllFormats,
+		"GPX, csv,gpx":     {FormatGPX, FormatCSV},
+		"fit,desconhecido": {FormatFIT},
+		"geojson,none,gpx": nil,
+	}
+	for list, want := range tests {
+		if got := ParseFormats(list); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseFormats(%q) = %v, esperado %v", list, got, want)
+		}
+	}
+}
+
+func TestWriteClip(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := WriteClip(filepath.Join(dir, "video_overlay.mp4"), testClip(), AllFormats)
+	if err != nil {
+		t.Fatalf("WriteClip: %v", err)
+	}
+
+	if len(paths) != len(AllFormats) {
+		t.Fatalf("esperava %d arquivos, obteve %v", len(AllFormats), paths)
+	}
+	for i, format := range AllFormats {
+		if want := filepath.Join(dir, "video_overlay."+format); paths[i] != want {
+			t.Errorf("arquivo %d = %s, esperado %s", i, paths[i], want)
+		}
+		if info, err := os.Stat(paths[i]); err != nil || info.Size() == 0 {
+			t.Errorf("%s ausente ou vazio: %v", paths[i], err)
+		}
+	}
+
+	if _, err := WriteClip(filepath.Join(dir, "vazio.mp4"), Clip{}, AllFormats); err == nil {
+		t.Error("clipe sem pontos deveria falhar")
+	}
+}
+
+func TestWriteGPX(t *testing.T) {
+	clip := testClip()
+	var buf bytes.Buffer
+	if err := WriteGPX(&buf, clip); err != nil {
+		t.Fatalf("WriteGPX: %v", err)
+	}
+
+	var doc struct {
+		Version  string `xml:"version,attr"`
+		Metadata struct {
+			Desc string `xml:"desc"`
+			Time string `xml:"time"`
+		} `xml:"metadata"`
+		Track struct {
+			Name   string `xml:"name"`
+			Type   string `xml:"type"`
+			Points []struct {
+				Lat   float64 `xml:"lat,attr"`
+				Lon   float64 `xml:"lon,attr"`
+				Ele   float64 `xml:"ele"`
+				Time  string  `xml:"time"`
+				Speed string  `xml:"extensions>TrackPointExtension>speed"`
+			} `xml:"trkseg>trkpt"`
+		} `xml:"trk"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("GPX inválido: %v", err)
+	}
+
+	if doc.Version != "1.1" || doc.Track.Name != clip.Name || doc.Track.Type != "Ride" {
+		t.Errorf("cabeçalho inesperado: versão %q, nome %q, tipo %q", doc.Version, doc.Track.Name, doc.Track.Type)
+	}
+	if doc.Metadata.Time != "2024-03-10T10:59:58.000Z" {
+		t.Errorf("início do vídeo = %q", doc.Metadata.Time)
+	}
+	if len(doc.Track.Points) != len(clip.Points) {
+		t.Fatalf("esperava %d pontos, obteve %d", len(clip.Points), len(doc.Track.Points))
+	}
+	last := doc.Track.Points[len(doc.Track.Points)-1]
+	want := clip.Points[len(clip.Points)-1]
+	if last.Lat != want.Lat || last.Lon != want.Lng || last.Ele != want.Altitude {
+		t.Errorf("último ponto = %+v, esperado %+v", last, want)
+	}
+	if last.Time != "2024-03-10T11:00:59.000Z" || last.Speed != "10.00" {
+		t.Errorf("último ponto com horário %q e velocidade %q", last.Time, last.Speed)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	clip := testClip()
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, clip); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV inválido: %v", err)
+	}
+	if !reflect.DeepEqual(records[0], csvHeader) {
+		t.Errorf("cabeçalho = %v", records[0])
+	}
+	if len(records) != len(clip.Points)+1 {
+		t.Fatalf("esperava %d linhas, obteve %d", len(clip.Points)+1, len(records))
+	}
+
+	first := records[1]
+	if first[0] != "2.000" {
+		t.Errorf("o primeiro ponto deveria estar a 2s do início do vídeo, está em %s", first[0])
+	}
+	if first[5] != "36.00" {
+		t.Errorf("velocidade em km/h = %s, esperado 36.00", first[5])
+	}
+	lastDistance, _ := strconv.ParseFloat(records[len(records)-1][6], 64)
+	if lastDistance != 590 {
+		t.Errorf("distância do clipe deveria começar em zero e chegar a 590 m, chegou a %v", lastDistance)
+	}
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	clip := testClip()
+	var buf bytes.Buffer
+	if err := WriteGeoJSON(&buf, clip); err != nil {
+		t.Fatalf("WriteGeoJSON: %v", err)
+	}
+
+	var doc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string       `json:"type"`
+				Coordinates [][3]float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties struct {
+				ActivityID int64     `json:"activity_id"`
+				CoordTimes []string  `json:"coordTimes"`
+				VideoTimes []float64 `json:"videoTimes"`
+			} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("GeoJSON inválido: %v", err)
+	}
+
+	if doc.Type != "FeatureCollection" || len(doc.Features) != 1 || doc.Features[0].Geometry.Type != "LineString" {
+		t.Fatalf("estrutura inesperada: %+v", doc)
+	}
+	feature := doc.Features[0]
+	if len(feature.Geometry.Coordinates) != len(clip.Points) || len(feature.Properties.CoordTimes) != len(clip.Points) {
+		t.Fatalf("esperava %d coordenadas e horários", len(clip.Points))
+	}
+	// GeoJSON ordena as coordenadas como [lng, lat, altitude]
+	if got := feature.Geometry.Coordinates[0]; got != [3]float64{-46.63, -23.55, 760} {
+		t.Errorf("primeira coordenada = %v", got)
+	}
+	if feature.Properties.VideoTimes[0] != 2 || feature.Properties.ActivityID != clip.ActivityID {
+		t.Errorf("propriedades inesperadas: %+v", feature.Properties)
+	}
+}
@@ -0,0 +1,257 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"strava-overlay/internal/gps"
+)
+
+// Constantes do protocolo FIT usadas pelo arquivo de percurso (course)
+const (
+	fitProtocolVersion = 0x10
+	fitProfileVersion  = 2132
+	fitEpochOffset     = 631065600 // Segundos entre 1970-01-01 e 1989-12-31, a época do FIT
+
+	fitMesgFileID = 0
+	fitMesgLap    = 19
+	fitMesgRecord = 20
+	fitMesgEvent  = 21
+	fitMesgCourse = 31
+
+	fitBaseEnum   = 0x00
+	fitBaseString = 0x07
+	fitBaseUint16 = 0x84
+	fitBaseSint32 = 0x85
+	fitBaseUint32 = 0x86
+
+	fitFileCourse         = 6
+	fitManufacturerDev    = 255
+	fitEventTimer         = 0
+	fitEventTypeStart     = 0
+	fitEventTypeStopAll   = 4
+	fitCourseNameSize     = 32
+	fitSemicirclesPerDeg  = 2147483648.0 / 180
+	fitInvalidUint16      = 0xFFFF
+	fitInvalidUint32      = 0xFFFFFFFF
+	fitAltitudeScale      = 5
+	fitAltitudeOffset     = 500
+	fitDistanceScale      = 100
+	fitSpeedScale         = 1000
+	fitElapsedTimeScale   = 1000
+	fitLocalFileID        = 0
+	fitLocalCourse        = 1
+	fitLocalLap           = 2
+	fitLocalEvent         = 3
+	fitLocalRecord        = 4
+	fitDefinitionHeader   = 0x40
+	fitHeaderSize         = 14
+	fitArchitectureLittle = 0
+)
+
+// fitField descreve um campo na mensagem de definição
+type fitField struct {
+	number   byte
+	size     byte
+	baseType byte
+}
+
+// fitSports mapeia o tipo de atividade do Strava para o esporte do FIT
+var fitSports = map[string]byte{
+	"Run":          1,
+	"TrailRun":     1,
+	"VirtualRun":   1,
+	"Ride":         2,
+	"MountainBike": 2,
+	"GravelRide":   2,
+	"EBikeRide":    2,
+	"VirtualRide":  2,
+	"Swim":         5,
+	"Walk":         11,
+	"Hike":         17,
+}
+
+// WriteFIT grava o clipe como um percurso FIT (course), importável em ciclocomputadores e
+// relógios. Os registros usam os instantes GPS sincronizados com o vídeo.
+func WriteFIT(w io.Writer, clip Clip) error {
+	var body bytes.Buffer
+	first := clip.Points[0]
+	last := clip.Points[len(clip.Points)-1]
+
+	// file_id
+	writeFITDefinition(&body, fitLocalFileID, fitMesgFileID, []fitField{
+		{0, 1, fitBaseEnum},   // type
+		{1, 2, fitBaseUint16}, // manufacturer
+		{2, 2, fitBaseUint16}, // product
+		{4, 4, fitBaseUint32}, // time_created
+	})
+	writeFITData(&body, fitLocalFileID,
+		byte(fitFileCourse), uint16(fitManufacturerDev), uint16(0), fitTimestamp(clip.VideoStart))
+
+	// course
+	writeFITDefinition(&body, fitLocalCourse, fitMesgCourse, []fitField{
+		{4, 1, fitBaseEnum},                   // sport
+		{5, fitCourseNameSize, fitBaseString}, // name
+	})
+	writeFITData(&body, fitLocalCourse, fitSports[clip.ActivityType], fitString(clip.Name, fitCourseNameSize))
+
+	// lap único cobrindo o clipe inteiro
+	elapsed := last.Time.Sub(first.Time)
+	writeFITDefinition(&body, fitLocalLap, fitMesgLap, []fitField{
+		{253, 4, fitBaseUint32}, // timestamp
+		{2, 4, fitBaseUint32},   // start_time
+		{3, 4, fitBaseSint32},   // start_position_lat
+		{4, 4, fitBaseSint32},   // start_position_long
+		{5, 4, fitBaseSint32},   // end_position_lat
+		{6, 4, fitBaseSint32},   // end_position_long
+		{7, 4, fitBaseUint32},   // total_elapsed_time
+		{8, 4, fitBaseUint32},   // total_timer_time
+		{9, 4, fitBaseUint32},   // total_distance
+	})
+	writeFITData(&body, fitLocalLap,
+		fitTimestamp(last.Time), fitTimestamp(first.Time),
+		fitSemicircles(first.Lat), fitSemicircles(first.Lng),
+		fitSemicircles(last.Lat), fitSemicircles(last.Lng),
+		fitScaled(elapsed.Seconds(), fitElapsedTimeScale), fitScaled(elapsed.Seconds(), fitElapsedTimeScale),
+		fitScaled(clip.clipDistance(last), fitDistanceScale))
+
+	// event de início
+	writeFITDefinition(&body, fitLocalEvent, fitMesgEvent, []fitField{
+		{253, 4, fitBaseUint32}, // timestamp
+		{0, 1, fitBaseEnum},     // event
+		{1, 1, fitBaseEnum},     // event_type
+	})
+	writeFITData(&body, fitLocalEvent, fitTimestamp(first.Time), byte(fitEventTimer), byte(fitEventTypeStart))
+
+	// records
+	writeFITDefinition(&body, fitLocalRecord, fitMesgRecord, []fitField{
+		{253, 4, fitBaseUint32}, // timestamp
+		{0, 4, fitBaseSint32},   // position_lat
+		{1, 4, fitBaseSint32},   // position_long
+		{2, 2, fitBaseUint16},   // altitude
+		{5, 4, fitBaseUint32},   // distance
+		{6, 2, fitBaseUint16},   // speed
+	})
+	for _, point := range clip.Points {
+		writeFITData(&body, fitLocalRecord,
+			fitTimestamp(point.Time),
+			fitSemicircles(point.Lat), fitSemicircles(point.Lng),
+			fitAltitude(point),
+			fitScaled(clip.clipDistance(point), fitDistanceScale),
+			fitSpeed(point.Velocity))
+	}
+
+	// event de fim
+	writeFITData(&body, fitLocalEvent, fitTimestamp(last.Time), byte(fitEventTimer), byte(fitEventTypeStopAll))
+
+	header := make([]byte, fitHeaderSize)
+	header[0] = fitHeaderSize
+	header[1] = fitProtocolVersion
+	binary.LittleEndian.PutUint16(header[2:], fitProfileVersion)
+	binary.LittleEndian.PutUint32(header[4:], uint32(body.Len()))
+	copy(header[8:], ".FIT")
+	binary.LittleEndian.PutUint16(header[12:], fitCRC(0, header[:12]))
+
+	crc := fitCRC(fitCRC(0, header), body.Bytes())
+	footer := make([]byte, 2)
+	binary.LittleEndian.PutUint16(footer, crc)
+
+	for _, chunk := range [][]byte{header, body.Bytes(), footer} {
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFITDefinition grava a mensagem de definição que descreve os campos do tipo local
+func writeFITDefinition(buf *bytes.Buffer, local byte, global uint16, fields []fitField) {
+	buf.WriteByte(fitDefinitionHeader | local)
+	buf.WriteByte(0) // reservado
+	buf.WriteByte(fitArchitectureLittle)
+	binary.Write(buf, binary.LittleEndian, global)
+	buf.WriteByte(byte(len(fields)))
+	for _, field := range fields {
+		buf.Write([]byte{field.number, field.size, field.baseType})
+	}
+}
+
+// writeFITData grava uma mensagem de dados com os valores na ordem da definição
+func writeFITData(buf *bytes.Buffer, local byte, values ...interface{}) {
+	buf.WriteByte(local)
+	for _, value := range values {
+		binary.Write(buf, binary.LittleEndian, value)
+	}
+}
+
+func fitTimestamp(t time.Time) uint32 {
+	return uint32(t.Unix() - fitEpochOffset)
+}
+
+func fitSemicircles(degrees float64) int32 {
+	return int32(math.Round(degrees * fitSemicirclesPerDeg))
+}
+
+// fitScaled aplica a escala do campo, marcando como inválido o que não cabe em uint32
+func fitScaled(value, scale float64) uint32 {
+	scaled := math.Round(value * scale)
+	if scaled < 0 || scaled >= fitInvalidUint32 || math.IsNaN(scaled) {
+		return fitInvalidUint32
+	}
+	return uint32(scaled)
+}
+
+func fitAltitude(point gps.GPSPoint) uint16 {
+	scaled := math.Round((point.Altitude + fitAltitudeOffset) * fitAltitudeScale)
+	if scaled < 0 || scaled >= fitInvalidUint16 {
+		return fitInvalidUint16
+	}
+	return uint16(scaled)
+}
+
+func fitSpeed(velocity float64) uint16 {
+	scaled := math.Round(velocity * fitSpeedScale)
+	if scaled < 0 || scaled >= fitInvalidUint16 {
+		return fitInvalidUint16
+	}
+	return uint16(scaled)
+}
+
+// fitString ajusta o texto ao tamanho fixo do campo, terminado em zero
+func fitString(value string, size int) []byte {
+	value = strings.ToValidUTF8(value, "")
+	field := make([]byte, size)
+	if len(value) > size-1 {
+		value = value[:size-1]
+		// Não corta um caractere UTF-8 ao meio
+		for !utf8.ValidString(value) {
+			value = value[:len(value)-1]
+		}
+	}
+	copy(field, value)
+	return field
+}
+
+var fitCRCTable = [16]uint16{
+	0x0000, 0xCC01, 0xD801, 0x1400, 0xF001, 0x3C00, 0x2800, 0xE401,
+	0xA001, 0x6C00, 0x7800, 0xB401, 0x5000, 0x9C01, 0x8801, 0x4400,
+}
+
+// fitCRC é o CRC-16 definido pelo protocolo FIT
+func fitCRC(crc uint16, data []byte) uint16 {
+	for _, b := range data {
+		tmp := fitCRCTable[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ fitCRCTable[b&0xF]
+
+		tmp = fitCRCTable[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ fitCRCTable[(b>>4)&0xF]
+	}
+	return crc
+}
@@ -0,0 +1,151 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// fitMessage é uma mensagem de dados decodificada, com os campos pelo número
+type fitMessage struct {
+	global uint16
+	fields map[byte][]byte
+}
+
+// parseFIT lê o arquivo pela definição de cada tipo local, como um leitor FIT faria
+func parseFIT(t *testing.T, data []byte) []fitMessage {
+	t.Helper()
+
+	size := int(data[0])
+	end := size + int(binary.LittleEndian.Uint32(data[4:8]))
+	if end+2 != len(data) {
+		t.Fatalf("tamanho dos dados no cabeçalho (%d) não bate com o arquivo (%d)", end-size, len(data)-size-2)
+	}
+
+	type definition struct {
+		global uint16
+		fields []fitField
+	}
+	definitions := make(map[byte]definition)
+	var messages []fitMessage
+
+	for pos := size; pos < end; {
+		header := data[pos]
+		local := header & 0x0F
+		pos++
+
+		if header&fitDefinitionHeader != 0 {
+			if data[pos+1] != fitArchitectureLittle {
+				t.Fatalf("arquitetura inesperada na posição %d", pos)
+			}
+			def := definition{global: binary.LittleEndian.Uint16(data[pos+2:])}
+			count := int(data[pos+4])
+			pos += 5
+			for i := 0; i < count; i++ {
+				def.fields = append(def.fields, fitField{data[pos], data[pos+1], data[pos+2]})
+				pos += 3
+			}
+			definitions[local] = def
+			continue
+		}
+
+		def, ok := definitions[local]
+		if !ok {
+			t.Fatalf("mensagem de dados do tipo local %d sem definição", local)
+		}
+		msg := fitMessage{global: def.global, fields: make(map[byte][]byte)}
+		for _, field := range def.fields {
+			msg.fields[field.number] = data[pos : pos+int(field.size)]
+			pos += int(field.size)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestFITCRC(t *testing.T) {
+	// O CRC do FIT é o CRC-16/ARC: valor de verificação 0xBB3D para "123456789"
+	if got := fitCRC(0, []byte("123456789")); got != 0xBB3D {
+		t.Errorf("fitCRC = %#04x, esperado 0xbb3d", got)
+	}
+}
+
+func TestWriteFIT(t *testing.T) {
+	clip := testClip()
+	var buf bytes.Buffer
+	if err := WriteFIT(&buf, clip); err != nil {
+		t.Fatalf("WriteFIT: %v", err)
+	}
+	data := buf.Bytes()
+
+	// Cabeçalho de 14 bytes com ".FIT" e CRC próprio
+	if data[0] != fitHeaderSize || data[1] != fitProtocolVersion || string(data[8:12]) != ".FIT" {
+		t.Fatalf("cabeçalho inválido: % x", data[:fitHeaderSize])
+	}
+	if got := binary.LittleEndian.Uint16(data[2:]); got != fitProfileVersion {
+		t.Errorf("versão do perfil = %d", got)
+	}
+	if got, want := binary.LittleEndian.Uint16(data[12:]), fitCRC(0, data[:12]); got != want {
+		t.Errorf("CRC do cabeçalho = %#04x, esperado %#04x", got, want)
+	}
+	// O CRC do arquivo inteiro, incluindo o CRC final, é zero
+	if crc := fitCRC(0, data); crc != 0 {
+		t.Errorf("CRC do arquivo inválido (resto %#04x)", crc)
+	}
+
+	messages := parseFIT(t, data)
+	counts := make(map[uint16]int)
+	var records []fitMessage
+	for _, msg := range messages {
+		counts[msg.global]++
+		if msg.global == fitMesgRecord {
+			records = append(records, msg)
+		}
+	}
+
+	for global, want := range map[uint16]int{fitMesgFileID: 1, fitMesgCourse: 1, fitMesgLap: 1, fitMesgEvent: 2, fitMesgRecord: len(clip.Points)} {
+		if counts[global] != want {
+			t.Errorf("mensagem %d: %d ocorrências, esperado %d", global, counts[global], want)
+		}
+	}
+	if messages[0].global != fitMesgFileID {
+		t.Errorf("a primeira mensagem deve ser file_id, é %d", messages[0].global)
+	}
+	if fileType := messages[0].fields[0][0]; fileType != fitFileCourse {
+		t.Errorf("tipo de arquivo = %d, esperado course (%d)", fileType, fitFileCourse)
+	}
+
+	for i, record := range records {
+		point := clip.Points[i]
+		if got := binary.LittleEndian.Uint32(record.fields[253]); got != fitTimestamp(point.Time) {
+			t.Fatalf("registro %d: timestamp %d, esperado %d", i, got, fitTimestamp(point.Time))
+		}
+		if got := int32(binary.LittleEndian.Uint32(record.fields[0])); got != fitSemicircles(point.Lat) {
+			t.Fatalf("registro %d: latitude %d, esperado %d", i, got, fitSemicircles(point.Lat))
+		}
+		if got := int32(binary.LittleEndian.Uint32(record.fields[1])); got != fitSemicircles(point.Lng) {
+			t.Fatalf("registro %d: longitude %d, esperado %d", i, got, fitSemicircles(point.Lng))
+		}
+		if got := binary.LittleEndian.Uint16(record.fields[6]); got != 10000 {
+			t.Fatalf("registro %d: velocidade %d mm/s, esperado 10000", i, got)
+		}
+		if got := binary.LittleEndian.Uint32(record.fields[5]); got != uint32(i*1000) {
+			t.Fatalf("registro %d: distância %d cm, esperado %d", i, got, i*1000)
+		}
+	}
+
+	// 2024-03-10T11:00:00Z na época do FIT
+	if got := fitTimestamp(clipStart); got != 1079002800 {
+		t.Errorf("fitTimestamp = %d, esperado 1079002800", got)
+	}
+}
+
+func TestFITString(t *testing.T) {
+	field := fitString("Subida à Serra da Cantareira pelo caminho longo", fitCourseNameSize)
+	if len(field) != fitCourseNameSize || field[fitCourseNameSize-1] != 0 {
+		t.Fatalf("campo deve ter %d bytes terminados em zero: %q", fitCourseNameSize, field)
+	}
+	if !bytes.HasPrefix(field, []byte("Subida à Serra")) {
+		t.Errorf("texto truncado incorretamente: %q", field)
+	}
+}
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+)
+
+type geoJSONCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONLineString      `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONLineString struct {
+	Type        string       `json:"type"`
+	Coordinates [][3]float64 `json:"coordinates"`
+}
+
+// WriteGeoJSON grava o clipe como uma LineString [lng, lat, altitude]. Os instantes de cada
+// coordenada seguem a convenção coordTimes, e videoTimes traz a posição no vídeo em segundos.
+func WriteGeoJSON(w io.Writer, clip Clip) error {
+	coordinates := make([][3]float64, len(clip.Points))
+	coordTimes := make([]string, len(clip.Points))
+	videoTimes := make([]float64, len(clip.Points))
+	speeds := make([]float64, len(clip.Points))
+
+	for i, point := range clip.Points {
+		coordinates[i] = [3]float64{round(point.Lng, 7), round(point.Lat, 7), round(point.Altitude, 1)}
+		coordTimes[i] = gpxTime(point.Time)
+		videoTimes[i] = round(clip.VideoOffset(point).Seconds(), 3)
+		speeds[i] = round(point.Velocity, 2)
+	}
+
+	collection := geoJSONCollection{
+		Type: "FeatureCollection",
+		Features: []geoJSONFeature{{
+			Type: "Feature",
+			Geometry: geoJSONLineString{
+				Type:        "LineString",
+				Coordinates: coordinates,
+			},
+			Properties: map[string]interface{}{
+				"name":        clip.Name,
+				"activity_id": clip.ActivityID,
+				"type":        clip.ActivityType,
+				"video_start": gpxTime(clip.VideoStart),
+				"coordTimes":  coordTimes,
+				"videoTimes":  videoTimes,
+				"speeds":      speeds,
+			},
+		}},
+	}
+
+	return json.NewEncoder(w).Encode(collection)
+}
+
+func round(value float64, precision int) float64 {
+	scale := math.Pow(10, float64(precision))
+	return math.Round(value*scale) / scale
+}
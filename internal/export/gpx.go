@@ -0,0 +1,105 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type gpxFile struct {
+	XMLName   xml.Name    `xml:"gpx"`
+	Version   string      `xml:"version,attr"`
+	Creator   string      `xml:"creator,attr"`
+	Namespace string      `xml:"xmlns,attr"`
+	TPX       string      `xml:"xmlns:gpxtpx,attr"`
+	Metadata  gpxMetadata `xml:"metadata"`
+	Track     gpxTrack    `xml:"trk"`
+}
+
+type gpxMetadata struct {
+	Name string `xml:"name"`
+	Desc string `xml:"desc"`
+	Time string `xml:"time"`
+}
+
+type gpxTrack struct {
+	Name    string     `xml:"name"`
+	Type    string     `xml:"type,omitempty"`
+	Segment gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat        float64       `xml:"lat,attr"`
+	Lon        float64       `xml:"lon,attr"`
+	Elevation  float64       `xml:"ele"`
+	Time       string        `xml:"time"`
+	Extensions gpxExtensions `xml:"extensions"`
+}
+
+type gpxExtensions struct {
+	TrackPoint gpxTrackPointExtension `xml:"gpxtpx:TrackPointExtension"`
+}
+
+// gpxTrackPointExtension usa a extensão TrackPointExtension v2 da Garmin para velocidade e rumo
+type gpxTrackPointExtension struct {
+	Speed  string `xml:"gpxtpx:speed"`
+	Course string `xml:"gpxtpx:course"`
+}
+
+// WriteGPX grava o clipe como GPX 1.1. Os horários são os instantes GPS sincronizados com o
+// vídeo, e a descrição registra o início do vídeo para reconstruir a linha do tempo.
+func WriteGPX(w io.Writer, clip Clip) error {
+	doc := gpxFile{
+		Version:   "1.1",
+		Creator:   "Strava Add Overlay",
+		Namespace: "http://www.topografix.com/GPX/1/1",
+		TPX:       "http://www.garmin.com/xmlschemas/TrackPointExtension/v2",
+		Metadata: gpxMetadata{
+			Name: clip.Name,
+			Desc: fmt.Sprintf("Strava activity %d, video start %s", clip.ActivityID, gpxTime(clip.VideoStart)),
+			Time: gpxTime(clip.VideoStart),
+		},
+		Track: gpxTrack{
+			Name: clip.Name,
+			Type: clip.ActivityType,
+		},
+	}
+
+	points := make([]gpxPoint, len(clip.Points))
+	for i, point := range clip.Points {
+		points[i] = gpxPoint{
+			Lat:       point.Lat,
+			Lon:       point.Lng,
+			Elevation: point.Altitude,
+			Time:      gpxTime(point.Time),
+			Extensions: gpxExtensions{
+				TrackPoint: gpxTrackPointExtension{
+					Speed:  fmt.Sprintf("%.2f", point.Velocity),
+					Course: fmt.Sprintf("%.1f", point.Bearing),
+				},
+			},
+		}
+	}
+	doc.Track.Segment.Points = points
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// gpxTime formata o instante em UTC com milissegundos, como exige o xsd:dateTime do GPX
+func gpxTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
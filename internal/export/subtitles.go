@@ -0,0 +1,268 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"strava-overlay/internal/gps"
+)
+
+// Formatos de legenda com a telemetria do clipe
+const (
+	FormatSRT    = "srt"
+	FormatWebVTT = "vtt"
+	FormatASS    = "ass"
+)
+
+// DefaultSubtitleTemplate é o texto de cada legenda quando nenhum modelo é configurado
+const DefaultSubtitleTemplate = "{{.Speed}} km/h · {{.Altitude}} m · {{.Distance}} km · {{.HeartRate}} bpm"
+
+// SubtitleFields são os valores disponíveis no modelo de legenda, já formatados
+type SubtitleFields struct {
+	Speed     string // km/h
+	Altitude  string // m
+	Distance  string // km desde o início da atividade
+	HeartRate string // bpm, "--" sem o stream de frequência cardíaca
+	Grade     string // %
+	GForce    string // G
+	Elapsed   string // Tempo decorrido da atividade
+	Clock     string // Horário local do ponto
+}
+
+// Subtitles gera legendas com a telemetria do clipe a partir de um modelo text/template
+type Subtitles struct {
+	template *template.Template
+	location *time.Location
+}
+
+// subtitleCue é uma legenda com início e fim na linha do tempo do vídeo
+type subtitleCue struct {
+	start, end time.Duration
+	text       string
+}
+
+// NewSubtitles compila o modelo das legendas. Modelo vazio usa DefaultSubtitleTemplate.
+func NewSubtitles(text string) (*Subtitles, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultSubtitleTemplate
+	}
+
+	tmpl, err := template.New("subtitle").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("modelo de legenda inválido: %w", err)
+	}
+
+	// Valida os campos usados no modelo antes de gerar qualquer arquivo
+	if err := tmpl.Execute(io.Discard, SubtitleFields{}); err != nil {
+		return nil, fmt.Errorf("modelo de legenda inválido: %w", err)
+	}
+
+	return &Subtitles{template: tmpl, location: time.Local}, nil
+}
+
+// SetLocation define o fuso usado no campo Clock (o fuso da atividade)
+func (s *Subtitles) SetLocation(location *time.Location) {
+	if location != nil {
+		s.location = location
+	}
+}
+
+// ParseSubtitleFormats interpreta uma lista separada por vírgulas ("srt,ass"), ignorando
+// formatos desconhecidos ou repetidos. Vazio ou "none" desativa as legendas.
+func ParseSubtitleFormats(list string) []string {
+	var formats []string
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "webvtt" {
+			name = FormatWebVTT
+		}
+		if name == "" || seen[name] {
+			continue
+		}
+		if name == "none" {
+			return nil
+		}
+		if name != FormatSRT && name != FormatWebVTT && name != FormatASS {
+			log.Printf("⚠️ Formato de legenda desconhecido ignorado: %s", name)
+			continue
+		}
+		seen[name] = true
+		formats = append(formats, name)
+	}
+
+	return formats
+}
+
+// WriteFiles grava as legendas em cada formato ao lado de basePath, trocando a extensão.
+// Retorna os arquivos gerados.
+func (s *Subtitles) WriteFiles(basePath string, clip Clip, formats []string) ([]string, error) {
+	return writeFormats(basePath, clip, formats, map[string]ClipWriter{
+		FormatSRT:    s.WriteSRT,
+		FormatWebVTT: s.WriteWebVTT,
+		FormatASS:    s.WriteASS,
+	}, "legenda")
+}
+
+// WriteSRT grava as legendas no formato SubRip
+func (s *Subtitles) WriteSRT(w io.Writer, clip Clip) error {
+	cues, err := s.cues(clip)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for i, cue := range cues {
+		fmt.Fprintf(&buf, "%d\n%s --> %s\n%s\n\n", i+1,
+			formatCueTime(cue.start, ","), formatCueTime(cue.end, ","), cue.text)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// WriteWebVTT grava as legendas no formato WebVTT
+func (s *Subtitles) WriteWebVTT(w io.Writer, clip Clip) error {
+	cues, err := s.cues(clip)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		// "-->" não pode aparecer no texto de uma legenda WebVTT
+		text := strings.ReplaceAll(cue.text, "-->", "->")
+		fmt.Fprintf(&buf, "%s --> %s\n%s\n\n",
+			formatCueTime(cue.start, "."), formatCueTime(cue.end, "."), text)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// WriteASS grava as legendas no formato Advanced SubStation Alpha, com texto branco contornado
+// na parte inferior central do vídeo
+func (s *Subtitles) WriteASS(w io.Writer, clip Clip) error {
+	cues, err := s.cues(clip)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("[Script Info]\n")
+	fmt.Fprintf(&buf, "Title: %s\n", assText(clip.Name))
+	buf.WriteString("ScriptType: v4.00+\nPlayResX: 1920\nPlayResY: 1080\nWrapStyle: 2\nScaledBorderAndShadow: yes\n\n")
+
+	buf.WriteString("[V4+ Styles]\n")
+	buf.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, " +
+		"Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, " +
+		"Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	buf.WriteString("Style: Telemetry,Arial,44,&H00FFFFFF,&H000000FF,&H00000000,&H80000000," +
+		"-1,0,0,0,100,100,0,0,1,3,1,2,40,40,40,1\n\n")
+
+	buf.WriteString("[Events]\n")
+	buf.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&buf, "Dialogue: 0,%s,%s,Telemetry,,0,0,0,,%s\n",
+			formatASSTime(cue.start), formatASSTime(cue.end), assText(cue.text))
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// cues gera uma legenda por ponto, do instante do ponto até o próximo, juntando pontos
+// consecutivos com o mesmo texto. Pontos antes do início do vídeo são descartados.
+func (s *Subtitles) cues(clip Clip) ([]subtitleCue, error) {
+	var cues []subtitleCue
+
+	for i, point := range clip.Points {
+		start := clip.VideoOffset(point)
+		end := start + time.Second
+		if i+1 < len(clip.Points) {
+			end = clip.VideoOffset(clip.Points[i+1])
+		}
+		if end <= 0 {
+			continue
+		}
+		if start < 0 {
+			start = 0
+		}
+
+		text, err := s.render(point)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(cues); n > 0 && cues[n-1].text == text && cues[n-1].end == start {
+			cues[n-1].end = end
+			continue
+		}
+		cues = append(cues, subtitleCue{start: start, end: end, text: text})
+	}
+
+	return cues, nil
+}
+
+// render aplica o modelo aos valores do ponto
+func (s *Subtitles) render(point gps.GPSPoint) (string, error) {
+	heartRate := "--"
+	if point.HeartRate > 0 {
+		heartRate = fmt.Sprintf("%.0f", point.HeartRate)
+	}
+
+	fields := SubtitleFields{
+		Speed:     fmt.Sprintf("%.1f", point.Velocity*3.6),
+		Altitude:  fmt.Sprintf("%.0f", point.Altitude),
+		Distance:  fmt.Sprintf("%.2f", point.Distance/1000),
+		HeartRate: heartRate,
+		Grade:     fmt.Sprintf("%.1f", point.Grade),
+		GForce:    fmt.Sprintf("%.2f", point.GForce),
+		Elapsed:   formatCueClock(point.ElapsedTime),
+		Clock:     point.Time.In(s.location).Format("15:04:05"),
+	}
+
+	var buf bytes.Buffer
+	if err := s.template.Execute(&buf, fields); err != nil {
+		return "", fmt.Errorf("erro ao aplicar modelo de legenda: %w", err)
+	}
+
+	// Linhas em branco encerram a legenda em SRT e WebVTT
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n"), nil
+}
+
+// formatCueTime formata HH:MM:SS<sep>mmm, usado por SRT (",") e WebVTT (".")
+func formatCueTime(d time.Duration, sep string) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// formatASSTime formata H:MM:SS.cc, com centésimos de segundo
+func formatASSTime(d time.Duration) string {
+	cs := d.Milliseconds() / 10
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
+
+// formatCueClock formata a duração como H:MM:SS
+func formatCueClock(d time.Duration) string {
+	seconds := int(d.Seconds())
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// assText escapa chaves (blocos de override do ASS) e converte quebras de linha em \N
+func assText(text string) string {
+	replacer := strings.NewReplacer("{", "(", "}", ")", "\r", "", "\n", `\N`)
+	return replacer.Replace(text)
+}
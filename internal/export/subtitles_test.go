@@ -0,0 +1,302 @@
+package export
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// quietLogs silencia os avisos de formato durante o teste
+func quietLogs(tb testing.TB) {
+	previous := log.Writer()
+	log.SetOutput(io.Discard)
+	tb.Cleanup(func() { log.SetOutput(previous) })
+}
+
+// mustSubtitles compila o modelo ou encerra o teste
+func mustSubtitles(t *testing.T, text string) *Subtitles {
+	t.Helper()
+	subtitles, err := NewSubtitles(text)
+	if err != nil {
+		t.Fatalf("NewSubtitles(%q): %v", text, err)
+	}
+	return subtitles
+}
+
+func TestFormatCueTime(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		sep  string
+		want string
+	}{
+		{0, ",", "00:00:00,000"},
+		{1500 * time.Millisecond, ",", "00:00:01,500"},
+		{time.Hour + 2*time.Minute + 3456*time.Millisecond, ",", "01:02:03,456"},
+		{time.Hour + 2*time.Minute + 3456*time.Millisecond, ".", "01:02:03.456"},
+		{59*time.Second + 999*time.Millisecond + 900*time.Microsecond, ".", "00:00:59.999"},
+	}
+
+	for _, tt := range tests {
+		if got := formatCueTime(tt.d, tt.sep); got != tt.want {
+			t.Errorf("formatCueTime(%v, %q) = %q, esperado %q", tt.d, tt.sep, got, tt.want)
+		}
+	}
+}
+
+func TestFormatASSTime(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0:00:00.00"},
+		{1500 * time.Millisecond, "0:00:01.50"},
+		{59*time.Second + 999*time.Millisecond, "0:00:59.99"},
+		{time.Hour + 2*time.Minute + 3456*time.Millisecond, "1:02:03.45"},
+		{12 * time.Hour, "12:00:00.00"},
+	}
+
+	for _, tt := range tests {
+		if got := formatASSTime(tt.d); got != tt.want {
+			t.Errorf("formatASSTime(%v) = %q, esperado %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestAssText(t *testing.T) {
+	tests := map[string]string{
+		"36.0 km/h":            "36.0 km/h",
+		`{\b1}negrito{\b0}`:    `(\b1)negrito(\b0)`,
+		"linha 1\nlinha 2":     `linha 1\Nlinha 2`,
+		"linha 1\r\nlinha 2":   `linha 1\Nlinha 2`,
+		"Pedal {de} domingo\n": `Pedal (de) domingo\N`,
+	}
+
+	for text, want := range tests {
+		if got := assText(text); got != want {
+			t.Errorf("assText(%q) = %q, esperado %q", text, got, want)
+		}
+	}
+}
+
+func TestNewSubtitlesRejectsBadTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{"vazio usa o modelo padrão", "  ", false},
+		{"todos os campos", "{{.Speed}} {{.Altitude}} {{.Distance}} {{.HeartRate}} {{.Grade}} {{.GForce}} {{.Elapsed}} {{.Clock}}", false},
+		{"sintaxe inválida", "{{.Speed} km/h", true},
+		{"campo inexistente", "{{.Velocidade}} km/h", true},
+		{"função inexistente", "{{upper .Speed}}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSubtitles(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSubtitles(%q) erro = %v, esperava erro: %v", tt.text, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubtitleCues(t *testing.T) {
+	subtitles := mustSubtitles(t, "{{.Speed}} km/h")
+
+	t.Run("texto igual vira uma única legenda", func(t *testing.T) {
+		cues, err := subtitles.cues(testClip())
+		if err != nil {
+			t.Fatalf("cues: %v", err)
+		}
+		// 60 pontos de 1 s a partir de 2 s no vídeo; o último dura 1 s
+		want := []subtitleCue{{start: 2 * time.Second, end: 62 * time.Second, text: "36.0 km/h"}}
+		if !reflect.DeepEqual(cues, want) {
+			t.Errorf("cues = %+v, esperado %+v", cues, want)
+		}
+	})
+
+	t.Run("mudança de texto abre nova legenda", func(t *testing.T) {
+		clip := testClip()
+		for i := 30; i < len(clip.Points); i++ {
+			clip.Points[i].Velocity = 5
+		}
+		cues, err := subtitles.cues(clip)
+		if err != nil {
+			t.Fatalf("cues: %v", err)
+		}
+		want := []subtitleCue{
+			{start: 2 * time.Second, end: 32 * time.Second, text: "36.0 km/h"},
+			{start: 32 * time.Second, end: 62 * time.Second, text: "18.0 km/h"},
+		}
+		if !reflect.DeepEqual(cues, want) {
+			t.Errorf("cues = %+v, esperado %+v", cues, want)
+		}
+	})
+
+	t.Run("pontos antes do vídeo são descartados", func(t *testing.T) {
+		clip := testClip()
+		clip.VideoStart = clipStart.Add(5500 * time.Millisecond)
+		for i := range clip.Points {
+			clip.Points[i].Velocity = float64(i)
+		}
+		cues, err := subtitles.cues(clip)
+		if err != nil {
+			t.Fatalf("cues: %v", err)
+		}
+		// O ponto 5 começa 0,5 s antes do vídeo e é cortado em 0
+		if len(cues) != 55 {
+			t.Fatalf("esperava 55 legendas, obteve %d", len(cues))
+		}
+		if cues[0].start != 0 || cues[0].end != 500*time.Millisecond || cues[0].text != "18.0 km/h" {
+			t.Errorf("primeira legenda = %+v", cues[0])
+		}
+	})
+}
+
+func TestSubtitleRender(t *testing.T) {
+	point := testClip().Points[0]
+	point.ElapsedTime = time.Hour + 5*time.Minute + 9*time.Second
+	point.HeartRate = 151.6
+
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skipf("base de fusos indisponível: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"modelo padrão", "", "36.0 km/h · 760 m · 5.00 km · 152 bpm"},
+		{"horário no fuso da atividade", "{{.Clock}} {{.Elapsed}}", "08:00:00 1:05:09"},
+		{"linhas em branco removidas", "{{.Speed}}\n\n  \n{{.Altitude}}\n", "36.0\n760"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subtitles := mustSubtitles(t, tt.template)
+			subtitles.SetLocation(saoPaulo)
+			got, err := subtitles.render(point)
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("render = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+
+	point.HeartRate = 0
+	got, _ := mustSubtitles(t, "{{.HeartRate}}").render(point)
+	if got != "--" {
+		t.Errorf("sem frequência cardíaca esperava \"--\", obtido %q", got)
+	}
+}
+
+func TestWriteSRTAndWebVTT(t *testing.T) {
+	clip := testClip()
+	clip.Points = clip.Points[:2]
+	clip.Points[1].Velocity = 5
+	subtitles := mustSubtitles(t, "{{.Speed}} --> {{.Altitude}}")
+
+	var srt bytes.Buffer
+	if err := subtitles.WriteSRT(&srt, clip); err != nil {
+		t.Fatalf("WriteSRT: %v", err)
+	}
+	wantSRT := "1\n00:00:02,000 --> 00:00:03,000\n36.0 --> 760\n\n" +
+		"2\n00:00:03,000 --> 00:00:04,000\n18.0 --> 760\n\n"
+	if srt.String() != wantSRT {
+		t.Errorf("SRT:\n%s\nesperado:\n%s", srt.String(), wantSRT)
+	}
+
+	var vtt bytes.Buffer
+	if err := subtitles.WriteWebVTT(&vtt, clip); err != nil {
+		t.Fatalf("WriteWebVTT: %v", err)
+	}
+	// "-->" no texto encerraria a linha de tempo do WebVTT
+	wantVTT := "WEBVTT\n\n" +
+		"00:00:02.000 --> 00:00:03.000\n36.0 -> 760\n\n" +
+		"00:00:03.000 --> 00:00:04.000\n18.0 -> 760\n\n"
+	if vtt.String() != wantVTT {
+		t.Errorf("WebVTT:\n%s\nesperado:\n%s", vtt.String(), wantVTT)
+	}
+}
+
+func TestWriteASS(t *testing.T) {
+	clip := testClip()
+	clip.Name = "Pedal {noturno}"
+	clip.Points = clip.Points[:1]
+	subtitles := mustSubtitles(t, "{{.Speed}} km/h\n{{.Altitude}} m")
+
+	var buf bytes.Buffer
+	if err := subtitles.WriteASS(&buf, clip); err != nil {
+		t.Fatalf("WriteASS: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"[Script Info]\nTitle: Pedal (noturno)\n",
+		"[V4+ Styles]\n",
+		"Style: Telemetry,",
+		"[Events]\n",
+		`Dialogue: 0,0:00:02.00,0:00:03.00,Telemetry,,0,0,0,,36.0 km/h\N760 m` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("ASS sem %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSubtitlesWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	subtitles := mustSubtitles(t, "")
+
+	paths, err := subtitles.WriteFiles(filepath.Join(dir, "pedal.mp4"), testClip(), []string{FormatSRT, FormatWebVTT, FormatASS})
+	if err != nil {
+		t.Fatalf("WriteFiles: %v", err)
+	}
+
+	want := []string{filepath.Join(dir, "pedal.srt"), filepath.Join(dir, "pedal.vtt"), filepath.Join(dir, "pedal.ass")}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("arquivos = %v, esperado %v", paths, want)
+	}
+	for _, path := range paths {
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("legenda %s ausente ou vazia: %v", path, err)
+		}
+	}
+
+	if _, err := subtitles.WriteFiles(filepath.Join(dir, "pedal.mp4"), testClip(), []string{"sub"}); err == nil {
+		t.Error("formato desconhecido deveria retornar erro")
+	}
+	if _, err := subtitles.WriteFiles(filepath.Join(dir, "vazio.mp4"), Clip{}, []string{FormatSRT}); err == nil {
+		t.Error("clipe sem pontos deveria retornar erro")
+	}
+}
+
+func TestParseSubtitleFormats(t *testing.T) {
+	quietLogs(t)
+
+	tests := map[string][]string{
+		"":                 nil,
+		"none":             nil,
+		"srt":              {FormatSRT},
+		"SRT, webvtt, ass": {FormatSRT, FormatWebVTT, FormatASS},
+		"srt,srt,vtt":      {FormatSRT, FormatWebVTT},
+		"srt,sub":          {FormatSRT},
+	}
+
+	for list, want := range tests {
+		if got := ParseSubtitleFormats(list); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseSubtitleFormats(%q) = %v, esperado %v", list, got, want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"strava-overlay/internal/gps"
+)
+
+type telemetryFile struct {
+	ActivityID int64             `json:"activity_id"`
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	VideoStart string            `json:"video_start"`
+	Samples    []telemetrySample `json:"samples"`
+}
+
+type telemetrySample struct {
+	VideoTime float64 `json:"t"`
+	Time      string  `json:"time"`
+	Lat       float64 `json:"lat"`
+	Lng       float64 `json:"lng"`
+	Altitude  float64 `json:"alt"`
+	Speed     float64 `json:"speed"`
+	Bearing   float64 `json:"bearing"`
+	Distance  float64 `json:"distance"`
+	Grade     float64 `json:"grade"`
+	GForce    float64 `json:"gforce"`
+	HeartRate float64 `json:"heart_rate,omitempty"`
+}
+
+// WriteTelemetryJSON grava as amostras do clipe em JSON, com "t" em segundos desde o início do
+// vídeo. É o formato anexado ao MKV quando a telemetria é embutida no arquivo de saída.
+func WriteTelemetryJSON(w io.Writer, clip Clip) error {
+	file := telemetryFile{
+		ActivityID: clip.ActivityID,
+		Name:       clip.Name,
+		Type:       clip.ActivityType,
+		VideoStart: gpxTime(clip.VideoStart),
+		Samples:    make([]telemetrySample, 0, len(clip.Points)),
+	}
+
+	for _, point := range clip.Points {
+		file.Samples = append(file.Samples, telemetrySample{
+			VideoTime: round(clip.VideoOffset(point).Seconds(), 3),
+			Time:      gpxTime(point.Time),
+			Lat:       round(point.Lat, 7),
+			Lng:       round(point.Lng, 7),
+			Altitude:  round(point.Altitude, 1),
+			Speed:     round(point.Velocity, 2),
+			Bearing:   round(point.Bearing, 1),
+			Distance:  round(point.Distance, 1),
+			Grade:     round(point.Grade, 1),
+			GForce:    round(point.GForce, 3),
+			HeartRate: round(point.HeartRate, 0),
+		})
+	}
+
+	return json.NewEncoder(w).Encode(file)
+}
+
+// ISO6709 retorna a posição no formato ISO 6709 usado pelos metadados de localização de MP4 e
+// MOV ("+DD.DDDDDD-DDD.DDDDDD+AAA.AAA/")
+func ISO6709(point gps.GPSPoint) string {
+	return fmt.Sprintf("%+010.6f%+011.6f%+.3f/", point.Lat, point.Lng, point.Altitude)
+}
+
+// GPMFSample é um trecho de telemetria GPMF (GoPro Metadata Format) com sua duração na linha
+// do tempo do vídeo
+type GPMFSample struct {
+	Duration time.Duration
+	Data     []byte
+}
+
+// Escalas do GPS5: latitude e longitude em 1e-7 graus, altitude e velocidade 2D em mm e mm/s,
+// velocidade 3D em cm/s
+var gps5Scales = []int32{10000000, 10000000, 1000, 1000, 100}
+
+// GPMFSamples divide o clipe em amostras de 1 segundo no formato GPMF, com o stream GPS5 lido
+// por ferramentas que entendem a telemetria da GoPro. Cada amostra contém os pontos cuja posição
+// no vídeo cai naquele segundo; segundos sem pontos repetem o último ponto conhecido.
+func GPMFSamples(clip Clip, duration time.Duration) []GPMFSample {
+	var samples []GPMFSample
+	if len(clip.Points) == 0 || duration <= 0 {
+		return samples
+	}
+
+	next := 0
+	last := clip.Points[0]
+	for start := time.Duration(0); start < duration; start += time.Second {
+		end := min(start+time.Second, duration)
+
+		var points []gps.GPSPoint
+		for next < len(clip.Points) && clip.VideoOffset(clip.Points[next]) < end {
+			if clip.VideoOffset(clip.Points[next]) >= start {
+				points = append(points, clip.Points[next])
+			}
+			last = clip.Points[next]
+			next++
+		}
+		if len(points) == 0 {
+			points = []gps.GPSPoint{last}
+		}
+
+		samples = append(samples, GPMFSample{
+			Duration: end - start,
+			Data:     gpmfDevice(points),
+		})
+	}
+
+	return samples
+}
+
+// gpmfDevice monta o DEVC com um stream GPS5 para os pontos
+func gpmfDevice(points []gps.GPSPoint) []byte {
+	var stream bytes.Buffer
+	writeGPMF(&stream, "STNM", 'c', 1, []byte("GPS (Lat., Long., Alt., 2D speed, 3D speed)"))
+	writeGPMF(&stream, "GPSF", 'L', 4, uint32(3)) // Fix 3D
+	writeGPMF(&stream, "GPSU", 'U', 16, []byte(points[0].Time.UTC().Format("060102150405.000")))
+	writeGPMF(&stream, "UNIT", 'c', 3, []byte("degdegm\x00\x00m/sm/s"))
+	writeGPMF(&stream, "SCAL", 'l', 4, gps5Scales)
+
+	rows := make([]int32, 0, len(points)*5)
+	for _, point := range points {
+		rows = append(rows,
+			int32(math.Round(point.Lat*float64(gps5Scales[0]))),
+			int32(math.Round(point.Lng*float64(gps5Scales[1]))),
+			int32(math.Round(point.Altitude*float64(gps5Scales[2]))),
+			int32(math.Round(point.Velocity*float64(gps5Scales[3]))),
+			int32(math.Round(point.Velocity*float64(gps5Scales[4]))),
+		)
+	}
+	writeGPMF(&stream, "GPS5", 'l', 20, rows)
+
+	var device bytes.Buffer
+	writeGPMF(&device, "DVID", 'L', 4, uint32(1))
+	writeGPMF(&device, "DVNM", 'c', 1, []byte("Strava Add Overlay"))
+	writeGPMFNested(&device, "STRM", stream.Bytes())
+
+	var out bytes.Buffer
+	writeGPMFNested(&out, "DEVC", device.Bytes())
+	return out.Bytes()
+}
+
+// writeGPMF grava um KLV: chave, tipo, tamanho da estrutura e repetições (big-endian), com os
+// dados completados até múltiplo de 4 bytes
+func writeGPMF(buf *bytes.Buffer, key string, valueType byte, structSize int, value interface{}) {
+	var data bytes.Buffer
+	binary.Write(&data, binary.BigEndian, value)
+
+	buf.WriteString(key)
+	buf.WriteByte(valueType)
+	buf.WriteByte(byte(structSize))
+	binary.Write(buf, binary.BigEndian, uint16(data.Len()/structSize))
+	buf.Write(data.Bytes())
+	buf.Write(make([]byte, gpmfPadding(data.Len())))
+}
+
+// writeGPMFNested grava um KLV que contém outros KLVs (tipo nulo)
+func writeGPMFNested(buf *bytes.Buffer, key string, children []byte) {
+	buf.WriteString(key)
+	buf.WriteByte(0)
+	buf.WriteByte(1)
+	binary.Write(buf, binary.BigEndian, uint16(len(children)))
+	buf.Write(children)
+	buf.Write(make([]byte, gpmfPadding(len(children))))
+}
+
+func gpmfPadding(length int) int {
+	return (4 - length%4) % 4
+}
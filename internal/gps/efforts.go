@@ -0,0 +1,79 @@
+package gps
+
+import (
+	"sort"
+	"time"
+)
+
+// SegmentEffort é a passagem por um segmento do Strava posicionada na linha do tempo da trilha.
+// Ranks iguais a 0 indicam que o esforço não entrou no top 10 (KOM) ou top 3 (PR).
+type SegmentEffort struct {
+	Name        string
+	Start       time.Time
+	ElapsedTime time.Duration
+	Distance    float64
+	KOMRank     int
+	PRRank      int
+
+	startDistance float64 // Distância acumulada da trilha no início do esforço
+}
+
+// End retorna o instante em que o esforço terminou
+func (e SegmentEffort) End() time.Time {
+	return e.Start.Add(e.ElapsedTime)
+}
+
+// EffortStatus descreve o esforço em andamento num ponto da trilha
+type EffortStatus struct {
+	Effort    SegmentEffort
+	Elapsed   time.Duration
+	Remaining float64 // Metros até o fim do segmento
+}
+
+// EffortTimeline localiza o esforço em andamento a partir do tempo de cada ponto
+type EffortTimeline struct {
+	efforts []SegmentEffort
+}
+
+// NewEffortTimeline ordena os esforços pelo início e usa a trilha para saber a distância
+// acumulada em que cada um começou
+func NewEffortTimeline(efforts []SegmentEffort, track *Interpolator) *EffortTimeline {
+	et := &EffortTimeline{efforts: append([]SegmentEffort(nil), efforts...)}
+
+	sort.SliceStable(et.efforts, func(i, j int) bool {
+		return et.efforts[i].Start.Before(et.efforts[j].Start)
+	})
+
+	if track != nil {
+		for i := range et.efforts {
+			start, _ := track.At(et.efforts[i].Start)
+			et.efforts[i].startDistance = start.Distance
+		}
+	}
+
+	return et
+}
+
+// At retorna o esforço em andamento no ponto. Com segmentos sobrepostos prevalece o que
+// começou por último, normalmente o trecho mais curto e específico.
+func (et *EffortTimeline) At(point GPSPoint) (EffortStatus, bool) {
+	for i := len(et.efforts) - 1; i >= 0; i-- {
+		effort := et.efforts[i]
+		if point.Time.Before(effort.Start) || !point.Time.Before(effort.End()) {
+			continue
+		}
+
+		covered := point.Distance - effort.startDistance
+		remaining := effort.Distance - covered
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		return EffortStatus{
+			Effort:    effort,
+			Elapsed:   point.Time.Sub(effort.Start),
+			Remaining: remaining,
+		}, true
+	}
+	return EffortStatus{}, false
+}
@@ -0,0 +1,425 @@
+package gps
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Filter transforma a trilha bruta antes do cálculo de bearing e G-force
+type Filter interface {
+	Name() string
+	Apply(points []GPSPoint) []GPSPoint
+}
+
+// FilterChain aplica os filtros em sequência
+type FilterChain []Filter
+
+// Apply executa cada filtro da cadeia sobre o resultado do anterior
+func (fc FilterChain) Apply(points []GPSPoint) []GPSPoint {
+	for _, filter := range fc {
+		before := len(points)
+		points = filter.Apply(points)
+		if len(points) != before {
+			log.Printf("DEBUG: Filtro %s: %d -> %d pontos", filter.Name(), before, len(points))
+		}
+	}
+	return points
+}
+
+// FilterConfig define quais filtros compõem a cadeia padrão e seus parâmetros
+type FilterConfig struct {
+	// Rejeição de outliers por velocidade implausível (m/s); 0 desativa
+	MaxSpeed float64
+
+	// Suavização de posição por Kalman; ruído da medida em metros e da aceleração em m/s²
+	KalmanEnabled          bool
+	KalmanMeasurementNoise float64
+	KalmanProcessNoise     float64
+
+	// Janelas (ímpares) do Savitzky–Golay; 0 desativa
+	VelocityWindow int
+	AltitudeWindow int
+}
+
+// DefaultFilterConfig retorna parâmetros adequados a ciclismo e corrida com GPS de 1 Hz
+func DefaultFilterConfig() FilterConfig {
+	return FilterConfig{
+		MaxSpeed:               40.0, // 144 km/h
+		KalmanEnabled:          true,
+		KalmanMeasurementNoise: 5.0,
+		KalmanProcessNoise:     1.5,
+		VelocityWindow:         5,
+		AltitudeWindow:         11,
+	}
+}
+
+// ParseFilterConfig interpreta a velocidade máxima em m/s ("40", "0" desativa a rejeição de
+// outliers) e o liga/desliga do Kalman ("true"/"false"); valores vazios mantêm o padrão
+func ParseFilterConfig(maxSpeed, kalman string) (FilterConfig, error) {
+	cfg := DefaultFilterConfig()
+
+	if maxSpeed = strings.TrimSpace(maxSpeed); maxSpeed != "" {
+		v, err := strconv.ParseFloat(maxSpeed, 64)
+		if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+			return DefaultFilterConfig(), fmt.Errorf("velocidade máxima do filtro inválida '%s'", maxSpeed)
+		}
+		cfg.MaxSpeed = v
+	}
+
+	if kalman = strings.TrimSpace(kalman); kalman != "" {
+		enabled, err := strconv.ParseBool(kalman)
+		if err != nil {
+			return DefaultFilterConfig(), fmt.Errorf("valor inválido para o filtro de Kalman '%s'", kalman)
+		}
+		cfg.KalmanEnabled = enabled
+	}
+
+	return cfg, nil
+}
+
+// NewFilterChain monta a cadeia na ordem correta: primeiro rejeita outliers, depois suaviza
+func NewFilterChain(cfg FilterConfig) FilterChain {
+	var chain FilterChain
+
+	if cfg.MaxSpeed > 0 {
+		chain = append(chain, &SpeedOutlierFilter{MaxSpeed: cfg.MaxSpeed, MaxConsecutiveRejects: 5})
+	}
+	if cfg.KalmanEnabled {
+		chain = append(chain, &KalmanPositionFilter{
+			MeasurementNoise: cfg.KalmanMeasurementNoise,
+			ProcessNoise:     cfg.KalmanProcessNoise,
+		})
+	}
+	if cfg.VelocityWindow > 1 {
+		chain = append(chain, &SavitzkyGolayFilter{Field: FieldVelocity, Window: cfg.VelocityWindow, Order: 2})
+	}
+	if cfg.AltitudeWindow > 1 {
+		chain = append(chain, &SavitzkyGolayFilter{Field: FieldAltitude, Window: cfg.AltitudeWindow, Order: 2})
+	}
+
+	return chain
+}
+
+// SpeedOutlierFilter descarta pontos que exigiriam uma velocidade implausível a partir do
+// último ponto aceito, eliminando os "teletransportes" causados por picos do GPS
+type SpeedOutlierFilter struct {
+	MaxSpeed float64 // m/s
+	// Após tantas rejeições seguidas, o ponto aceito anterior é que era o outlier e a trilha
+	// é reancorada no ponto atual
+	MaxConsecutiveRejects int
+}
+
+func (f *SpeedOutlierFilter) Name() string {
+	return "speed-outlier"
+}
+
+func (f *SpeedOutlierFilter) Apply(points []GPSPoint) []GPSPoint {
+	if len(points) < 2 {
+		return points
+	}
+
+	filtered := make([]GPSPoint, 0, len(points))
+	filtered = append(filtered, points[0])
+	rejected := 0
+
+	for _, point := range points[1:] {
+		last := filtered[len(filtered)-1]
+		dt := point.Time.Sub(last.Time).Seconds()
+		if dt <= 0 {
+			// Timestamps repetidos não trazem informação nova
+			continue
+		}
+
+		speed := DistanceMeters(last, point) / dt
+		if speed > f.MaxSpeed && rejected < f.MaxConsecutiveRejects {
+			rejected++
+			continue
+		}
+
+		if rejected >= f.MaxConsecutiveRejects && speed > f.MaxSpeed {
+			filtered[len(filtered)-1] = point
+		} else {
+			filtered = append(filtered, point)
+		}
+		rejected = 0
+	}
+
+	return filtered
+}
+
+// KalmanPositionFilter suaviza a posição com um filtro de Kalman de velocidade constante
+// seguido do suavizador RTS, que elimina o atraso do filtro direto
+type KalmanPositionFilter struct {
+	MeasurementNoise float64 // desvio padrão do GPS em metros
+	ProcessNoise     float64 // desvio padrão da aceleração em m/s²
+}
+
+func (f *KalmanPositionFilter) Name() string {
+	return "kalman-position"
+}
+
+func (f *KalmanPositionFilter) Apply(points []GPSPoint) []GPSPoint {
+	if len(points) < 3 {
+		return points
+	}
+
+	// Projeção equiretangular local: suficiente para as distâncias de uma atividade
+	origin := points[0]
+	metersPerDegLat := 111320.0
+	metersPerDegLng := 111320.0 * math.Cos(origin.Lat*math.Pi/180)
+
+	xs := make([]float64, len(points))
+	ys := make([]float64, len(points))
+	dts := make([]float64, len(points))
+	for i, p := range points {
+		xs[i] = (p.Lng - origin.Lng) * metersPerDegLng
+		ys[i] = (p.Lat - origin.Lat) * metersPerDegLat
+		if i > 0 {
+			dts[i] = p.Time.Sub(points[i-1].Time).Seconds()
+		}
+	}
+
+	smoothX := f.smoothAxis(xs, dts)
+	smoothY := f.smoothAxis(ys, dts)
+
+	result := make([]GPSPoint, len(points))
+	for i, p := range points {
+		p.Lng = origin.Lng + smoothX[i]/metersPerDegLng
+		p.Lat = origin.Lat + smoothY[i]/metersPerDegLat
+		result[i] = p
+	}
+	return result
+}
+
+// kalmanState é o estado [posição, velocidade] de um eixo e sua covariância 2x2
+type kalmanState struct {
+	x, v          float64
+	p00, p01, p11 float64
+}
+
+// smoothAxis aplica Kalman direto + RTS em um eixo
+func (f *KalmanPositionFilter) smoothAxis(measurements, dts []float64) []float64 {
+	n := len(measurements)
+	r := f.MeasurementNoise * f.MeasurementNoise
+	q := f.ProcessNoise * f.ProcessNoise
+
+	predicted := make([]kalmanState, n)
+	filtered := make([]kalmanState, n)
+
+	filtered[0] = kalmanState{x: measurements[0], p00: r, p11: 100}
+	predicted[0] = filtered[0]
+
+	for i := 1; i < n; i++ {
+		prev := filtered[i-1]
+		dt := dts[i]
+
+		// Predição: x' = F x, P' = F P Fᵀ + Q
+		pred := kalmanState{
+			x:   prev.x + dt*prev.v,
+			v:   prev.v,
+			p00: prev.p00 + 2*dt*prev.p01 + dt*dt*prev.p11 + q*dt*dt*dt*dt/4,
+			p01: prev.p01 + dt*prev.p11 + q*dt*dt*dt/2,
+			p11: prev.p11 + q*dt*dt,
+		}
+		predicted[i] = pred
+
+		// Atualização com a medida da posição
+		s := pred.p00 + r
+		k0 := pred.p00 / s
+		k1 := pred.p01 / s
+		innovation := measurements[i] - pred.x
+
+		filtered[i] = kalmanState{
+			x:   pred.x + k0*innovation,
+			v:   pred.v + k1*innovation,
+			p00: (1 - k0) * pred.p00,
+			p01: (1 - k0) * pred.p01,
+			p11: pred.p11 - k1*pred.p01,
+		}
+	}
+
+	// Suavizador Rauch–Tung–Striebel (passada de trás para frente)
+	smoothed := make([]kalmanState, n)
+	smoothed[n-1] = filtered[n-1]
+
+	for i := n - 2; i >= 0; i-- {
+		cur := filtered[i]
+		next := predicted[i+1]
+		dt := dts[i+1]
+
+		// C = P F ᵀ P'⁻¹
+		pf00 := cur.p00 + dt*cur.p01
+		pf01 := cur.p01
+		pf10 := cur.p01 + dt*cur.p11
+		pf11 := cur.p11
+
+		det := next.p00*next.p11 - next.p01*next.p01
+		if math.Abs(det) < 1e-12 {
+			smoothed[i] = cur
+			continue
+		}
+		inv00 := next.p11 / det
+		inv01 := -next.p01 / det
+		inv11 := next.p00 / det
+
+		c00 := pf00*inv00 + pf01*inv01
+		c01 := pf00*inv01 + pf01*inv11
+		c10 := pf10*inv00 + pf11*inv01
+		c11 := pf10*inv01 + pf11*inv11
+
+		dx := smoothed[i+1].x - next.x
+		dv := smoothed[i+1].v - next.v
+
+		smoothed[i] = kalmanState{
+			x:   cur.x + c00*dx + c01*dv,
+			v:   cur.v + c10*dx + c11*dv,
+			p00: cur.p00,
+			p01: cur.p01,
+			p11: cur.p11,
+		}
+	}
+
+	result := make([]float64, n)
+	for i, state := range smoothed {
+		result[i] = state.x
+	}
+	return result
+}
+
+// PointField identifica o campo escalar suavizado pelo Savitzky–Golay
+type PointField int
+
+const (
+	FieldVelocity PointField = iota
+	FieldAltitude
+)
+
+// SavitzkyGolayFilter ajusta um polinômio por mínimos quadrados em uma janela deslizante,
+// suavizando o ruído sem achatar picos reais como faz a média móvel. Assume amostragem
+// aproximadamente uniforme, como a dos streams do Strava.
+type SavitzkyGolayFilter struct {
+	Field  PointField
+	Window int // tamanho ímpar da janela
+	Order  int // grau do polinômio
+}
+
+func (f *SavitzkyGolayFilter) Name() string {
+	if f.Field == FieldAltitude {
+		return "savitzky-golay-altitude"
+	}
+	return "savitzky-golay-velocity"
+}
+
+func (f *SavitzkyGolayFilter) Apply(points []GPSPoint) []GPSPoint {
+	half := f.Window / 2
+	if half < 1 || len(points) < 3 {
+		return points
+	}
+
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = f.get(p)
+	}
+
+	// Coeficientes por meia-janela; nas bordas a janela é reduzida simetricamente
+	coefficients := make(map[int][]float64)
+
+	result := make([]GPSPoint, len(points))
+	for i, p := range points {
+		m := half
+		if i < m {
+			m = i
+		}
+		if len(points)-1-i < m {
+			m = len(points) - 1 - i
+		}
+
+		if m > 0 {
+			coeffs, ok := coefficients[m]
+			if !ok {
+				coeffs = savitzkyGolayCoefficients(m, f.Order)
+				coefficients[m] = coeffs
+			}
+
+			smoothed := 0.0
+			for j := -m; j <= m; j++ {
+				smoothed += coeffs[j+m] * values[i+j]
+			}
+			f.set(&p, smoothed)
+		}
+		result[i] = p
+	}
+
+	return result
+}
+
+func (f *SavitzkyGolayFilter) get(p GPSPoint) float64 {
+	if f.Field == FieldAltitude {
+		return p.Altitude
+	}
+	return p.Velocity
+}
+
+func (f *SavitzkyGolayFilter) set(p *GPSPoint, value float64) {
+	if f.Field == FieldAltitude {
+		p.Altitude = value
+		return
+	}
+	p.Velocity = math.Max(0, value)
+}
+
+// savitzkyGolayCoefficients calcula os pesos de suavização para a janela [-m, m]: a primeira
+// linha de (AᵀA)⁻¹Aᵀ, com A[i][k] = iᵏ
+func savitzkyGolayCoefficients(m, order int) []float64 {
+	if order > 2*m {
+		order = 2 * m
+	}
+	size := order + 1
+
+	// AᵀA aumentada com a identidade para a inversão por Gauss-Jordan
+	ata := make([][]float64, size)
+	for r := range ata {
+		ata[r] = make([]float64, 2*size)
+		for c := 0; c < size; c++ {
+			for i := -m; i <= m; i++ {
+				ata[r][c] += math.Pow(float64(i), float64(r+c))
+			}
+		}
+		ata[r][size+r] = 1
+	}
+
+	for col := 0; col < size; col++ {
+		pivot := col
+		for r := col + 1; r < size; r++ {
+			if math.Abs(ata[r][col]) > math.Abs(ata[pivot][col]) {
+				pivot = r
+			}
+		}
+		ata[col], ata[pivot] = ata[pivot], ata[col]
+
+		div := ata[col][col]
+		for c := range ata[col] {
+			ata[col][c] /= div
+		}
+		for r := 0; r < size; r++ {
+			if r == col {
+				continue
+			}
+			factor := ata[r][col]
+			for c := range ata[r] {
+				ata[r][c] -= factor * ata[col][c]
+			}
+		}
+	}
+
+	coeffs := make([]float64, 2*m+1)
+	for i := -m; i <= m; i++ {
+		for k := 0; k < size; k++ {
+			coeffs[i+m] += ata[0][size+k] * math.Pow(float64(i), float64(k))
+		}
+	}
+	return coeffs
+}
@@ -0,0 +1,277 @@
+package gps
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+const (
+	testLat         = -23.55
+	testLng         = -46.63
+	metersPerDegLat = 111320.0
+)
+
+// straightTrack gera uma trilha de 1 Hz rumo ao leste a velocidade constante
+func straightTrack(n int, speed float64) []GPSPoint {
+	start := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	metersPerDegLng := metersPerDegLat * math.Cos(testLat*math.Pi/180)
+
+	points := make([]GPSPoint, n)
+	for i := range points {
+		points[i] = GPSPoint{
+			Time:     start.Add(time.Duration(i) * time.Second),
+			Lat:      testLat,
+			Lng:      testLng + float64(i)*speed/metersPerDegLng,
+			Velocity: speed,
+			Altitude: 700 + 0.05*float64(i),
+		}
+	}
+	return points
+}
+
+// positionRMS calcula o erro quadrático médio da posição em metros em relação à trilha real
+func positionRMS(points, truth []GPSPoint) float64 {
+	sum := 0.0
+	for i := range points {
+		d := DistanceMeters(points[i], truth[i])
+		sum += d * d
+	}
+	return math.Sqrt(sum / float64(len(points)))
+}
+
+// fieldRMS calcula o erro quadrático médio de um campo escalar em relação à trilha real
+func fieldRMS(points, truth []GPSPoint, get func(GPSPoint) float64) float64 {
+	sum := 0.0
+	for i := range points {
+		d := get(points[i]) - get(truth[i])
+		sum += d * d
+	}
+	return math.Sqrt(sum / float64(len(points)))
+}
+
+func TestDistanceMeters(t *testing.T) {
+	origin := GPSPoint{Lat: testLat, Lng: testLng}
+	tests := []struct {
+		name     string
+		from, to GPSPoint
+		want     float64
+	}{
+		{"mesmo ponto", origin, origin, 0},
+		{"1 km ao norte", origin, GPSPoint{Lat: testLat + 1000/metersPerDegLat, Lng: testLng}, 1000},
+		{"1 grau de longitude no equador", GPSPoint{Lat: 0, Lng: 0}, GPSPoint{Lat: 0, Lng: 1}, 111195},
+	}
+
+	for _, tt := range tests {
+		got := DistanceMeters(tt.from, tt.to)
+		if !nearlyEqual(got, tt.want, tt.want*0.002) {
+			t.Errorf("%s: %.2f m, esperado %.2f m", tt.name, got, tt.want)
+		}
+		if back := DistanceMeters(tt.to, tt.from); back != got {
+			t.Errorf("%s: distância não simétrica (%.6f != %.6f)", tt.name, got, back)
+		}
+	}
+}
+
+func TestSpeedOutlierFilterRejectsSpike(t *testing.T) {
+	points := straightTrack(60, 8)
+	// Pico de 2 km para o norte em uma única amostra
+	points[30].Lat += 2000 / metersPerDegLat
+
+	filter := &SpeedOutlierFilter{MaxSpeed: 40, MaxConsecutiveRejects: 5}
+	filtered := filter.Apply(points)
+
+	if len(filtered) != len(points)-1 {
+		t.Fatalf("esperava descartar 1 ponto, restaram %d de %d", len(filtered), len(points))
+	}
+	for _, p := range filtered {
+		if p.Time.Equal(points[30].Time) {
+			t.Fatalf("o pico em %v não foi descartado", p.Time)
+		}
+	}
+}
+
+func TestSpeedOutlierFilterReanchorsAfterPersistentJump(t *testing.T) {
+	points := straightTrack(60, 8)
+	// O primeiro ponto está 5 km fora da trilha: depois de MaxConsecutiveRejects
+	// rejeições, o filtro deve reancorar na trilha real em vez de descartá-la inteira
+	points[0].Lat += 5000 / metersPerDegLat
+
+	filter := &SpeedOutlierFilter{MaxSpeed: 40, MaxConsecutiveRejects: 5}
+	filtered := filter.Apply(points)
+
+	if len(filtered) < len(points)-10 {
+		t.Fatalf("o filtro descartou a trilha real: restaram %d de %d pontos", len(filtered), len(points))
+	}
+	if DistanceMeters(filtered[0], points[1]) > 100 {
+		t.Errorf("o ponto inicial fora da trilha não foi substituído: %+v", filtered[0])
+	}
+}
+
+func TestSpeedOutlierFilterKeepsPlausibleTrack(t *testing.T) {
+	points := straightTrack(60, 15)
+	filter := &SpeedOutlierFilter{MaxSpeed: 40, MaxConsecutiveRejects: 5}
+	if filtered := filter.Apply(points); len(filtered) != len(points) {
+		t.Fatalf("trilha plausível perdeu pontos: %d de %d", len(filtered), len(points))
+	}
+}
+
+func TestKalmanPositionFilterReducesNoise(t *testing.T) {
+	truth := straightTrack(300, 6)
+	metersPerDegLng := metersPerDegLat * math.Cos(testLat*math.Pi/180)
+
+	rng := rand.New(rand.NewSource(1))
+	noisy := make([]GPSPoint, len(truth))
+	for i, p := range truth {
+		p.Lat += rng.NormFloat64() * 5 / metersPerDegLat
+		p.Lng += rng.NormFloat64() * 5 / metersPerDegLng
+		noisy[i] = p
+	}
+
+	filter := &KalmanPositionFilter{MeasurementNoise: 5, ProcessNoise: 1.5}
+	smoothed := filter.Apply(noisy)
+
+	if len(smoothed) != len(noisy) {
+		t.Fatalf("o Kalman não deve descartar pontos: %d de %d", len(smoothed), len(noisy))
+	}
+
+	before := positionRMS(noisy, truth)
+	after := positionRMS(smoothed, truth)
+	if after > before/2 {
+		t.Errorf("o Kalman deveria reduzir o erro à metade: antes %.2f m, depois %.2f m", before, after)
+	}
+}
+
+func TestKalmanPositionFilterKeepsCleanTrack(t *testing.T) {
+	truth := straightTrack(120, 10)
+	filter := &KalmanPositionFilter{MeasurementNoise: 5, ProcessNoise: 1.5}
+
+	if rms := positionRMS(filter.Apply(truth), truth); rms > 0.5 {
+		t.Errorf("trilha sem ruído foi deslocada em %.2f m", rms)
+	}
+}
+
+func TestSavitzkyGolayFilterReducesNoise(t *testing.T) {
+	truth := straightTrack(300, 0)
+	for i := range truth {
+		// Velocidade e altitude com curvatura real, que a suavização não deve achatar
+		x := float64(i)
+		truth[i].Velocity = 8 + 4*math.Sin(x/40)
+		truth[i].Altitude = 700 + 30*math.Sin(x/60)
+	}
+
+	rng := rand.New(rand.NewSource(2))
+	noisy := make([]GPSPoint, len(truth))
+	for i, p := range truth {
+		p.Velocity += rng.NormFloat64() * 0.8
+		p.Altitude += rng.NormFloat64() * 2
+		noisy[i] = p
+	}
+
+	tests := []struct {
+		name   string
+		filter *SavitzkyGolayFilter
+		get    func(GPSPoint) float64
+	}{
+		{"velocidade", &SavitzkyGolayFilter{Field: FieldVelocity, Window: 5, Order: 2}, func(p GPSPoint) float64 { return p.Velocity }},
+		{"altitude", &SavitzkyGolayFilter{Field: FieldAltitude, Window: 11, Order: 2}, func(p GPSPoint) float64 { return p.Altitude }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			smoothed := tt.filter.Apply(noisy)
+			before := fieldRMS(noisy, truth, tt.get)
+			after := fieldRMS(smoothed, truth, tt.get)
+			if after > before*0.8 {
+				t.Errorf("o Savitzky–Golay deveria reduzir o ruído: antes %.3f, depois %.3f", before, after)
+			}
+		})
+	}
+}
+
+func TestSavitzkyGolayFilterPreservesQuadratic(t *testing.T) {
+	points := straightTrack(50, 0)
+	for i := range points {
+		x := float64(i)
+		points[i].Altitude = 500 + 2*x - 0.03*x*x
+	}
+
+	filter := &SavitzkyGolayFilter{Field: FieldAltitude, Window: 11, Order: 2}
+	smoothed := filter.Apply(points)
+
+	for i := range points {
+		if diff := math.Abs(smoothed[i].Altitude - points[i].Altitude); diff > 1e-6 {
+			t.Fatalf("polinômio de grau 2 alterado no ponto %d: %.6f != %.6f", i, smoothed[i].Altitude, points[i].Altitude)
+		}
+	}
+}
+
+func TestSavitzkyGolayFilterClampsNegativeVelocity(t *testing.T) {
+	points := straightTrack(20, 0)
+	points[10].Velocity = -5
+
+	filter := &SavitzkyGolayFilter{Field: FieldVelocity, Window: 5, Order: 2}
+	for i, p := range filter.Apply(points) {
+		if p.Velocity < 0 {
+			t.Fatalf("velocidade negativa no ponto %d: %.3f", i, p.Velocity)
+		}
+	}
+}
+
+func TestNewFilterChainFromConfig(t *testing.T) {
+	cfg, err := ParseFilterConfig("0", "false")
+	if err != nil {
+		t.Fatalf("ParseFilterConfig: %v", err)
+	}
+
+	chain := NewFilterChain(cfg)
+	for _, filter := range chain {
+		if filter.Name() == "speed-outlier" || filter.Name() == "kalman-position" {
+			t.Errorf("filtro %s deveria estar desativado", filter.Name())
+		}
+	}
+	if len(chain) != 2 {
+		t.Errorf("esperava apenas os dois Savitzky–Golay, obteve %d filtros", len(chain))
+	}
+
+	if _, err := ParseFilterConfig("-1", ""); err == nil {
+		t.Error("velocidade negativa deveria ser rejeitada")
+	}
+	if _, err := ParseFilterConfig("", "talvez"); err == nil {
+		t.Error("valor inválido para o Kalman deveria ser rejeitado")
+	}
+}
+
+func TestGPSProcessorUsesConfiguredChain(t *testing.T) {
+	points := straightTrack(60, 8)
+	points[30].Lat += 2000 / metersPerDegLat
+
+	data := StreamData{}
+	start := points[0].Time
+	for _, p := range points {
+		data.Time = append(data.Time, int(p.Time.Sub(start).Seconds()))
+		data.LatLng = append(data.LatLng, [2]float64{p.Lat, p.Lng})
+	}
+
+	// Sem filtros, o pico aparece na trilha interpolada
+	processor := NewGPSProcessor()
+	processor.SetFilterChain(nil)
+	if err := processor.ProcessStreamData(data, start); err != nil {
+		t.Fatalf("ProcessStreamData: %v", err)
+	}
+	spike, _ := processor.GetPointForTime(points[30].Time)
+	if DistanceMeters(spike, points[29]) < 1000 {
+		t.Fatalf("sem filtros o pico deveria permanecer na trilha")
+	}
+
+	processor = NewGPSProcessor()
+	processor.SetFilterChain(FilterChain{&SpeedOutlierFilter{MaxSpeed: 40, MaxConsecutiveRejects: 5}})
+	if err := processor.ProcessStreamData(data, start); err != nil {
+		t.Fatalf("ProcessStreamData: %v", err)
+	}
+	filtered, _ := processor.GetPointForTime(points[30].Time)
+	if d := DistanceMeters(filtered, points[29]); d > 100 {
+		t.Errorf("com a cadeia configurada o pico deveria ser removido, distância %.0f m", d)
+	}
+}
@@ -0,0 +1,294 @@
+package gps
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Motivos de um destaque: o componente que mais contribuiu para a pontuação
+const (
+	HighlightSpeed     = "speed"
+	HighlightGForce    = "gforce"
+	HighlightGrade     = "grade"
+	HighlightHeartRate = "heart_rate"
+	HighlightSegment   = "segment"
+)
+
+// TimeRange é um intervalo da linha do tempo da trilha
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// HighlightConfig controla a detecção de destaques
+type HighlightConfig struct {
+	// Duração de cada destaque e passo entre janelas candidatas
+	Window time.Duration
+	Step   time.Duration
+	// Número máximo de destaques retornados
+	MaxHighlights int
+	// Pesos de cada componente na pontuação
+	SpeedWeight     float64
+	GForceWeight    float64
+	GradeWeight     float64
+	HeartRateWeight float64
+	SegmentWeight   float64
+}
+
+// DefaultHighlightConfig retorna até 5 destaques de 20 segundos, priorizando velocidade
+func DefaultHighlightConfig() HighlightConfig {
+	return HighlightConfig{
+		Window:          20 * time.Second,
+		Step:            2 * time.Second,
+		MaxHighlights:   5,
+		SpeedWeight:     1.0,
+		GForceWeight:    0.8,
+		GradeWeight:     0.6,
+		HeartRateWeight: 0.6,
+		SegmentWeight:   0.8,
+	}
+}
+
+// ParseHighlightConfig interpreta o número de destaques ("5") e a duração de cada um ("20s").
+// Valores vazios mantêm o padrão; valores inválidos retornam o padrão e um erro.
+func ParseHighlightConfig(count, window string) (HighlightConfig, error) {
+	cfg := DefaultHighlightConfig()
+
+	if count = strings.TrimSpace(count); count != "" {
+		n, err := strconv.Atoi(count)
+		if err != nil || n <= 0 {
+			return DefaultHighlightConfig(), fmt.Errorf("número de destaques inválido '%s'", count)
+		}
+		cfg.MaxHighlights = n
+	}
+
+	if window = strings.TrimSpace(window); window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil || d < time.Second {
+			return DefaultHighlightConfig(), fmt.Errorf("duração de destaque inválida '%s'", window)
+		}
+		cfg.Window = d
+		cfg.Step = max(d/10, time.Second)
+	}
+
+	return cfg, nil
+}
+
+// Highlight é um trecho da trilha com pontuação entre 0 e 1 e os picos que a explicam
+type Highlight struct {
+	Start         time.Time
+	End           time.Time
+	Score         float64
+	Reason        string  // Componente dominante (HighlightSpeed, HighlightGForce...)
+	PeakSpeed     float64 // m/s
+	MaxGForce     float64
+	SteepestGrade float64 // %, com sinal (negativo em descidas)
+	HeartRateRise float64 // bpm de subida dentro do trecho
+	Segment       string  // Segmento do Strava percorrido no trecho, se houver
+}
+
+// highlightWindow guarda os componentes brutos de uma janela candidata
+type highlightWindow struct {
+	Highlight
+	components [5]float64   // velocidade, G, inclinação, FC, segmento
+	peaks      [5]time.Time // Instante do pico de cada componente
+	peak       time.Time    // Pico do componente dominante
+}
+
+// DetectHighlights pontua janelas deslizantes da trilha por pico de velocidade, força G,
+// inclinação, subida de frequência cardíaca e esforços em segmentos, e retorna os melhores
+// trechos sem sobreposição, do mais ao menos pontuado. Com coverage, só são consideradas
+// janelas inteiramente dentro desses intervalos (ex.: os trechos filmados).
+func DetectHighlights(points []GPSPoint, efforts []SegmentEffort, coverage []TimeRange, cfg HighlightConfig) []Highlight {
+	if len(points) < 2 || cfg.Window <= 0 {
+		return nil
+	}
+	if cfg.Step <= 0 {
+		cfg.Step = cfg.Window / 10
+	}
+	if len(coverage) == 0 {
+		coverage = []TimeRange{{Start: points[0].Time, End: points[len(points)-1].Time}}
+	}
+
+	var windows []highlightWindow
+	for _, r := range coverage {
+		for start := r.Start; !start.After(r.End); start = start.Add(cfg.Step) {
+			end := start.Add(cfg.Window)
+			if end.After(r.End) {
+				// Intervalo mais curto que a janela vira um único candidato
+				if !start.Equal(r.Start) {
+					break
+				}
+				end = r.End
+			}
+			if window, ok := scoreWindow(points, efforts, start, end); ok {
+				windows = append(windows, window)
+			}
+		}
+	}
+	if len(windows) == 0 {
+		return nil
+	}
+
+	normalizeHighlightScores(windows, cfg)
+	return selectHighlights(windows, cfg.MaxHighlights)
+}
+
+// scoreWindow calcula os componentes brutos dos pontos em [start, end)
+func scoreWindow(points []GPSPoint, efforts []SegmentEffort, start, end time.Time) (highlightWindow, bool) {
+	first := sort.Search(len(points), func(i int) bool { return !points[i].Time.Before(start) })
+
+	window := highlightWindow{Highlight: Highlight{Start: start, End: end}}
+	minHeartRate := math.Inf(1)
+	count := 0
+
+	for i := first; i < len(points) && points[i].Time.Before(end); i++ {
+		p := points[i]
+		count++
+		if p.Paused {
+			continue
+		}
+
+		if p.Velocity > window.PeakSpeed {
+			window.PeakSpeed, window.peaks[0] = p.Velocity, p.Time
+		}
+		if p.GForce > window.MaxGForce {
+			window.MaxGForce, window.peaks[1] = p.GForce, p.Time
+		}
+		if math.Abs(p.Grade) > math.Abs(window.SteepestGrade) {
+			window.SteepestGrade, window.peaks[2] = p.Grade, p.Time
+		}
+		if p.HeartRate > 0 {
+			minHeartRate = math.Min(minHeartRate, p.HeartRate)
+			if rise := p.HeartRate - minHeartRate; rise > window.HeartRateRise {
+				window.HeartRateRise, window.peaks[3] = rise, p.Time
+			}
+		}
+	}
+	if count == 0 {
+		return window, false
+	}
+
+	// Inclinação só conta em movimento: descidas rápidas valem mais que rampas lentas
+	gradeScore := math.Abs(window.SteepestGrade) * math.Min(window.PeakSpeed/10, 1)
+
+	segmentScore := 0.0
+	for _, effort := range efforts {
+		overlapStart := maxTime(start, effort.Start)
+		overlap := minTime(end, effort.End()).Sub(overlapStart)
+		if overlap <= 0 {
+			continue
+		}
+		score := overlap.Seconds() / end.Sub(start).Seconds()
+		if effort.PRRank > 0 || effort.KOMRank > 0 {
+			score *= 1.5 // Recordes pessoais e top 10 pesam mais
+		}
+		if score > segmentScore {
+			segmentScore = score
+			window.Segment = effort.Name
+			window.peaks[4] = overlapStart.Add(overlap / 2)
+		}
+	}
+
+	window.components = [5]float64{window.PeakSpeed, window.MaxGForce, gradeScore, window.HeartRateRise, segmentScore}
+	return window, true
+}
+
+// normalizeHighlightScores divide cada componente pelo maior valor entre as janelas e combina
+// os componentes pelos pesos. Componentes sem dados (ex.: sem frequência cardíaca) não
+// reduzem a pontuação das demais.
+func normalizeHighlightScores(windows []highlightWindow, cfg HighlightConfig) {
+	weights := [5]float64{cfg.SpeedWeight, cfg.GForceWeight, cfg.GradeWeight, cfg.HeartRateWeight, cfg.SegmentWeight}
+	reasons := [5]string{HighlightSpeed, HighlightGForce, HighlightGrade, HighlightHeartRate, HighlightSegment}
+
+	var maxima [5]float64
+	for _, w := range windows {
+		for k, value := range w.components {
+			maxima[k] = math.Max(maxima[k], value)
+		}
+	}
+
+	totalWeight := 0.0
+	for k, weight := range weights {
+		if maxima[k] > 0 {
+			totalWeight += weight
+		}
+	}
+	if totalWeight == 0 {
+		return
+	}
+
+	for i := range windows {
+		best := 0.0
+		for k, value := range windows[i].components {
+			if maxima[k] == 0 {
+				continue
+			}
+			contribution := weights[k] * value / maxima[k]
+			windows[i].Score += contribution / totalWeight
+			if contribution > best {
+				best = contribution
+				windows[i].Reason = reasons[k]
+				windows[i].peak = windows[i].peaks[k]
+			}
+		}
+	}
+}
+
+// selectHighlights escolhe as janelas de maior pontuação que não se sobrepõem. Entre janelas
+// com a mesma pontuação (o mesmo pico visto por janelas vizinhas) vence a mais centrada no pico.
+func selectHighlights(windows []highlightWindow, limit int) []Highlight {
+	offCenter := func(w highlightWindow) time.Duration {
+		d := w.peak.Sub(w.Start.Add(w.End.Sub(w.Start) / 2))
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+	sort.SliceStable(windows, func(i, j int) bool {
+		if windows[i].Score != windows[j].Score {
+			return windows[i].Score > windows[j].Score
+		}
+		return offCenter(windows[i]) < offCenter(windows[j])
+	})
+
+	var selected []Highlight
+	for _, w := range windows {
+		if limit > 0 && len(selected) >= limit {
+			break
+		}
+		if w.Score <= 0 {
+			break
+		}
+
+		overlaps := false
+		for _, h := range selected {
+			if w.Start.Before(h.End) && h.Start.Before(w.End) {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			selected = append(selected, w.Highlight)
+		}
+	}
+	return selected
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,182 @@
+package gps
+
+import (
+	"testing"
+	"time"
+)
+
+// highlightTrack gera 10 minutos a 8 m/s em leve subida, com um sprint de 5 s a 16 m/s aos
+// 100 s e uma descida íngreme de 5 s a 11 m/s aos 400 s
+func highlightTrack() []GPSPoint {
+	points := straightTrack(600, 8)
+	for i := range points {
+		points[i].Grade = 1
+		switch {
+		case i >= 100 && i < 105:
+			points[i].Velocity = 16
+		case i >= 400 && i < 405:
+			points[i].Velocity = 11
+			points[i].Grade = -9
+		}
+	}
+	return points
+}
+
+// highlightTestConfig dá à inclinação o mesmo peso da velocidade, para que a descida vença
+func highlightTestConfig() HighlightConfig {
+	cfg := DefaultHighlightConfig()
+	cfg.GradeWeight = 1
+	return cfg
+}
+
+// assertNoOverlap falha se dois destaques compartilham algum instante
+func assertNoOverlap(t *testing.T, highlights []Highlight) {
+	t.Helper()
+	for i := range highlights {
+		for j := i + 1; j < len(highlights); j++ {
+			a, b := highlights[i], highlights[j]
+			if a.Start.Before(b.End) && b.Start.Before(a.End) {
+				t.Errorf("destaques %d [%s, %s) e %d [%s, %s) se sobrepõem", i,
+					a.Start.Format("15:04:05"), a.End.Format("15:04:05"), j,
+					b.Start.Format("15:04:05"), b.End.Format("15:04:05"))
+			}
+		}
+	}
+}
+
+func TestDetectHighlightsRanksDescentAndSprint(t *testing.T) {
+	points := highlightTrack()
+	start := points[0].Time
+
+	highlights := DetectHighlights(points, nil, nil, highlightTestConfig())
+	if len(highlights) != 5 {
+		t.Fatalf("esperava 5 destaques, obteve %d", len(highlights))
+	}
+	assertNoOverlap(t, highlights)
+
+	tests := []struct {
+		name       string
+		highlight  Highlight
+		wantStart  time.Duration
+		wantReason string
+	}{
+		// Cada destaque fica centrado no primeiro ponto do pico
+		{"descida", highlights[0], 390 * time.Second, HighlightGrade},
+		{"sprint", highlights[1], 90 * time.Second, HighlightSpeed},
+	}
+	for _, tt := range tests {
+		if got := tt.highlight.Start.Sub(start); got != tt.wantStart {
+			t.Errorf("%s: início em %v, esperado %v", tt.name, got, tt.wantStart)
+		}
+		if got := tt.highlight.End.Sub(tt.highlight.Start); got != 20*time.Second {
+			t.Errorf("%s: duração %v, esperado 20s", tt.name, got)
+		}
+		if tt.highlight.Reason != tt.wantReason {
+			t.Errorf("%s: motivo %q, esperado %q", tt.name, tt.highlight.Reason, tt.wantReason)
+		}
+	}
+	if highlights[0].SteepestGrade != -9 || highlights[1].PeakSpeed != 16 {
+		t.Errorf("picos: inclinação %.1f, velocidade %.1f", highlights[0].SteepestGrade, highlights[1].PeakSpeed)
+	}
+
+	for i := 1; i < len(highlights); i++ {
+		if highlights[i].Score > highlights[i-1].Score {
+			t.Errorf("destaque %d (%.3f) pontuado acima do anterior (%.3f)", i, highlights[i].Score, highlights[i-1].Score)
+		}
+	}
+	if highlights[2].Score >= highlights[1].Score {
+		t.Errorf("trecho comum (%.3f) deveria pontuar abaixo do sprint (%.3f)", highlights[2].Score, highlights[1].Score)
+	}
+}
+
+func TestDetectHighlightsWithinCoverage(t *testing.T) {
+	points := highlightTrack()
+	start := points[0].Time
+
+	// Vídeos que filmaram só o sprint e um trecho comum, sem a descida
+	coverage := []TimeRange{
+		{Start: start.Add(60 * time.Second), End: start.Add(180 * time.Second)},
+		{Start: start.Add(500 * time.Second), End: start.Add(560 * time.Second)},
+	}
+	highlights := DetectHighlights(points, nil, coverage, highlightTestConfig())
+	if len(highlights) == 0 {
+		t.Fatal("nenhum destaque detectado")
+	}
+	assertNoOverlap(t, highlights)
+
+	if got := highlights[0].Start.Sub(start); got != 90*time.Second || highlights[0].Reason != HighlightSpeed {
+		t.Errorf("o sprint deveria ser o primeiro destaque, obtido início %v motivo %q", got, highlights[0].Reason)
+	}
+	for i, h := range highlights {
+		inside := false
+		for _, r := range coverage {
+			if !h.Start.Before(r.Start) && !h.End.After(r.End) {
+				inside = true
+			}
+		}
+		if !inside {
+			t.Errorf("destaque %d [%v, %v) fora dos trechos filmados", i, h.Start.Sub(start), h.End.Sub(start))
+		}
+	}
+}
+
+func TestSelectHighlights(t *testing.T) {
+	base := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return base.Add(time.Duration(seconds) * time.Second) }
+	window := func(start int, score float64, peak int) highlightWindow {
+		return highlightWindow{
+			Highlight: Highlight{Start: at(start), End: at(start + 20), Score: score},
+			peak:      at(peak),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		windows    []highlightWindow
+		limit      int
+		wantStarts []int
+	}{
+		{
+			name:       "ordena pela pontuação",
+			windows:    []highlightWindow{window(0, 0.2, 10), window(100, 0.9, 110), window(50, 0.5, 60)},
+			wantStarts: []int{100, 50, 0},
+		},
+		{
+			name:       "descarta janelas sobrepostas a uma melhor",
+			windows:    []highlightWindow{window(0, 0.8, 10), window(10, 0.7, 20), window(19, 0.6, 29), window(20, 0.5, 30)},
+			wantStarts: []int{0, 20},
+		},
+		{
+			name:       "empate vence a mais centrada no pico",
+			windows:    []highlightWindow{window(0, 0.8, 18), window(8, 0.8, 18), window(4, 0.8, 18)},
+			wantStarts: []int{8},
+		},
+		{
+			name:       "respeita o limite",
+			windows:    []highlightWindow{window(0, 0.9, 10), window(30, 0.8, 40), window(60, 0.7, 70)},
+			limit:      2,
+			wantStarts: []int{0, 30},
+		},
+		{
+			name:       "ignora janelas sem pontuação",
+			windows:    []highlightWindow{window(0, 0.4, 10), window(30, 0, 40)},
+			wantStarts: []int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			highlights := selectHighlights(tt.windows, tt.limit)
+			assertNoOverlap(t, highlights)
+
+			if len(highlights) != len(tt.wantStarts) {
+				t.Fatalf("esperava %d destaques, obteve %d", len(tt.wantStarts), len(highlights))
+			}
+			for i, want := range tt.wantStarts {
+				if got := highlights[i].Start; !got.Equal(at(want)) {
+					t.Errorf("destaque %d começa em %v, esperado %ds", i, got.Sub(base), want)
+				}
+			}
+		})
+	}
+}
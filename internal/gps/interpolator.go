@@ -0,0 +1,168 @@
+package gps
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Interpolator amostra a trilha processada em instantes arbitrários, com precisão abaixo de
+// um segundo: spline cúbica de Hermite para a posição, interpolação linear para os valores
+// escalares e o menor arco para o bearing
+type Interpolator struct {
+	points []GPSPoint
+}
+
+// NewInterpolator cria um interpolador sobre pontos ordenados por tempo
+func NewInterpolator(points []GPSPoint) *Interpolator {
+	return &Interpolator{points: points}
+}
+
+// Range retorna o primeiro e o último instante cobertos pela trilha
+func (in *Interpolator) Range() (time.Time, time.Time, bool) {
+	if len(in.points) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	return in.points[0].Time, in.points[len(in.points)-1].Time, true
+}
+
+// At retorna o ponto interpolado no instante t. Fora da trilha retorna o ponto da borda mais
+// próxima e false.
+func (in *Interpolator) At(t time.Time) (GPSPoint, bool) {
+	n := len(in.points)
+	if n == 0 {
+		return GPSPoint{}, false
+	}
+
+	if t.Before(in.points[0].Time) {
+		point := in.points[0]
+		point.Time = t
+		return point, false
+	}
+	if t.After(in.points[n-1].Time) {
+		point := in.points[n-1]
+		point.Time = t
+		return point, false
+	}
+
+	// Primeiro índice com Time > t; o segmento é [i-1, i]
+	i := sort.Search(n, func(k int) bool { return in.points[k].Time.After(t) })
+	if i == n {
+		point := in.points[n-1]
+		point.Time = t
+		return point, true
+	}
+	i--
+
+	p1 := in.points[i]
+	p2 := in.points[i+1]
+	span := p2.Time.Sub(p1.Time).Seconds()
+	if span <= 0 {
+		p1.Time = t
+		return p1, true
+	}
+	ratio := t.Sub(p1.Time).Seconds() / span
+
+	lat, lng := in.hermitePosition(i, ratio)
+
+	point := GPSPoint{
+		Time:     t,
+		Lat:      lat,
+		Lng:      lng,
+		Velocity: lerp(p1.Velocity, p2.Velocity, ratio),
+		Altitude: lerp(p1.Altitude, p2.Altitude, ratio),
+		Bearing:  interpolateBearing(p1.Bearing, p2.Bearing, ratio),
+		GForce:   lerp(p1.GForce, p2.GForce, ratio),
+
+		LongitudinalG: lerp(p1.LongitudinalG, p2.LongitudinalG, ratio),
+		LateralG:      lerp(p1.LateralG, p2.LateralG, ratio),
+
+		Paused:    p2.Paused,
+		HeartRate: lerp(p1.HeartRate, p2.HeartRate, ratio),
+	}
+	interpolateCumulative(&point, p1, p2, ratio)
+
+	return point, true
+}
+
+// hermitePosition interpola lat/lng no segmento [i, i+1] com tangentes de Catmull-Rom
+// não uniforme, que respeitam intervalos de tempo irregulares entre amostras
+func (in *Interpolator) hermitePosition(i int, ratio float64) (float64, float64) {
+	p1 := in.points[i]
+	p2 := in.points[i+1]
+	h := p2.Time.Sub(p1.Time).Seconds()
+
+	latTan1, lngTan1 := in.tangent(i)
+	latTan2, lngTan2 := in.tangent(i + 1)
+
+	s := ratio
+	s2 := s * s
+	s3 := s2 * s
+	h00 := 2*s3 - 3*s2 + 1
+	h10 := s3 - 2*s2 + s
+	h01 := -2*s3 + 3*s2
+	h11 := s3 - s2
+
+	lat := h00*p1.Lat + h10*h*latTan1 + h01*p2.Lat + h11*h*latTan2
+	lng := h00*p1.Lng + h10*h*lngTan1 + h01*p2.Lng + h11*h*lngTan2
+	return lat, lng
+}
+
+// tangent estima a derivada da posição (graus por segundo) no ponto i
+func (in *Interpolator) tangent(i int) (float64, float64) {
+	prev := i - 1
+	next := i + 1
+	if prev < 0 {
+		prev = i
+	}
+	if next >= len(in.points) {
+		next = i
+	}
+
+	dt := in.points[next].Time.Sub(in.points[prev].Time).Seconds()
+	if dt <= 0 {
+		return 0, 0
+	}
+
+	return (in.points[next].Lat - in.points[prev].Lat) / dt,
+		(in.points[next].Lng - in.points[prev].Lng) / dt
+}
+
+// FrameSampler gera um ponto interpolado para cada quadro do vídeo
+type FrameSampler struct {
+	interpolator *Interpolator
+	frameRate    float64
+}
+
+// NewFrameSampler cria um amostrador na taxa de quadros informada
+func NewFrameSampler(interpolator *Interpolator, frameRate float64) *FrameSampler {
+	if frameRate <= 0 || math.IsNaN(frameRate) {
+		frameRate = 1
+	}
+	return &FrameSampler{interpolator: interpolator, frameRate: frameRate}
+}
+
+// FrameRate retorna a taxa de amostragem efetiva
+func (fs *FrameSampler) FrameRate() float64 {
+	return fs.frameRate
+}
+
+// Sample retorna um ponto por quadro entre start e start+duration. Quadros fora da trilha
+// repetem o ponto da borda, mantendo o overlay alinhado à linha do tempo do vídeo.
+func (fs *FrameSampler) Sample(start time.Time, duration time.Duration) []GPSPoint {
+	frameCount := int(math.Ceil(duration.Seconds() * fs.frameRate))
+	if frameCount < 1 {
+		frameCount = 1
+	}
+
+	samples := make([]GPSPoint, frameCount)
+	for frame := 0; frame < frameCount; frame++ {
+		offset := time.Duration(float64(frame) / fs.frameRate * float64(time.Second))
+		samples[frame], _ = fs.interpolator.At(start.Add(offset))
+	}
+	return samples
+}
+
+func lerp(a, b, ratio float64) float64 {
+	return a + ratio*(b-a)
+}
@@ -0,0 +1,272 @@
+package gps
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// rideTrack gera uma trilha de 1 Hz com curvas e variação de velocidade, como a de um pedal
+func rideTrack(duration time.Duration) []GPSPoint {
+	n := int(duration.Seconds()) + 1
+	points := straightTrack(n, 0)
+	for i := range points {
+		x := float64(i)
+		points[i].Lat = testLat + 0.01*math.Sin(x/300)
+		points[i].Lng = testLng + 0.00008*x
+		points[i].Velocity = 8 + 3*math.Sin(x/50)
+		points[i].Bearing = math.Mod(x, 360)
+		points[i].Distance = 8 * x
+	}
+	return points
+}
+
+// nearlyEqual compara valores de ponto flutuante com tolerância absoluta
+func nearlyEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestInterpolatorAtPosition(t *testing.T) {
+	// Movimento retilíneo uniforme, com um intervalo irregular de 2 s entre as amostras 2 e 3:
+	// a spline com tangentes de Catmull-Rom não uniformes reproduz a reta exatamente
+	const speed = 8.0
+	metersPerDegLng := metersPerDegLat * math.Cos(testLat*math.Pi/180)
+	start := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	var points []GPSPoint
+	for _, second := range []float64{0, 1, 2, 4, 5, 6} {
+		points = append(points, GPSPoint{
+			Time: start.Add(time.Duration(second * float64(time.Second))),
+			Lat:  testLat + second*speed/2/metersPerDegLat,
+			Lng:  testLng + second*speed/metersPerDegLng,
+		})
+	}
+	in := NewInterpolator(points)
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+	}{
+		{"amostra exata", 2 * time.Second},
+		{"ponto médio", 500 * time.Millisecond},
+		{"um quarto do segmento", 1250 * time.Millisecond},
+		{"meio do intervalo irregular", 3 * time.Second},
+		{"perto do fim", 5900 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := in.At(start.Add(tt.offset))
+			if !ok {
+				t.Fatalf("At(%v) deveria estar dentro da trilha", tt.offset)
+			}
+			second := tt.offset.Seconds()
+			wantLat := testLat + second*speed/2/metersPerDegLat
+			wantLng := testLng + second*speed/metersPerDegLng
+			if !nearlyEqual(got.Lat, wantLat, 1e-9) || !nearlyEqual(got.Lng, wantLng, 1e-9) {
+				t.Errorf("At(%v) = (%.8f, %.8f), esperado (%.8f, %.8f)", tt.offset, got.Lat, got.Lng, wantLat, wantLng)
+			}
+			if !got.Time.Equal(start.Add(tt.offset)) {
+				t.Errorf("At(%v) retornou o instante %v", tt.offset, got.Time)
+			}
+		})
+	}
+}
+
+func TestInterpolateBearing(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to float64
+		ratio    float64
+		want     float64
+	}{
+		{"sem volta", 10, 20, 0.5, 15},
+		{"359° para 1° passa pelo norte", 359, 1, 0.5, 0},
+		{"1° para 359° passa pelo norte", 1, 359, 0.25, 0.5},
+		{"350° para 10° a três quartos", 350, 10, 0.75, 5},
+		{"bearing negativo normalizado", -90, 0, 0.5, 315},
+		{"início do segmento", 359, 1, 0, 359},
+		{"fim do segmento", 359, 1, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := interpolateBearing(tt.from, tt.to, tt.ratio)
+			if !nearlyEqual(got, tt.want, 1e-9) {
+				t.Errorf("interpolateBearing(%v, %v, %v) = %v, esperado %v", tt.from, tt.to, tt.ratio, got, tt.want)
+			}
+			if got < 0 || got >= 360 {
+				t.Errorf("bearing %v fora de [0, 360)", got)
+			}
+		})
+	}
+}
+
+func TestInterpolatorAtScalarAndCumulativeFields(t *testing.T) {
+	start := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	p1 := GPSPoint{
+		Time: start, Lat: testLat, Lng: testLng,
+		Velocity: 6, Altitude: 700, Bearing: 358, GForce: 0.1, LongitudinalG: 0.2, LateralG: -0.4, HeartRate: 140,
+		Distance: 1000, ElapsedTime: 5 * time.Minute, MovingTime: 4 * time.Minute, Ascent: 50, Descent: 20, Grade: 2,
+	}
+	p2 := GPSPoint{
+		Time: start.Add(2 * time.Second), Lat: testLat, Lng: testLng + 0.0002,
+		Velocity: 10, Altitude: 704, Bearing: 4, GForce: 0.3, LongitudinalG: -0.2, LateralG: 0.4, HeartRate: 150,
+		Distance: 1016, ElapsedTime: 5*time.Minute + 2*time.Second, MovingTime: 4*time.Minute + 2*time.Second,
+		Ascent: 54, Descent: 20, Grade: 6, Paused: true,
+	}
+
+	got, ok := NewInterpolator([]GPSPoint{p1, p2}).At(start.Add(500 * time.Millisecond))
+	if !ok {
+		t.Fatal("At deveria estar dentro da trilha")
+	}
+
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"velocidade", got.Velocity, 7},
+		{"altitude", got.Altitude, 701},
+		{"bearing", got.Bearing, 359.5},
+		{"G-force", got.GForce, 0.15},
+		{"G longitudinal", got.LongitudinalG, 0.1},
+		{"G lateral", got.LateralG, -0.2},
+		{"frequência cardíaca", got.HeartRate, 142.5},
+		{"distância", got.Distance, 1004},
+		{"tempo decorrido (s)", got.ElapsedTime.Seconds(), 300.5},
+		{"tempo em movimento (s)", got.MovingTime.Seconds(), 240.5},
+		{"subida", got.Ascent, 51},
+		{"descida", got.Descent, 20},
+		{"inclinação", got.Grade, 3},
+	}
+	for _, tt := range tests {
+		if !nearlyEqual(tt.got, tt.want, 1e-9) {
+			t.Errorf("%s: obtido %v, esperado %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if !got.Paused {
+		t.Error("o trecho que termina numa pausa deveria estar marcado como pausado")
+	}
+}
+
+func TestInterpolatorAtOutsideTrack(t *testing.T) {
+	points := rideTrack(10 * time.Second)
+	first, last := points[0], points[len(points)-1]
+	in := NewInterpolator(points)
+
+	tests := []struct {
+		name   string
+		at     time.Time
+		want   GPSPoint
+		wantOK bool
+	}{
+		{"antes do início", first.Time.Add(-3 * time.Second), first, false},
+		{"no primeiro ponto", first.Time, first, true},
+		{"no último ponto", last.Time, last, true},
+		{"depois do fim", last.Time.Add(90 * time.Second), last, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := in.At(tt.at)
+			if ok != tt.wantOK {
+				t.Errorf("ok = %v, esperado %v", ok, tt.wantOK)
+			}
+			if !got.Time.Equal(tt.at) {
+				t.Errorf("o ponto deveria ter o instante pedido %v, tem %v", tt.at, got.Time)
+			}
+			if got.Lat != tt.want.Lat || got.Lng != tt.want.Lng || got.Velocity != tt.want.Velocity || got.Distance != tt.want.Distance {
+				t.Errorf("esperava os valores do ponto da borda %+v, obteve %+v", tt.want, got)
+			}
+		})
+	}
+
+	if _, ok := NewInterpolator(nil).At(first.Time); ok {
+		t.Error("trilha vazia não deveria retornar ponto")
+	}
+}
+
+func TestProcessorGridMatchesInterpolator(t *testing.T) {
+	quietLogs(t)
+
+	// Amostras com lacunas de 4 e 7 segundos, como as de um GPS em modo econômico
+	points := rideTrack(60 * time.Second)
+	data := StreamData{}
+	start := points[0].Time
+	for i, p := range points {
+		if (i > 10 && i < 14) || (i > 30 && i < 37) {
+			continue
+		}
+		data.Time = append(data.Time, i)
+		data.LatLng = append(data.LatLng, [2]float64{p.Lat, p.Lng})
+		data.Velocity = append(data.Velocity, p.Velocity)
+	}
+
+	processor := NewGPSProcessor()
+	processor.SetFilterChain(nil)
+	if err := processor.ProcessStreamData(data, start); err != nil {
+		t.Fatalf("ProcessStreamData: %v", err)
+	}
+
+	grid := processor.GetAllPoints()
+	if len(grid) != len(points) {
+		t.Fatalf("esperava %d pontos na grade de 1 s, obteve %d", len(points), len(grid))
+	}
+	track := processor.Interpolator()
+	for i, point := range grid {
+		if want := start.Add(time.Duration(i) * time.Second); !point.Time.Equal(want) {
+			t.Fatalf("ponto %d em %v, esperado %v", i, point.Time.Sub(start), want.Sub(start))
+		}
+		want, _ := track.At(point.Time)
+		if point != want {
+			t.Errorf("ponto %d da grade difere do Interpolator: %+v != %+v", i, point, want)
+		}
+	}
+}
+
+func TestFrameSamplerCoversDuration(t *testing.T) {
+	points := rideTrack(10 * time.Second)
+	sampler := NewFrameSampler(NewInterpolator(points), 60)
+
+	samples := sampler.Sample(points[0].Time, 10*time.Second)
+	if len(samples) != 600 {
+		t.Fatalf("esperava 600 quadros, obteve %d", len(samples))
+	}
+	for i := 1; i < len(samples); i++ {
+		if !samples[i].Time.After(samples[i-1].Time) {
+			t.Fatalf("quadro %d fora de ordem: %v <= %v", i, samples[i].Time, samples[i-1].Time)
+		}
+	}
+	if got := samples[30].Time.Sub(points[0].Time); got != 500*time.Millisecond {
+		t.Errorf("quadro 30 deveria cair em 500ms, caiu em %v", got)
+	}
+}
+
+// BenchmarkInterpolatorAt mede uma consulta em um pedal de 5 horas
+func BenchmarkInterpolatorAt(b *testing.B) {
+	points := rideTrack(5 * time.Hour)
+	interpolator := NewInterpolator(points)
+	start := points[0].Time
+	span := points[len(points)-1].Time.Sub(start)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		offset := time.Duration(int64(i) * int64(time.Second/60) % int64(span))
+		interpolator.At(start.Add(offset))
+	}
+}
+
+// BenchmarkFrameSampler5h60fps mede a amostragem de todos os quadros de um vídeo de 5 horas
+// a 60 fps (1.080.000 quadros)
+func BenchmarkFrameSampler5h60fps(b *testing.B) {
+	points := rideTrack(5 * time.Hour)
+	sampler := NewFrameSampler(NewInterpolator(points), 60)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		samples := sampler.Sample(points[0].Time, 5*time.Hour)
+		if len(samples) != 5*3600*60 {
+			b.Fatalf("esperava %d quadros, obteve %d", 5*3600*60, len(samples))
+		}
+	}
+}
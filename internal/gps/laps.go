@@ -0,0 +1,99 @@
+package gps
+
+import (
+	"sort"
+	"time"
+)
+
+// Lap é uma volta da atividade posicionada na linha do tempo da trilha
+type Lap struct {
+	Number      int
+	Name        string
+	Start       time.Time
+	ElapsedTime time.Duration
+	Distance    float64
+}
+
+// End retorna o instante em que a volta terminou
+func (l Lap) End() time.Time {
+	return l.Start.Add(l.ElapsedTime)
+}
+
+// Split é um parcial de distância fixa (km ou milha); EndDistance é a distância acumulada da
+// atividade ao fim do parcial
+type Split struct {
+	Number      int
+	Distance    float64
+	EndDistance float64
+	ElapsedTime time.Duration
+	MovingTime  time.Duration
+}
+
+// LapStatus descreve a volta em andamento e o último parcial concluído num ponto da trilha
+type LapStatus struct {
+	Lap        Lap
+	LapCount   int
+	LapElapsed time.Duration
+	HasLap     bool
+
+	LastSplit Split
+	HasSplit  bool
+}
+
+// LapTimeline localiza voltas e parciais a partir do tempo e da distância acumulada dos pontos
+type LapTimeline struct {
+	laps   []Lap
+	splits []Split
+}
+
+// NewLapTimeline ordena as voltas pelo início e calcula a distância acumulada de cada parcial
+func NewLapTimeline(laps []Lap, splits []Split) *LapTimeline {
+	lt := &LapTimeline{
+		laps:   append([]Lap(nil), laps...),
+		splits: append([]Split(nil), splits...),
+	}
+
+	sort.SliceStable(lt.laps, func(i, j int) bool {
+		return lt.laps[i].Start.Before(lt.laps[j].Start)
+	})
+
+	total := 0.0
+	for i := range lt.splits {
+		total += lt.splits[i].Distance
+		lt.splits[i].EndDistance = total
+	}
+
+	return lt
+}
+
+// Laps retorna as voltas ordenadas por início
+func (lt *LapTimeline) Laps() []Lap {
+	return lt.laps
+}
+
+// At retorna a volta em andamento e o último parcial concluído no ponto
+func (lt *LapTimeline) At(point GPSPoint) LapStatus {
+	status := LapStatus{LapCount: len(lt.laps)}
+
+	// Última volta iniciada até o instante do ponto
+	i := sort.Search(len(lt.laps), func(k int) bool {
+		return lt.laps[k].Start.After(point.Time)
+	}) - 1
+	if i >= 0 {
+		lap := lt.laps[i]
+		status.Lap = lap
+		status.HasLap = true
+		status.LapElapsed = min(point.Time.Sub(lap.Start), lap.ElapsedTime)
+	}
+
+	// Último parcial cuja distância acumulada já foi percorrida
+	j := sort.Search(len(lt.splits), func(k int) bool {
+		return lt.splits[k].EndDistance > point.Distance
+	}) - 1
+	if j >= 0 {
+		status.LastSplit = lt.splits[j]
+		status.HasSplit = true
+	}
+
+	return status
+}
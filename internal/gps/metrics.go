@@ -0,0 +1,202 @@
+package gps
+
+import (
+	"math"
+	"time"
+)
+
+// gravity é a aceleração da gravidade padrão (m/s²), usada para converter acelerações em G
+const gravity = 9.80665
+
+// DerivedMetricsConfig controla o cálculo das acelerações derivadas da trilha
+type DerivedMetricsConfig struct {
+	// Janela (ímpar) da média móvel aplicada às acelerações; 0 ou 1 desativa
+	SmoothingWindow int
+	// Abaixo desta velocidade (m/s) o bearing é ruidoso demais e a aceleração lateral é zerada
+	MinCorneringSpeed float64
+
+	// Abaixo desta velocidade (m/s) o trecho conta como parado e não soma tempo em movimento
+	StopSpeedThreshold float64
+	// Variação mínima de altitude (m) para somar subida ou descida, filtrando ruído do altímetro
+	ClimbHysteresis float64
+	// Distância (m) centrada no ponto usada para calcular a inclinação
+	GradeWindow float64
+}
+
+// DefaultDerivedMetricsConfig retorna parâmetros adequados a trilhas de 1 Hz
+func DefaultDerivedMetricsConfig() DerivedMetricsConfig {
+	return DerivedMetricsConfig{
+		SmoothingWindow:    5,
+		MinCorneringSpeed:  2.0,
+		StopSpeedThreshold: 0.5,
+		ClimbHysteresis:    1.0,
+		GradeWindow:        50.0,
+	}
+}
+
+// ComputeAccelerations preenche LongitudinalG, LateralG e GForce de cada ponto. A componente
+// longitudinal vem da variação de velocidade e a lateral da aceleração centrípeta v·ω, onde ω é
+// a taxa de variação do bearing (equivalente a v²/r numa curva de raio r). GForce recebe a
+// magnitude combinada, calculada após a suavização das duas componentes.
+// Os pontos devem estar ordenados por tempo e com o bearing já calculado.
+func ComputeAccelerations(points []GPSPoint, cfg DerivedMetricsConfig) {
+	n := len(points)
+	if n < 3 {
+		for i := range points {
+			points[i].LongitudinalG, points[i].LateralG, points[i].GForce = 0, 0, 0
+		}
+		return
+	}
+
+	longitudinal := make([]float64, n)
+	lateral := make([]float64, n)
+
+	for i := 1; i < n-1; i++ {
+		prev, curr, next := points[i-1], points[i], points[i+1]
+
+		dt := next.Time.Sub(prev.Time).Seconds()
+		if dt <= 0 {
+			continue
+		}
+
+		longitudinal[i] = (next.Velocity - prev.Velocity) / dt / gravity
+
+		if curr.Velocity < cfg.MinCorneringSpeed {
+			continue
+		}
+
+		// O bearing de um ponto é o do segmento que chega nele; os dois segmentos ao redor de i
+		// estão separados, em média, por metade do intervalo entre os vizinhos
+		turn := bearingDelta(curr.Bearing, next.Bearing) * math.Pi / 180
+		yawRate := turn / (dt / 2)
+		lateral[i] = curr.Velocity * yawRate / gravity
+	}
+
+	// As bordas repetem o vizinho interno
+	longitudinal[0], longitudinal[n-1] = longitudinal[1], longitudinal[n-2]
+	lateral[0], lateral[n-1] = lateral[1], lateral[n-2]
+
+	longitudinal = movingAverage(longitudinal, cfg.SmoothingWindow)
+	lateral = movingAverage(lateral, cfg.SmoothingWindow)
+
+	for i := range points {
+		points[i].LongitudinalG = longitudinal[i]
+		points[i].LateralG = lateral[i]
+		points[i].GForce = math.Hypot(longitudinal[i], lateral[i])
+	}
+}
+
+// ComputeCumulativeMetrics preenche os valores acumulados desde o início da trilha (distância,
+// tempo decorrido, tempo em movimento, subida e descida) e a inclinação de cada ponto.
+// Os pontos devem estar ordenados por tempo.
+func ComputeCumulativeMetrics(points []GPSPoint, cfg DerivedMetricsConfig) {
+	if len(points) == 0 {
+		return
+	}
+
+	start := points[0].Time
+	refAltitude := points[0].Altitude
+	points[0].Distance, points[0].ElapsedTime, points[0].MovingTime = 0, 0, 0
+	points[0].Ascent, points[0].Descent = 0, 0
+
+	for i := 1; i < len(points); i++ {
+		prev := points[i-1]
+		curr := &points[i]
+
+		segment := DistanceMeters(prev, *curr)
+		dt := curr.Time.Sub(prev.Time)
+
+		curr.Distance = prev.Distance + segment
+		curr.ElapsedTime = curr.Time.Sub(start)
+		curr.MovingTime = prev.MovingTime
+		// Amostras pausadas pelo Strava nunca contam como tempo em movimento
+		if !curr.Paused && segmentSpeed(prev, *curr, segment, dt) >= cfg.StopSpeedThreshold {
+			curr.MovingTime += dt
+		}
+
+		// Só soma a variação de altitude quando ela supera a histerese em relação à referência
+		curr.Ascent, curr.Descent = prev.Ascent, prev.Descent
+		if climb := curr.Altitude - refAltitude; climb >= cfg.ClimbHysteresis {
+			curr.Ascent += climb
+			refAltitude = curr.Altitude
+		} else if -climb >= cfg.ClimbHysteresis {
+			curr.Descent -= climb
+			refAltitude = curr.Altitude
+		}
+	}
+
+	computeGrade(points, cfg.GradeWindow)
+}
+
+// segmentSpeed usa a velocidade do stream quando disponível e, sem ela, a distância percorrida
+func segmentSpeed(p1, p2 GPSPoint, segment float64, dt time.Duration) float64 {
+	if p1.Velocity > 0 || p2.Velocity > 0 {
+		return (p1.Velocity + p2.Velocity) / 2
+	}
+	if dt <= 0 {
+		return 0
+	}
+	return segment / dt.Seconds()
+}
+
+// computeGrade calcula a inclinação (%) pela variação de altitude numa janela de distância
+// centrada em cada ponto. Sem deslocamento suficiente (paradas) mantém a inclinação anterior.
+func computeGrade(points []GPSPoint, window float64) {
+	if window <= 0 {
+		window = DefaultDerivedMetricsConfig().GradeWindow
+	}
+	half := window / 2
+
+	lo, hi := 0, 0
+	for i := range points {
+		for points[i].Distance-points[lo].Distance > half {
+			lo++
+		}
+		for hi < len(points)-1 && points[hi+1].Distance-points[i].Distance <= half {
+			hi++
+		}
+
+		run := points[hi].Distance - points[lo].Distance
+		switch {
+		case run >= half:
+			points[i].Grade = (points[hi].Altitude - points[lo].Altitude) / run * 100
+		case i > 0:
+			points[i].Grade = points[i-1].Grade
+		default:
+			points[i].Grade = 0
+		}
+	}
+}
+
+// bearingDelta retorna a variação de from para to pelo menor arco, em graus (-180, 180].
+// Valores positivos indicam curva à direita.
+func bearingDelta(from, to float64) float64 {
+	delta := math.Mod(to-from, 360)
+	if delta > 180 {
+		delta -= 360
+	} else if delta <= -180 {
+		delta += 360
+	}
+	return delta
+}
+
+// movingAverage aplica uma média móvel centrada, encolhendo a janela nas bordas
+func movingAverage(values []float64, window int) []float64 {
+	if window < 2 || len(values) < 2 {
+		return values
+	}
+	half := window / 2
+
+	smoothed := make([]float64, len(values))
+	for i := range values {
+		lo := max(0, i-half)
+		hi := min(len(values)-1, i+half)
+
+		sum := 0.0
+		for j := lo; j <= hi; j++ {
+			sum += values[j]
+		}
+		smoothed[i] = sum / float64(hi-lo+1)
+	}
+	return smoothed
+}
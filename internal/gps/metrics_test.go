@@ -0,0 +1,236 @@
+package gps
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// circularTrack gera voltas de 1 Hz em um círculo de raio radius (m) a velocidade constante,
+// com o bearing calculado como em ProcessStreamData (segmento que chega em cada ponto)
+func circularTrack(radius, speed float64, laps int, clockwise bool) []GPSPoint {
+	start := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	metersPerDegLng := metersPerDegLat * math.Cos(testLat*math.Pi/180)
+	omega := speed / radius
+	if clockwise {
+		omega = -omega
+	}
+
+	n := int(float64(laps)*2*math.Pi*radius/speed) + 1
+	points := make([]GPSPoint, n)
+	for i := range points {
+		theta := omega * float64(i)
+		points[i] = GPSPoint{
+			Time:     start.Add(time.Duration(i) * time.Second),
+			Lat:      testLat + radius*math.Sin(theta)/metersPerDegLat,
+			Lng:      testLng + radius*math.Cos(theta)/metersPerDegLng,
+			Velocity: speed,
+		}
+	}
+
+	gp := &GPSProcessor{}
+	for i := 1; i < n; i++ {
+		points[i].Bearing = gp.calculateBearing(points[i-1], points[i])
+	}
+	points[0].Bearing = points[1].Bearing
+	return points
+}
+
+func TestComputeAccelerationsCircularTrack(t *testing.T) {
+	const radius, speed = 40.0, 8.0
+	want := speed * speed / (radius * gravity)
+	cfg := DefaultDerivedMetricsConfig()
+
+	tests := []struct {
+		name      string
+		clockwise bool
+		sign      float64
+	}{
+		{"sentido horário (curva à direita)", true, 1},
+		{"sentido anti-horário (curva à esquerda)", false, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := circularTrack(radius, speed, 3, tt.clockwise)
+			ComputeAccelerations(points, cfg)
+
+			// As bordas repetem o vizinho interno e a média móvel encolhe a janela nelas
+			for i := cfg.SmoothingWindow; i < len(points)-cfg.SmoothingWindow; i++ {
+				p := points[i]
+				if math.Abs(p.LateralG-tt.sign*want) > 0.02*want {
+					t.Fatalf("ponto %d: G lateral %.4f, esperado %.4f (v²/(r·g))", i, p.LateralG, tt.sign*want)
+				}
+				if math.Abs(p.LongitudinalG) > 1e-9 {
+					t.Fatalf("ponto %d: G longitudinal %.4f em velocidade constante", i, p.LongitudinalG)
+				}
+				if math.Abs(p.GForce-want) > 0.02*want {
+					t.Fatalf("ponto %d: G combinado %.4f, esperado %.4f", i, p.GForce, want)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeAccelerationsStraightAcceleration(t *testing.T) {
+	const accel = 2.0 // m/s²
+	points := straightTrack(30, 0)
+	for i := range points {
+		points[i].Velocity = 5 + accel*float64(i)
+		points[i].Bearing = 90
+	}
+
+	ComputeAccelerations(points, DefaultDerivedMetricsConfig())
+
+	want := accel / gravity
+	for i, p := range points {
+		if math.Abs(p.LongitudinalG-want) > 1e-9 {
+			t.Fatalf("ponto %d: G longitudinal %.4f, esperado %.4f", i, p.LongitudinalG, want)
+		}
+		if p.LateralG != 0 {
+			t.Fatalf("ponto %d: G lateral %.4f em linha reta", i, p.LateralG)
+		}
+	}
+}
+
+func TestComputeAccelerationsIgnoresSlowCornering(t *testing.T) {
+	cfg := DefaultDerivedMetricsConfig()
+	points := circularTrack(5, cfg.MinCorneringSpeed/2, 2, true)
+
+	ComputeAccelerations(points, cfg)
+
+	for i, p := range points {
+		if p.LateralG != 0 {
+			t.Fatalf("ponto %d: G lateral %.4f abaixo da velocidade mínima de curva", i, p.LateralG)
+		}
+	}
+}
+
+// trackStep descreve count amostras seguidas, cada uma dt depois da anterior e meters a leste
+type trackStep struct {
+	count    int
+	dt       time.Duration
+	meters   float64
+	velocity float64
+	climb    float64 // Variação de altitude (m) por amostra
+	paused   bool
+}
+
+// pathTrack monta uma trilha rumo ao leste a partir dos trechos, começando na origem a 700 m
+func pathTrack(steps ...trackStep) []GPSPoint {
+	metersPerDegLng := metersPerDegLat * math.Cos(testLat*math.Pi/180)
+	point := GPSPoint{
+		Time:     time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC),
+		Lat:      testLat,
+		Lng:      testLng,
+		Velocity: steps[0].velocity,
+		Altitude: 700,
+	}
+
+	points := []GPSPoint{point}
+	for _, step := range steps {
+		for k := 0; k < step.count; k++ {
+			point.Time = point.Time.Add(step.dt)
+			point.Lng += step.meters / metersPerDegLng
+			point.Velocity = step.velocity
+			point.Altitude += step.climb
+			point.Paused = step.paused
+			points = append(points, point)
+		}
+	}
+	return points
+}
+
+func TestComputeCumulativeMetricsDistanceAndTime(t *testing.T) {
+	points := pathTrack(
+		trackStep{count: 20, dt: time.Second, meters: 10, velocity: 10},
+		trackStep{count: 19, dt: time.Second}, // Parado no semáforo
+		trackStep{count: 20, dt: time.Second, meters: 10, velocity: 10},
+		trackStep{count: 5, dt: time.Second, meters: 10, velocity: 10, paused: true}, // Pausado pelo Strava
+		trackStep{count: 36, dt: time.Second, meters: 10, velocity: 10},
+	)
+
+	ComputeCumulativeMetrics(points, DefaultDerivedMetricsConfig())
+
+	last := points[len(points)-1]
+	// 81 amostras de 10 m; o haversine fica ~0,1% abaixo da projeção usada para montar a trilha
+	if !nearlyEqual(last.Distance, 810, 810*0.002) {
+		t.Errorf("distância total %.1f m, esperado 810 m", last.Distance)
+	}
+	if last.ElapsedTime != 100*time.Second {
+		t.Errorf("tempo decorrido %v, esperado 100s", last.ElapsedTime)
+	}
+	// Os intervalos que chegam à parada e saem dela contam pela velocidade média; os 18
+	// intervalos parados e os 5 pausados não
+	if last.MovingTime != 77*time.Second {
+		t.Errorf("tempo em movimento %v, esperado 77s", last.MovingTime)
+	}
+
+	for i := 1; i < len(points); i++ {
+		if points[i].Distance < points[i-1].Distance || points[i].MovingTime < points[i-1].MovingTime {
+			t.Fatalf("ponto %d: valores acumulados diminuíram", i)
+		}
+		if points[i].ElapsedTime != time.Duration(i)*time.Second {
+			t.Fatalf("ponto %d: tempo decorrido %v", i, points[i].ElapsedTime)
+		}
+	}
+	if points[30].Distance != points[20].Distance {
+		t.Errorf("a distância mudou durante a parada: %.1f -> %.1f m", points[20].Distance, points[30].Distance)
+	}
+}
+
+func TestComputeCumulativeMetricsClimbing(t *testing.T) {
+	steps := []trackStep{
+		{count: 10, dt: time.Second, meters: 10, velocity: 10, climb: 0.5}, // 700 -> 705 m
+		{count: 1, dt: time.Second, meters: 10, velocity: 10, climb: 0.4},
+	}
+	// Ruído do altímetro de ±0,4 m em torno de 705 m, abaixo da histerese de 1 m
+	for k := 0; k < 9; k++ {
+		steps = append(steps, trackStep{count: 1, dt: time.Second, meters: 10, velocity: 10, climb: []float64{-0.8, 0.8}[k%2]})
+	}
+	steps = append(steps, trackStep{count: 5, dt: time.Second, meters: 10, velocity: 10, climb: -1}) // 704,6 -> 699,6 m
+	points := pathTrack(steps...)
+
+	ComputeCumulativeMetrics(points, DefaultDerivedMetricsConfig())
+
+	last := points[len(points)-1]
+	if !nearlyEqual(last.Ascent, 5, 1e-9) {
+		t.Errorf("subida acumulada %.2f m, esperado 5 m", last.Ascent)
+	}
+	// A descida é medida a partir da última referência (705 m), não do ruído
+	if !nearlyEqual(last.Descent, 5.4, 1e-9) {
+		t.Errorf("descida acumulada %.2f m, esperado 5,4 m", last.Descent)
+	}
+	for i := 11; i <= 20; i++ {
+		if points[i].Ascent != points[10].Ascent || points[i].Descent != 0 {
+			t.Fatalf("ponto %d: o ruído do altímetro alterou subida/descida (%.2f/%.2f)", i, points[i].Ascent, points[i].Descent)
+		}
+	}
+}
+
+func TestComputeCumulativeMetricsGrade(t *testing.T) {
+	points := pathTrack(
+		trackStep{count: 50, dt: time.Second, meters: 10, velocity: 10, climb: 0.5},  // 5%
+		trackStep{count: 20, dt: time.Second},                                        // Parado no topo
+		trackStep{count: 50, dt: time.Second, meters: 10, velocity: 10, climb: -0.8}, // -8%
+	)
+
+	ComputeCumulativeMetrics(points, DefaultDerivedMetricsConfig())
+
+	tests := []struct {
+		name     string
+		from, to int
+		want     float64
+	}{
+		{"subida", 5, 45, 5},
+		{"parado mantém a inclinação anterior", 51, 70, points[50].Grade},
+		{"descida", 76, 115, -8},
+	}
+	for _, tt := range tests {
+		for i := tt.from; i <= tt.to; i++ {
+			if !nearlyEqual(points[i].Grade, tt.want, 0.05) {
+				t.Fatalf("%s: ponto %d com inclinação %.2f%%, esperado %.2f%%", tt.name, i, points[i].Grade, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package gps_test
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"strava-overlay/internal/gps"
+	"strava-overlay/internal/strava"
+)
+
+// As fixtures descrevem o mesmo pedal de 30 minutos: parada de 90 s aos 600 s (moving falso),
+// pausa automática de 899 s a 1200 s sem deslocamento e perda de sinal em movimento de 1499 s
+// a 1560 s. streams_original.json vem em 1 Hz no formato key_by_type; streams_low.json tem as
+// 100 amostras da resolução baixa no formato de lista.
+var fixtureStart = time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+
+func loadFixture(t *testing.T, name string) *strava.StreamSet {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("abrindo a fixture: %v", err)
+	}
+	defer f.Close()
+
+	streams, err := strava.DecodeStreams(f)
+	if err != nil {
+		t.Fatalf("DecodeStreams(%s): %v", name, err)
+	}
+	return streams
+}
+
+func streamData(streams *strava.StreamSet) gps.StreamData {
+	data := gps.StreamData{Time: streams.Time.Data, LatLng: streams.LatLng.Data}
+	if streams.Velocity != nil {
+		data.Velocity = streams.Velocity.Data
+	}
+	if streams.Altitude != nil {
+		data.Altitude = streams.Altitude.Data
+	}
+	if streams.Moving != nil {
+		data.Moving = streams.Moving.Data
+	}
+	if streams.HeartRate != nil {
+		data.HeartRate = streams.HeartRate.Data
+	}
+	return data
+}
+
+func TestStreamFixturesPipeline(t *testing.T) {
+	previous := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(previous) })
+
+	type expectedSegment struct {
+		kind  gps.SegmentType
+		start time.Duration
+		end   time.Duration
+	}
+	want := []expectedSegment{
+		{gps.SegmentStop, 600 * time.Second, 690 * time.Second},
+		{gps.SegmentAutoPause, 899 * time.Second, 1200 * time.Second},
+		{gps.SegmentGap, 1499 * time.Second, 1560 * time.Second},
+	}
+
+	tests := []struct {
+		fixture    string
+		resolution string
+		samples    int
+		tolerance  time.Duration // Espaçamento das amostras nas pontas dos trechos
+	}{
+		{"streams_original.json", "high", 1440, time.Second},
+		{"streams_low.json", "low", 100, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resolution, func(t *testing.T) {
+			streams := loadFixture(t, tt.fixture)
+			if streams.Len() != tt.samples || streams.Resolution() != tt.resolution {
+				t.Fatalf("fixture com %d amostras em %q, esperado %d em %q", streams.Len(), streams.Resolution(), tt.samples, tt.resolution)
+			}
+			if streams.Moving == nil {
+				t.Fatal("a fixture deve trazer o stream moving")
+			}
+
+			processor := gps.NewGPSProcessor()
+			if err := processor.ProcessStreamData(streamData(streams), fixtureStart); err != nil {
+				t.Fatalf("ProcessStreamData: %v", err)
+			}
+
+			// A trilha é interpolada a cada segundo nas duas resoluções
+			points := processor.GetAllPoints()
+			if got := points[len(points)-1].Time.Sub(points[0].Time); got < 29*time.Minute {
+				t.Errorf("trilha interpolada cobre só %v", got)
+			}
+
+			segments := processor.Segments()
+			if len(segments) != len(want) {
+				for _, s := range segments {
+					t.Logf("%s: %v a %v", s.Type, s.Start.Sub(fixtureStart), s.End.Sub(fixtureStart))
+				}
+				t.Fatalf("esperava %d trechos, detectou %d", len(want), len(segments))
+			}
+			for i, w := range want {
+				got := segments[i]
+				if got.Type != w.kind {
+					t.Errorf("trecho %d: tipo %s, esperado %s", i, got.Type, w.kind)
+				}
+				if d := absDuration(got.Start.Sub(fixtureStart) - w.start); d > tt.tolerance {
+					t.Errorf("trecho %d (%s): começa em %v, esperado %v", i, got.Type, got.Start.Sub(fixtureStart), w.start)
+				}
+				if d := absDuration(got.End.Sub(fixtureStart) - w.end); d > tt.tolerance {
+					t.Errorf("trecho %d (%s): termina em %v, esperado %v", i, got.Type, got.End.Sub(fixtureStart), w.end)
+				}
+			}
+
+			// O stream moving marca os pontos da parada como pausados
+			stopped, ok := processor.GetPointForTime(fixtureStart.Add(645 * time.Second))
+			if !ok || !stopped.Paused {
+				t.Errorf("ponto no meio da parada deveria estar pausado: %+v", stopped)
+			}
+			riding, ok := processor.GetPointForTime(fixtureStart.Add(300 * time.Second))
+			if !ok || riding.Paused {
+				t.Errorf("ponto em movimento não deveria estar pausado: %+v", riding)
+			}
+		})
+	}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
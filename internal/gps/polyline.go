@@ -0,0 +1,106 @@
+package gps
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// polylinePrecision é o fator do formato de polilinha do Google com 5 casas decimais, usado
+// pelo Strava em map.polyline e map.summary_polyline
+const polylinePrecision = 1e5
+
+// DecodePolyline decodifica uma polilinha no formato do Google em pares [lat, lng]
+func DecodePolyline(encoded string) ([][2]float64, error) {
+	coords := make([][2]float64, 0, len(encoded)/4)
+
+	var lat, lng int64
+	for i := 0; i < len(encoded); {
+		dLat, next, err := decodePolylineValue(encoded, i)
+		if err != nil {
+			return nil, err
+		}
+		dLng, next, err := decodePolylineValue(encoded, next)
+		if err != nil {
+			return nil, err
+		}
+		i = next
+
+		lat += dLat
+		lng += dLng
+		coords = append(coords, [2]float64{
+			float64(lat) / polylinePrecision,
+			float64(lng) / polylinePrecision,
+		})
+	}
+
+	return coords, nil
+}
+
+// decodePolylineValue lê um valor com sinal a partir de start e retorna o índice seguinte
+func decodePolylineValue(encoded string, start int) (int64, int, error) {
+	var result int64
+	var shift uint
+
+	for i := start; i < len(encoded); i++ {
+		b := int64(encoded[i]) - 63
+		if b < 0 || b > 0x3f {
+			return 0, 0, fmt.Errorf("caractere inválido na polilinha na posição %d", i)
+		}
+		if shift > 60 {
+			return 0, 0, fmt.Errorf("valor muito longo na polilinha na posição %d", i)
+		}
+
+		result |= (b & 0x1f) << shift
+		shift += 5
+
+		if b < 0x20 {
+			if result&1 != 0 {
+				return ^(result >> 1), i + 1, nil
+			}
+			return result >> 1, i + 1, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("polilinha truncada na posição %d", len(encoded))
+}
+
+// EncodePolyline codifica pares [lat, lng] no formato de polilinha do Google
+func EncodePolyline(coords [][2]float64) string {
+	var sb strings.Builder
+	sb.Grow(len(coords) * 8)
+
+	var prevLat, prevLng int64
+	for _, coord := range coords {
+		lat := int64(math.Round(coord[0] * polylinePrecision))
+		lng := int64(math.Round(coord[1] * polylinePrecision))
+
+		encodePolylineValue(&sb, lat-prevLat)
+		encodePolylineValue(&sb, lng-prevLng)
+		prevLat, prevLng = lat, lng
+	}
+
+	return sb.String()
+}
+
+// EncodePointsPolyline codifica a posição dos pontos como polilinha
+func EncodePointsPolyline(points []GPSPoint) string {
+	coords := make([][2]float64, len(points))
+	for i, point := range points {
+		coords[i] = [2]float64{point.Lat, point.Lng}
+	}
+	return EncodePolyline(coords)
+}
+
+func encodePolylineValue(sb *strings.Builder, value int64) {
+	v := value << 1
+	if value < 0 {
+		v = ^v
+	}
+
+	for v >= 0x20 {
+		sb.WriteByte(byte((0x20 | (v & 0x1f)) + 63))
+		v >>= 5
+	}
+	sb.WriteByte(byte(v + 63))
+}
@@ -0,0 +1,106 @@
+package gps
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// quantize arredonda a coordenada para a precisão da polilinha
+func quantize(v float64) float64 {
+	return math.Round(v*polylinePrecision) / polylinePrecision
+}
+
+// polylineCoords gera trilhas aleatórias válidas para testing/quick
+type polylineCoords [][2]float64
+
+func (polylineCoords) Generate(rng *rand.Rand, size int) reflect.Value {
+	coords := make(polylineCoords, rng.Intn(size+1))
+	for i := range coords {
+		coords[i] = [2]float64{
+			quantize(rng.Float64()*180 - 90),
+			quantize(rng.Float64()*360 - 180),
+		}
+	}
+	return reflect.ValueOf(coords)
+}
+
+func TestPolylineKnownValue(t *testing.T) {
+	// Exemplo da documentação do formato de polilinha do Google
+	coords := [][2]float64{{38.5, -120.2}, {40.7, -120.95}, {43.252, -126.453}}
+	const encoded = "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+
+	if got := EncodePolyline(coords); got != encoded {
+		t.Errorf("EncodePolyline = %q, esperado %q", got, encoded)
+	}
+	decoded, err := DecodePolyline(encoded)
+	if err != nil {
+		t.Fatalf("DecodePolyline: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, coords) {
+		t.Errorf("DecodePolyline = %v, esperado %v", decoded, coords)
+	}
+}
+
+func TestPolylineRoundTrip(t *testing.T) {
+	roundTrip := func(coords polylineCoords) bool {
+		decoded, err := DecodePolyline(EncodePolyline(coords))
+		if err != nil {
+			return false
+		}
+		return len(decoded) == len(coords) && (len(coords) == 0 || reflect.DeepEqual([][2]float64(coords), decoded))
+	}
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPolylineReencodeIsStable(t *testing.T) {
+	// Decodificar e recodificar uma polilinha canônica devolve a mesma string
+	stable := func(coords polylineCoords) bool {
+		encoded := EncodePolyline(coords)
+		decoded, err := DecodePolyline(encoded)
+		return err == nil && EncodePolyline(decoded) == encoded
+	}
+	if err := quick.Check(stable, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecodePolylineRejectsInvalid(t *testing.T) {
+	for _, encoded := range []string{"_p~iF~ps|U_", "_p~iF", "abc def", "\x01"} {
+		if _, err := DecodePolyline(encoded); err == nil {
+			t.Errorf("DecodePolyline(%q) deveria falhar", encoded)
+		}
+	}
+}
+
+func FuzzDecodePolyline(f *testing.F) {
+	f.Add("_p~iF~ps|U_ulLnnqC_mqNvxq`@")
+	f.Add("")
+	f.Add("??")
+	f.Add("~~~~~~~~~~~~~~")
+
+	f.Fuzz(func(t *testing.T, encoded string) {
+		coords, err := DecodePolyline(encoded)
+		if err != nil {
+			return
+		}
+		for _, c := range coords {
+			// Fora da faixa de coordenadas o float64 já não representa cada inteiro exatamente
+			if math.Abs(c[0]) > 1e6 || math.Abs(c[1]) > 1e6 {
+				return
+			}
+		}
+
+		decoded, err := DecodePolyline(EncodePolyline(coords))
+		if err != nil {
+			t.Fatalf("a polilinha recodificada não decodifica: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, coords) {
+			t.Fatalf("ida e volta alterou os pontos: %v != %v", decoded, coords)
+		}
+	})
+}
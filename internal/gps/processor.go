@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"log"
 	"math"
-	"sync"
+	"sort"
 	"time"
 )
 
@@ -15,243 +15,63 @@ type GPSPoint struct {
 	Velocity float64
 	Altitude float64
 	Bearing  float64
-	GForce   float64
-}
-
-type GPSProcessor struct {
-	points    []GPSPoint
-	pointsMap map[int64]GPSPoint // Cache por timestamp para busca rápida
-	mutex     sync.RWMutex
-	cached    bool
-}
-
-func NewGPSProcessor() *GPSProcessor {
-	return &GPSProcessor{}
-}
-
-func (gp *GPSProcessor) ProcessStreamDataOptimized(timeData, latlngData, velocityData, altitudeData []interface{}, startTime time.Time) error {
-	gp.mutex.Lock()
-	defer gp.mutex.Unlock()
-
-	if len(timeData) != len(latlngData) {
-		return fmt.Errorf("data length mismatch: time=%d, latlng=%d", len(timeData), len(latlngData))
-	}
-
-	// Pré-aloca slices para melhor performance
-	rawPoints := make([]GPSPoint, 0, len(timeData))
-	gp.pointsMap = make(map[int64]GPSPoint, len(timeData)*2) // Estima para pontos interpolados
-
-	// Worker pool para processamento paralelo
-	numWorkers := 4
-	jobs := make(chan int, len(timeData))
-	results := make(chan GPSPoint, len(timeData))
-	var wg sync.WaitGroup
-
-	// Inicia workers
-	for w := 0; w < numWorkers; w++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for i := range jobs {
-				point := gp.processGPSPoint(i, timeData, latlngData, velocityData, altitudeData, startTime)
-				if point.Lat != 0 && point.Lng != 0 {
-					results <- point
-				}
-			}
-		}()
-	}
-
-	// Envia jobs
-	go func() {
-		for i := 0; i < len(timeData); i++ {
-			jobs <- i
-		}
-		close(jobs)
-	}()
-
-	// Coleta resultados
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// Ordena pontos por timestamp
-	pointMap := make(map[time.Time]GPSPoint)
-	for point := range results {
-		pointMap[point.Time] = point
-	}
-
-	// Converte para slice ordenado
-	for _, point := range pointMap {
-		rawPoints = append(rawPoints, point)
-	}
-
-	if len(rawPoints) == 0 {
-		return fmt.Errorf("nenhum ponto GPS válido encontrado")
-	}
-
-	// Calcula bearing e G-force sequencialmente (depende da ordem)
-	gp.calculateDerivedValues(rawPoints)
+	GForce   float64 // Magnitude combinada das acelerações longitudinal e lateral
 
-	// Interpola pontos
-	gp.points = gp.interpolatePointsOptimized(rawPoints)
-
-	// Cria cache de busca rápida
-	gp.buildTimeCache()
-	gp.cached = true
-
-	log.Printf("DEBUG: %d pontos GPS processados, %d após interpolação", len(rawPoints), len(gp.points))
-	return nil
-}
-
-func (gp *GPSProcessor) processGPSPoint(i int, timeData, latlngData, velocityData, altitudeData []interface{}, startTime time.Time) GPSPoint {
-	timeOffset, ok := timeData[i].(float64)
-	if !ok {
-		return GPSPoint{}
-	}
-
-	latlngInterface, ok := latlngData[i].([]interface{})
-	if !ok || len(latlngInterface) != 2 {
-		return GPSPoint{}
-	}
-
-	lat, ok1 := latlngInterface[0].(float64)
-	lng, ok2 := latlngInterface[1].(float64)
-
-	if !ok1 || !ok2 || !gp.isValidCoordinate(lat, lng) {
-		return GPSPoint{}
-	}
-
-	point := GPSPoint{
-		Time: startTime.Add(time.Duration(timeOffset) * time.Second),
-		Lat:  lat,
-		Lng:  lng,
-	}
-
-	// Adiciona dados opcionais
-	if velocityData != nil && i < len(velocityData) && velocityData[i] != nil {
-		if vel, ok := velocityData[i].(float64); ok && !math.IsNaN(vel) {
-			point.Velocity = vel
-		}
-	}
-
-	if altitudeData != nil && i < len(altitudeData) && altitudeData[i] != nil {
-		if alt, ok := altitudeData[i].(float64); ok && !math.IsNaN(alt) {
-			point.Altitude = alt
-		}
-	}
+	LongitudinalG float64 // Positivo acelerando, negativo freando
+	LateralG      float64 // Positivo em curvas à direita
 
-	return point
-}
+	// Valores acumulados desde o início da atividade
+	Distance    float64       // Metros percorridos
+	ElapsedTime time.Duration // Tempo total decorrido
+	MovingTime  time.Duration // Tempo em movimento, excluindo paradas
+	Ascent      float64       // Subida acumulada em metros
+	Descent     float64       // Descida acumulada em metros
+	Grade       float64       // Inclinação atual em %
 
-func (gp *GPSProcessor) isValidCoordinate(lat, lng float64) bool {
-	return !(lat == 0 && lng == 0) &&
-		lat >= -90 && lat <= 90 &&
-		lng >= -180 && lng <= 180 &&
-		!math.IsNaN(lat) && !math.IsNaN(lng)
+	Paused    bool    // Strava marcou a amostra como parada (stream "moving" falso)
+	HeartRate float64 // Frequência cardíaca em bpm, 0 quando a atividade não tem o stream
 }
 
-func (gp *GPSProcessor) buildTimeCache() {
-	for _, point := range gp.points {
-		timestamp := point.Time.Unix()
-		gp.pointsMap[timestamp] = point
-	}
+type GPSProcessor struct {
+	points   []GPSPoint    // Trilha interpolada a cada segundo, ordenada por tempo
+	samples  []GPSPoint    // Pontos filtrados antes da interpolação de 1 segundo
+	index    *SpatialIndex // Índice em grade para busca por coordenadas
+	filters  FilterChain
+	metrics  DerivedMetricsConfig
+	segments SegmentConfig
 }
 
-// GetPointForTimeOptimized - busca otimizada usando cache
-func (gp *GPSProcessor) GetPointForTimeOptimized(targetTime time.Time) (GPSPoint, bool) {
-	gp.mutex.RLock()
-	defer gp.mutex.RUnlock()
-
-	if !gp.cached {
-		return gp.GetPointForTime(targetTime) // Fallback para método original
-	}
-
-	targetTimestamp := targetTime.Unix()
-
-	// Busca exata primeiro
-	if point, exists := gp.pointsMap[targetTimestamp]; exists {
-		return point, true
-	}
-
-	// Busca aproximada (±5 segundos)
-	for offset := int64(1); offset <= 5; offset++ {
-		if point, exists := gp.pointsMap[targetTimestamp+offset]; exists {
-			return point, true
-		}
-		if point, exists := gp.pointsMap[targetTimestamp-offset]; exists {
-			return point, true
-		}
+func NewGPSProcessor() *GPSProcessor {
+	return &GPSProcessor{
+		filters:  NewFilterChain(DefaultFilterConfig()),
+		metrics:  DefaultDerivedMetricsConfig(),
+		segments: DefaultSegmentConfig(),
 	}
-
-	// Fallback para busca linear
-	return gp.GetPointForTime(targetTime)
 }
 
-// Spatial index para busca geográfica otimizada
-type SpatialIndex struct {
-	cells    map[string][]int // grid_key -> point_indices
-	points   []GPSPoint
-	cellSize float64
+// SetDerivedMetricsConfig altera os parâmetros do cálculo de G-force
+func (gp *GPSProcessor) SetDerivedMetricsConfig(cfg DerivedMetricsConfig) {
+	gp.metrics = cfg
 }
 
-func (gp *GPSProcessor) buildSpatialIndex() *SpatialIndex {
-	si := &SpatialIndex{
-		cells:    make(map[string][]int),
-		points:   gp.points,
-		cellSize: 0.001, // ~100m resolution
-	}
-
-	for i, point := range gp.points {
-		key := si.getCellKey(point.Lat, point.Lng)
-		si.cells[key] = append(si.cells[key], i)
-	}
-
-	return si
+// SetSegmentConfig altera os parâmetros da detecção de paradas e lacunas
+func (gp *GPSProcessor) SetSegmentConfig(cfg SegmentConfig) {
+	gp.segments = cfg
 }
 
-func (si *SpatialIndex) getCellKey(lat, lng float64) string {
-	cellLat := math.Floor(lat / si.cellSize)
-	cellLng := math.Floor(lng / si.cellSize)
-	return fmt.Sprintf("%.0f,%.0f", cellLat, cellLng)
+// SetFilterChain substitui os filtros aplicados à trilha bruta (nil desativa a filtragem)
+func (gp *GPSProcessor) SetFilterChain(filters FilterChain) {
+	gp.filters = filters
 }
 
-func (si *SpatialIndex) findNearestPoint(targetLat, targetLng float64) (GPSPoint, bool) {
-	key := si.getCellKey(targetLat, targetLng)
-
-	// Busca na célula atual e adjacentes
-	searchKeys := []string{key}
-	for dlat := -1; dlat <= 1; dlat++ {
-		for dlng := -1; dlng <= 1; dlng++ {
-			if dlat == 0 && dlng == 0 {
-				continue
-			}
-			adjKey := fmt.Sprintf("%.0f,%.0f",
-				math.Floor(targetLat/si.cellSize)+float64(dlat),
-				math.Floor(targetLng/si.cellSize)+float64(dlng))
-			searchKeys = append(searchKeys, adjKey)
-		}
-	}
-
-	var closestPoint GPSPoint
-	minDist := math.MaxFloat64
-	found := false
-
-	for _, searchKey := range searchKeys {
-		if indices, exists := si.cells[searchKey]; exists {
-			for _, idx := range indices {
-				point := si.points[idx]
-				dist := haversineDistance(GPSPoint{Lat: targetLat, Lng: targetLng}, point)
-				if dist < minDist {
-					minDist = dist
-					closestPoint = point
-					found = true
-				}
-			}
-		}
-	}
-
-	return closestPoint, found
+// interpolateCumulative interpola os valores acumulados e a inclinação entre p1 e p2
+func interpolateCumulative(point *GPSPoint, p1, p2 GPSPoint, ratio float64) {
+	point.Distance = lerp(p1.Distance, p2.Distance, ratio)
+	point.ElapsedTime = p1.ElapsedTime + point.Time.Sub(p1.Time)
+	point.MovingTime = p1.MovingTime + time.Duration(ratio*float64(p2.MovingTime-p1.MovingTime))
+	point.Ascent = lerp(p1.Ascent, p2.Ascent, ratio)
+	point.Descent = lerp(p1.Descent, p2.Descent, ratio)
+	point.Grade = lerp(p1.Grade, p2.Grade, ratio)
 }
 
 // calculateDerivedValues calcula bearing e G-force para os pontos - VERSÃO MELHORADA
@@ -265,7 +85,7 @@ func (gp *GPSProcessor) calculateDerivedValues(points []GPSPoint) {
 		currentPoint := &points[i]
 
 		// Calcula bearing apenas se há movimento significativo
-		distance := gp.calculateDistanceBetweenPoints(prevPoint, *currentPoint)
+		distance := DistanceMeters(prevPoint, *currentPoint)
 		if distance > 1.0 { // Só calcula bearing se moveu mais de 1 metro
 			currentPoint.Bearing = gp.calculateBearing(prevPoint, *currentPoint)
 		} else {
@@ -273,99 +93,20 @@ func (gp *GPSProcessor) calculateDerivedValues(points []GPSPoint) {
 			currentPoint.Bearing = prevPoint.Bearing
 		}
 
-		// Calcula G-force
-		currentPoint.GForce = gp.calculateGForce(prevPoint, *currentPoint)
-	}
-
-	// O primeiro ponto herda os valores do segundo (se existir)
-	if len(points) > 1 {
-		points[0].Bearing = points[1].Bearing
-		points[0].GForce = points[1].GForce
-	}
-}
-
-// calculateDistanceBetweenPoints calcula distância entre dois pontos GPS
-func (gp *GPSProcessor) calculateDistanceBetweenPoints(p1, p2 GPSPoint) float64 {
-	const R = 6371000 // Raio da Terra em metros
-
-	lat1Rad := p1.Lat * math.Pi / 180
-	lon1Rad := p1.Lng * math.Pi / 180
-	lat2Rad := p2.Lat * math.Pi / 180
-	lon2Rad := p2.Lng * math.Pi / 180
-
-	dLat := lat2Rad - lat1Rad
-	dLon := lon2Rad - lon1Rad
-
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1Rad)*math.Cos(lat2Rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-
-	return R * c
-}
-
-// interpolatePointsOptimized - versão otimizada da interpolação
-func (gp *GPSProcessor) interpolatePointsOptimized(points []GPSPoint) []GPSPoint {
-	if len(points) < 2 {
-		return points
 	}
 
-	var interpolated []GPSPoint
-
-	// Pre-aloca com estimativa de tamanho
-	estimatedSize := len(points) * 2 // Estimativa conservadora
-	interpolated = make([]GPSPoint, 0, estimatedSize)
+	// O primeiro ponto herda o bearing do segundo
+	points[0].Bearing = points[1].Bearing
 
-	for i := 0; i < len(points)-1; i++ {
-		p1 := points[i]
-		p2 := points[i+1]
-
-		t1 := p1.Time
-		t2 := p2.Time
-		duration := t2.Sub(t1)
-
-		// Adiciona o ponto inicial do intervalo
-		interpolated = append(interpolated, p1)
-
-		// Se o intervalo for maior que 1 segundo, interpola
-		if duration.Seconds() > 1.0 {
-			// Calcula quantos pontos intermediários são necessários
-			numInterpolated := int(duration.Seconds()) - 1
-			if numInterpolated > 60 { // Limita para evitar muitos pontos
-				numInterpolated = 60
-			}
-
-			for j := 1; j <= numInterpolated; j++ {
-				ratio := float64(j) / float64(numInterpolated+1)
-
-				lat := p1.Lat + ratio*(p2.Lat-p1.Lat)
-				lng := p1.Lng + ratio*(p2.Lng-p1.Lng)
-				altitude := p1.Altitude + ratio*(p2.Altitude-p1.Altitude)
-				velocity := p1.Velocity + ratio*(p2.Velocity-p1.Velocity)
-
-				// Interpolação linear para bearing (considerando wraparound)
-				bearing := gp.interpolateBearing(p1.Bearing, p2.Bearing, ratio)
-				gForce := p1.GForce + ratio*(p2.GForce-p1.GForce)
-
-				newPoint := GPSPoint{
-					Time:     t1.Add(time.Duration(float64(duration) * ratio)),
-					Lat:      lat,
-					Lng:      lng,
-					Altitude: altitude,
-					Velocity: velocity,
-					Bearing:  bearing,
-					GForce:   gForce,
-				}
-				interpolated = append(interpolated, newPoint)
-			}
-		}
-	}
+	// G-force longitudinal e lateral a partir da velocidade e da curvatura
+	ComputeAccelerations(points, gp.metrics)
 
-	// Adiciona o último ponto
-	interpolated = append(interpolated, points[len(points)-1])
-	return interpolated
+	// Distância, tempos, subida/descida acumuladas e inclinação
+	ComputeCumulativeMetrics(points, gp.metrics)
 }
 
-// interpolateBearing interpola bearing considerando o wraparound de 0-360 graus - VERSÃO CORRIGIDA
-func (gp *GPSProcessor) interpolateBearing(bearing1, bearing2, ratio float64) float64 {
+// interpolateBearing interpola pelo menor arco entre os dois bearings
+func interpolateBearing(bearing1, bearing2, ratio float64) float64 {
 	// Normaliza os bearings para 0-360
 	bearing1 = math.Mod(bearing1+360, 360)
 	bearing2 = math.Mod(bearing2+360, 360)
@@ -390,117 +131,130 @@ func (gp *GPSProcessor) interpolateBearing(bearing1, bearing2, ratio float64) fl
 	return result
 }
 
-// ProcessStreamData - VERSÃO CORRIGIDA com melhor handling do bearing
-func (gp *GPSProcessor) ProcessStreamData(timeData, latlngData, velocityData, altitudeData []interface{}, startTime time.Time) error {
-	if len(timeData) != len(latlngData) {
-		return fmt.Errorf("data length mismatch: time=%d, latlng=%d", len(timeData), len(latlngData))
+// StreamData são os streams de uma atividade já tipados. Time e LatLng têm uma entrada por
+// amostra; Velocity, Altitude, Moving e HeartRate são opcionais e podem ser mais curtos
+type StreamData struct {
+	Time      []int
+	LatLng    [][2]float64
+	Velocity  []float64
+	Altitude  []float64
+	Moving    []bool
+	HeartRate []float64
+}
+
+// ProcessStreamData converte os streams do Strava na trilha processada: valida os pontos,
+// ordena por tempo, filtra, calcula bearing e G-force, interpola a cada segundo e monta o
+// índice espacial
+func (gp *GPSProcessor) ProcessStreamData(data StreamData, startTime time.Time) error {
+	if len(data.Time) != len(data.LatLng) {
+		return fmt.Errorf("data length mismatch: time=%d, latlng=%d", len(data.Time), len(data.LatLng))
 	}
 
-	log.Printf("DEBUG: Processando %d pontos GPS com bearing corrigido", len(timeData))
-	rawPoints := make([]GPSPoint, 0, len(timeData))
+	log.Printf("DEBUG: Processando %d pontos GPS", len(data.Time))
+	rawPoints := make([]GPSPoint, 0, len(data.Time))
 
-	validPoints := 0
-	for i := 0; i < len(timeData); i++ {
-		timeOffset, ok := timeData[i].(float64)
-		if !ok {
-			continue
+	for i := range data.Time {
+		if point, ok := parseStreamPoint(i, data, startTime); ok {
+			rawPoints = append(rawPoints, point)
 		}
+	}
 
-		latlngInterface, ok := latlngData[i].([]interface{})
-		if !ok || len(latlngInterface) != 2 {
-			continue
-		}
+	if len(rawPoints) == 0 {
+		return fmt.Errorf("nenhum ponto GPS válido encontrado")
+	}
 
-		lat, ok1 := latlngInterface[0].(float64)
-		lng, ok2 := latlngInterface[1].(float64)
+	rawPoints = sortAndDedupByTime(rawPoints)
+	validPoints := len(rawPoints)
 
-		if !ok1 || !ok2 || lat == 0 && lng == 0 ||
-			lat < -90 || lat > 90 || lng < -180 || lng > 180 ||
-			math.IsNaN(lat) || math.IsNaN(lng) {
-			continue
-		}
+	// Remove outliers e suaviza a trilha antes de derivar bearing e G-force
+	rawPoints = gp.filters.Apply(rawPoints)
 
-		point := GPSPoint{
-			Time: startTime.Add(time.Duration(timeOffset) * time.Second),
-			Lat:  lat,
-			Lng:  lng,
-		}
+	gp.calculateDerivedValues(rawPoints)
 
-		// Adiciona dados opcionais
-		if velocityData != nil && i < len(velocityData) && velocityData[i] != nil {
-			if vel, ok := velocityData[i].(float64); ok && !math.IsNaN(vel) {
-				point.Velocity = vel
-			}
-		}
+	// Interpola os pontos para criar uma transição suave
+	gp.samples = rawPoints
+	gp.points = gp.interpolatePoints(rawPoints)
+	gp.index = NewSpatialIndex(gp.points)
 
-		if altitudeData != nil && i < len(altitudeData) && altitudeData[i] != nil {
-			if alt, ok := altitudeData[i].(float64); ok && !math.IsNaN(alt) {
-				point.Altitude = alt
-			}
-		}
+	log.Printf("DEBUG: %d pontos GPS válidos processados, resultando em %d pontos após interpolação", validPoints, len(gp.points))
 
-		rawPoints = append(rawPoints, point)
-		validPoints++
+	return nil
+}
+
+// parseStreamPoint lê o ponto i dos streams, descartando amostras com coordenada inválida
+func parseStreamPoint(i int, data StreamData, startTime time.Time) (GPSPoint, bool) {
+	lat, lng := data.LatLng[i][0], data.LatLng[i][1]
+	if !isValidCoordinate(lat, lng) {
+		return GPSPoint{}, false
 	}
 
-	if validPoints == 0 {
-		return fmt.Errorf("nenhum ponto GPS válido encontrado")
+	point := GPSPoint{
+		Time: startTime.Add(time.Duration(data.Time[i]) * time.Second),
+		Lat:  lat,
+		Lng:  lng,
 	}
 
-	// CORREÇÃO: Calcula bearing e G-force usando função melhorada
-	gp.calculateDerivedValues(rawPoints)
+	// Adiciona dados opcionais
+	if i < len(data.Velocity) && !math.IsNaN(data.Velocity[i]) {
+		point.Velocity = data.Velocity[i]
+	}
 
-	// Interpola os pontos para criar uma transição suave
-	gp.points = gp.interpolatePoints(rawPoints)
+	if i < len(data.Altitude) && !math.IsNaN(data.Altitude[i]) {
+		point.Altitude = data.Altitude[i]
+	}
 
-	log.Printf("DEBUG: %d pontos GPS válidos processados, resultando em %d pontos após interpolação com bearing corrigido", validPoints, len(gp.points))
+	if i < len(data.Moving) {
+		point.Paused = !data.Moving[i]
+	}
 
-	return nil
+	if i < len(data.HeartRate) && !math.IsNaN(data.HeartRate[i]) {
+		point.HeartRate = data.HeartRate[i]
+	}
+
+	return point, true
+}
+
+func isValidCoordinate(lat, lng float64) bool {
+	return !(lat == 0 && lng == 0) &&
+		lat >= -90 && lat <= 90 &&
+		lng >= -180 && lng <= 180 &&
+		!math.IsNaN(lat) && !math.IsNaN(lng)
+}
+
+// sortAndDedupByTime ordena os pontos por tempo de forma estável e mantém apenas a primeira
+// amostra de cada instante, garantindo o mesmo resultado para a mesma entrada
+func sortAndDedupByTime(points []GPSPoint) []GPSPoint {
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Time.Before(points[j].Time)
+	})
+
+	unique := points[:1]
+	for _, point := range points[1:] {
+		if !point.Time.Equal(unique[len(unique)-1].Time) {
+			unique = append(unique, point)
+		}
+	}
+	return unique
 }
 
+// interpolatePoints preenche intervalos maiores que 1 segundo com um ponto por segundo,
+// amostrados pelo mesmo Interpolator usado na renderização
 func (gp *GPSProcessor) interpolatePoints(points []GPSPoint) []GPSPoint {
 	if len(points) < 2 {
 		return points
 	}
 
-	var interpolated []GPSPoint
+	track := NewInterpolator(points)
+	interpolated := make([]GPSPoint, 0, len(points)*2)
 	for i := 0; i < len(points)-1; i++ {
-		p1 := points[i]
-		p2 := points[i+1]
-
-		t1 := p1.Time
-		t2 := p2.Time
-		duration := t2.Sub(t1)
-
-		// Adiciona o ponto inicial do intervalo
-		interpolated = append(interpolated, p1)
-
-		// Se o intervalo for maior que 1 segundo, interpola
-		if duration.Seconds() > 1.0 {
-			// Gera os pontos intermediários a cada segundo
-			for t := time.Second; t < duration; t += time.Second {
-				ratio := float64(t) / float64(duration)
-
-				lat := p1.Lat + ratio*(p2.Lat-p1.Lat)
-				lng := p1.Lng + ratio*(p2.Lng-p1.Lng)
-				altitude := p1.Altitude + ratio*(p2.Altitude-p1.Altitude)
-				velocity := p1.Velocity + ratio*(p2.Velocity-p1.Velocity)
-
-				// Para Bearing e GForce, usamos o valor do ponto anterior para simplicidade
-				bearing := p1.Bearing
-				gForce := p1.GForce
-
-				newPoint := GPSPoint{
-					Time:     t1.Add(t),
-					Lat:      lat,
-					Lng:      lng,
-					Altitude: altitude,
-					Velocity: velocity,
-					Bearing:  bearing,
-					GForce:   gForce,
-				}
-				interpolated = append(interpolated, newPoint)
-			}
+		start := points[i].Time
+		duration := points[i+1].Time.Sub(start)
+
+		// Adiciona o ponto inicial do intervalo e os intermediários a cada segundo
+		interpolated = append(interpolated, points[i])
+		for t := time.Second; t < duration; t += time.Second {
+			point, _ := track.At(start.Add(t))
+			interpolated = append(interpolated, point)
 		}
 	}
 
@@ -509,31 +263,33 @@ func (gp *GPSProcessor) interpolatePoints(points []GPSPoint) []GPSPoint {
 	return interpolated
 }
 
+// GetPointsForTimeRange retorna os pontos entre startTime e endTime, incluindo o primeiro
+// ponto em ou após endTime para cobrir o fim do intervalo
 func (gp *GPSProcessor) GetPointsForTimeRange(startTime, endTime time.Time) []GPSPoint {
 	var result []GPSPoint
 
-	startIdx := -1
-	endIdx := -1
-
-	// Encontra os índices de início e fim baseados no tempo
-	for i, point := range gp.points {
-		if startIdx == -1 && (point.Time.Equal(startTime) || point.Time.After(startTime)) {
-			startIdx = i
-		}
-		if endIdx == -1 && (point.Time.Equal(endTime) || point.Time.After(endTime)) {
-			endIdx = i
-			break // para a busca após encontrar o ponto final
-		}
+	n := len(gp.points)
+	startIdx := gp.searchTime(startTime)
+	endIdx := gp.searchTime(endTime)
+	if endIdx == n {
+		endIdx = n - 1
 	}
 
-	if startIdx != -1 && endIdx != -1 {
+	if startIdx < n && startIdx <= endIdx {
 		result = gp.points[startIdx : endIdx+1]
 	}
 
-	fmt.Printf("DEBUG: Coletados %d pontos GPS para o vídeo (de %s a %s)\n", len(result), startTime.Format("15:04:05"), endTime.Format("15:04:05"))
+	log.Printf("DEBUG: Coletados %d pontos GPS para o vídeo (de %s a %s)", len(result), startTime.Format("15:04:05"), endTime.Format("15:04:05"))
 	return result
 }
 
+// searchTime retorna o índice do primeiro ponto em ou após t (len(points) se não houver)
+func (gp *GPSProcessor) searchTime(t time.Time) int {
+	return sort.Search(len(gp.points), func(i int) bool {
+		return !gp.points[i].Time.Before(t)
+	})
+}
+
 // calculateBearing calcula o bearing (direção) entre dois pontos GPS usando a fórmula correta
 func (gp *GPSProcessor) calculateBearing(from, to GPSPoint) float64 {
 	// Converte coordenadas para radianos
@@ -561,53 +317,32 @@ func (gp *GPSProcessor) calculateBearing(from, to GPSPoint) float64 {
 	return bearing
 }
 
-func (gp *GPSProcessor) calculateGForce(from, to GPSPoint) float64 {
-	if from.Time.Equal(to.Time) {
-		return 0
-	}
-
-	deltaV := to.Velocity - from.Velocity
-	deltaT := to.Time.Sub(from.Time).Seconds()
-
-	if deltaT == 0 {
-		return 0
-	}
-
-	acceleration := deltaV / deltaT
-	return acceleration / 9.81
-}
-
+// GetPointForTime retorna o ponto mais próximo de targetTime; em caso de empate, o anterior
 func (gp *GPSProcessor) GetPointForTime(targetTime time.Time) (GPSPoint, bool) {
 	if len(gp.points) == 0 {
 		return GPSPoint{}, false
 	}
 
-	var closestPoint GPSPoint
-	minDiff := time.Duration(math.MaxInt64)
-	found := false
-
-	for _, point := range gp.points {
-		diff := point.Time.Sub(targetTime)
-		if diff < 0 {
-			diff = -diff
-		}
-		if diff < minDiff {
-			minDiff = diff
-			closestPoint = point
-			found = true
-		}
+	i := gp.searchTime(targetTime)
+	if i == len(gp.points) {
+		i--
+	} else if i > 0 && targetTime.Sub(gp.points[i-1].Time) <= gp.points[i].Time.Sub(targetTime) {
+		i--
 	}
 
-	if found {
-		log.Printf("DEBUG: Ponto GPS mais próximo encontrado: %s (diferença: %v)", closestPoint.Time.Format("15:04:05"), minDiff)
+	closestPoint := gp.points[i]
+	diff := closestPoint.Time.Sub(targetTime)
+	if diff < 0 {
+		diff = -diff
 	}
+	log.Printf("DEBUG: Ponto GPS mais próximo encontrado: %s (diferença: %v)", closestPoint.Time.Format("15:04:05"), diff)
 
-	return closestPoint, found
+	return closestPoint, true
 }
 
-// haversineDistance calcula a distância entre dois pontos GPS.
-func haversineDistance(p1, p2 GPSPoint) float64 {
-	const R = 6371 // Raio da Terra em km
+// DistanceMeters calcula a distância haversine entre dois pontos GPS em metros
+func DistanceMeters(p1, p2 GPSPoint) float64 {
+	const R = 6371000 // Raio da Terra em metros
 	lat1Rad := p1.Lat * math.Pi / 180
 	lon1Rad := p1.Lng * math.Pi / 180
 	lat2Rad := p2.Lat * math.Pi / 180
@@ -624,30 +359,57 @@ func haversineDistance(p1, p2 GPSPoint) float64 {
 
 // GetPointForCoords encontra o ponto GPS mais próximo de uma coordenada específica.
 func (gp *GPSProcessor) GetPointForCoords(targetLat, targetLng float64) (GPSPoint, bool) {
-	if len(gp.points) == 0 {
-		return GPSPoint{}, false
+	if gp.index == nil {
+		gp.index = NewSpatialIndex(gp.points)
+	}
+
+	closestPoint, minDist, found := gp.index.Nearest(targetLat, targetLng)
+	if found {
+		log.Printf("DEBUG: Ponto GPS mais próximo do clique encontrado: %s (distância: %.2f m)", closestPoint.Time.Format("15:04:05"), minDist)
 	}
 
-	var closestPoint GPSPoint
-	minDist := math.MaxFloat64
-	found := false
+	return closestPoint, found
+}
 
-	targetPoint := GPSPoint{Lat: targetLat, Lng: targetLng}
+// GetPointForCoordsBetween encontra o ponto mais próximo da coordenada entre from e to. Em
+// trajetos de ida e volta e em voltas repetidas a busca só pela posição pode cair na passagem
+// errada; restringir ao intervalo do trecho clicado desfaz a ambiguidade.
+func (gp *GPSProcessor) GetPointForCoordsBetween(targetLat, targetLng float64, from, to time.Time) (GPSPoint, bool) {
+	if to.Before(from) {
+		from, to = to, from
+	}
 
-	for _, point := range gp.points {
-		dist := haversineDistance(targetPoint, point)
-		if dist < minDist {
-			minDist = dist
-			closestPoint = point
-			found = true
+	target := GPSPoint{Lat: targetLat, Lng: targetLng}
+	bestIdx := -1
+	bestDist := math.MaxFloat64
+	for i := gp.searchTime(from); i < len(gp.points) && !gp.points[i].Time.After(to); i++ {
+		if dist := DistanceMeters(target, gp.points[i]); dist < bestDist {
+			bestIdx, bestDist = i, dist
 		}
 	}
+	if bestIdx < 0 {
+		return GPSPoint{}, false
+	}
 
-	if found {
-		log.Printf("DEBUG: Ponto GPS mais próximo do clique encontrado: %s (distância: %.2f m)", closestPoint.Time.Format("15:04:05"), minDist*1000)
+	log.Printf("DEBUG: Ponto GPS mais próximo do clique no trecho encontrado: %s (distância: %.2f m)", gp.points[bestIdx].Time.Format("15:04:05"), bestDist)
+	return gp.points[bestIdx], true
+}
+
+// Interpolator retorna um interpolador sobre a trilha processada, para amostragem em
+// instantes arbitrários (ex.: um ponto por quadro de vídeo)
+func (gp *GPSProcessor) Interpolator() *Interpolator {
+	if len(gp.samples) > 0 {
+		return NewInterpolator(gp.samples)
 	}
+	return NewInterpolator(gp.points)
+}
 
-	return closestPoint, found
+// Segments retorna as paradas, pausas automáticas e lacunas detectadas na trilha
+func (gp *GPSProcessor) Segments() []TrackSegment {
+	if len(gp.samples) > 0 {
+		return DetectSegments(gp.samples, gp.segments)
+	}
+	return DetectSegments(gp.points, gp.segments)
 }
 
 // GetAllPoints retorna todos os pontos GPS processados e interpolados.
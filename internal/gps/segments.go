@@ -0,0 +1,188 @@
+package gps
+
+import (
+	"sort"
+	"time"
+)
+
+// SegmentType identifica o tipo de trecho detectado na trilha
+type SegmentType string
+
+const (
+	SegmentStop      SegmentType = "stop"       // Parado com o dispositivo gravando
+	SegmentAutoPause SegmentType = "auto_pause" // Gravação pausada (lacuna no tempo sem deslocamento)
+	SegmentGap       SegmentType = "gap"        // Perda de sinal em movimento (lacuna com deslocamento)
+)
+
+// TrackSegment é um intervalo da trilha em que o atleta não estava se movendo ou não há dados.
+// Lat/Lng indicam onde o trecho começou.
+type TrackSegment struct {
+	Type  SegmentType
+	Start time.Time
+	End   time.Time
+	Lat   float64
+	Lng   float64
+}
+
+// Duration retorna a duração do trecho
+func (s TrackSegment) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Contains indica se t está dentro do trecho
+func (s TrackSegment) Contains(t time.Time) bool {
+	return !t.Before(s.Start) && t.Before(s.End)
+}
+
+// SegmentConfig controla a detecção de paradas, pausas e lacunas
+type SegmentConfig struct {
+	// Abaixo desta velocidade (m/s) o atleta é considerado parado
+	StopSpeed float64
+	// Duração mínima para uma sequência de pontos parados virar uma parada
+	MinStopDuration time.Duration
+	// Intervalo entre amostras acima do qual há uma lacuna (pausa automática ou perda de sinal).
+	// Em trilhas de baixa resolução o limite sobe para gapIntervalFactor vezes o intervalo típico.
+	GapThreshold time.Duration
+}
+
+// DefaultSegmentConfig retorna parâmetros adequados a trilhas de 1 Hz
+func DefaultSegmentConfig() SegmentConfig {
+	return SegmentConfig{
+		StopSpeed:       0.5,
+		MinStopDuration: 10 * time.Second,
+		GapThreshold:    10 * time.Second,
+	}
+}
+
+// gapIntervalFactor é quantas vezes o intervalo mediano entre amostras um intervalo precisa
+// durar para contar como lacuna
+const gapIntervalFactor = 3
+
+// gapThreshold retorna o limite de lacuna para a trilha: o configurado ou, quando os pontos são
+// esparsos (streams em resolução baixa ou média), um múltiplo do intervalo mediano entre eles
+func gapThreshold(points []GPSPoint, cfg SegmentConfig) time.Duration {
+	intervals := make([]time.Duration, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		intervals = append(intervals, points[i].Time.Sub(points[i-1].Time))
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+
+	return max(cfg.GapThreshold, gapIntervalFactor*intervals[len(intervals)/2])
+}
+
+// DetectSegments encontra paradas, pausas automáticas e lacunas nos pontos amostrados (antes da
+// interpolação, para que as lacunas ainda sejam visíveis). O resultado é ordenado por início.
+func DetectSegments(points []GPSPoint, cfg SegmentConfig) []TrackSegment {
+	var segments []TrackSegment
+	if len(points) < 2 {
+		return segments
+	}
+
+	speeds := pointSpeeds(points)
+	threshold := gapThreshold(points, cfg)
+	stopStart := -1
+	closeStop := func(end int) {
+		if stopStart >= 0 && points[end].Time.Sub(points[stopStart].Time) >= cfg.MinStopDuration {
+			segments = append(segments, TrackSegment{
+				Type:  SegmentStop,
+				Start: points[stopStart].Time,
+				End:   points[end].Time,
+				Lat:   points[stopStart].Lat,
+				Lng:   points[stopStart].Lng,
+			})
+		}
+		stopStart = -1
+	}
+
+	for i := range points {
+		if i > 0 {
+			prev, curr := points[i-1], points[i]
+			dt := curr.Time.Sub(prev.Time)
+
+			if dt > threshold {
+				closeStop(i - 1)
+
+				// Sem deslocamento relevante durante a lacuna o dispositivo estava pausado
+				segmentType := SegmentGap
+				if DistanceMeters(prev, curr)/dt.Seconds() < cfg.StopSpeed {
+					segmentType = SegmentAutoPause
+				}
+				segments = append(segments, TrackSegment{
+					Type:  segmentType,
+					Start: prev.Time,
+					End:   curr.Time,
+					Lat:   prev.Lat,
+					Lng:   prev.Lng,
+				})
+			}
+		}
+
+		if speeds[i] < cfg.StopSpeed {
+			if stopStart < 0 {
+				stopStart = i
+			}
+		} else {
+			closeStop(i)
+		}
+	}
+	closeStop(len(points) - 1)
+
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].Start.Before(segments[j].Start)
+	})
+	return segments
+}
+
+// pointSpeeds usa o stream de velocidade quando existe e, sem ele, estima a velocidade pela
+// distância até o ponto anterior. Amostras pausadas pelo Strava contam como paradas.
+func pointSpeeds(points []GPSPoint) []float64 {
+	speeds := make([]float64, len(points))
+
+	hasVelocity := false
+	for i, point := range points {
+		speeds[i] = point.Velocity
+		if point.Velocity > 0 {
+			hasVelocity = true
+		}
+	}
+	if !hasVelocity {
+		estimateSpeeds(points, speeds)
+	}
+
+	for i, point := range points {
+		if point.Paused {
+			speeds[i] = 0
+		}
+	}
+	return speeds
+}
+
+// estimateSpeeds preenche speeds com a distância até o ponto anterior dividida pelo intervalo
+func estimateSpeeds(points []GPSPoint, speeds []float64) {
+	for i := 1; i < len(points); i++ {
+		if dt := points[i].Time.Sub(points[i-1].Time).Seconds(); dt > 0 {
+			speeds[i] = DistanceMeters(points[i-1], points[i]) / dt
+		}
+	}
+	if len(points) > 1 {
+		speeds[0] = speeds[1]
+	}
+}
+
+// SegmentAt retorna o trecho que contém t, considerando apenas os tipos informados
+func SegmentAt(segments []TrackSegment, t time.Time, types ...SegmentType) (TrackSegment, bool) {
+	for _, segment := range segments {
+		if segment.Start.After(t) {
+			break
+		}
+		if !segment.Contains(t) {
+			continue
+		}
+		for _, segmentType := range types {
+			if segment.Type == segmentType {
+				return segment, true
+			}
+		}
+	}
+	return TrackSegment{}, false
+}
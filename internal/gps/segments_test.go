@@ -0,0 +1,122 @@
+package gps
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectSegments(t *testing.T) {
+	moving := trackStep{count: 30, dt: time.Second, meters: 10, velocity: 10}
+
+	// wantSegment indica o tipo e os índices dos pontos de início e fim do trecho esperado
+	type wantSegment struct {
+		segmentType SegmentType
+		start, end  int
+	}
+
+	tests := []struct {
+		name   string
+		points []GPSPoint
+		want   []wantSegment
+	}{
+		{
+			name:   "parada",
+			points: pathTrack(moving, trackStep{count: 20, dt: time.Second}, moving),
+			want:   []wantSegment{{SegmentStop, 31, 51}},
+		},
+		{
+			name:   "parada curta ignorada",
+			points: pathTrack(moving, trackStep{count: 5, dt: time.Second}, moving),
+		},
+		{
+			name:   "amostras pausadas pelo Strava contam como parada",
+			points: pathTrack(moving, trackStep{count: 15, dt: time.Second, meters: 10, velocity: 10, paused: true}, moving),
+			want:   []wantSegment{{SegmentStop, 31, 46}},
+		},
+		{
+			name:   "sem stream de velocidade a parada vem do deslocamento",
+			points: pathTrack(trackStep{count: 30, dt: time.Second, meters: 10}, trackStep{count: 20, dt: time.Second}, trackStep{count: 30, dt: time.Second, meters: 10}),
+			want:   []wantSegment{{SegmentStop, 31, 51}},
+		},
+		{
+			name:   "lacuna sem deslocamento é pausa automática",
+			points: pathTrack(moving, trackStep{count: 1, dt: time.Minute, velocity: 10}, moving),
+			want:   []wantSegment{{SegmentAutoPause, 30, 31}},
+		},
+		{
+			name:   "lacuna com deslocamento é perda de sinal",
+			points: pathTrack(moving, trackStep{count: 1, dt: time.Minute, meters: 600, velocity: 10}, moving),
+			want:   []wantSegment{{SegmentGap, 30, 31}},
+		},
+		{
+			name:   "parada seguida de pausa automática",
+			points: pathTrack(moving, trackStep{count: 20, dt: time.Second}, trackStep{count: 1, dt: time.Minute}, moving),
+			want:   []wantSegment{{SegmentStop, 31, 50}, {SegmentAutoPause, 50, 51}},
+		},
+		{
+			// Com amostras a cada 5 s o limite sobe para 15 s
+			name: "trilha esparsa tolera intervalos maiores",
+			points: pathTrack(
+				trackStep{count: 20, dt: 5 * time.Second, meters: 50, velocity: 10},
+				trackStep{count: 1, dt: 12 * time.Second, meters: 120, velocity: 10},
+				trackStep{count: 20, dt: 5 * time.Second, meters: 50, velocity: 10},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments := DetectSegments(tt.points, DefaultSegmentConfig())
+			if len(segments) != len(tt.want) {
+				t.Fatalf("esperava %d trechos, obteve %d: %+v", len(tt.want), len(segments), segments)
+			}
+
+			for i, want := range tt.want {
+				got := segments[i]
+				start, end := tt.points[want.start], tt.points[want.end]
+				if got.Type != want.segmentType {
+					t.Errorf("trecho %d: tipo %q, esperado %q", i, got.Type, want.segmentType)
+				}
+				if !got.Start.Equal(start.Time) || !got.End.Equal(end.Time) {
+					t.Errorf("trecho %d: de %v a %v, esperado de %v a %v", i,
+						got.Start.Sub(tt.points[0].Time), got.End.Sub(tt.points[0].Time),
+						start.Time.Sub(tt.points[0].Time), end.Time.Sub(tt.points[0].Time))
+				}
+				if got.Lat != start.Lat || got.Lng != start.Lng {
+					t.Errorf("trecho %d: começa em (%f, %f), esperado (%f, %f)", i, got.Lat, got.Lng, start.Lat, start.Lng)
+				}
+			}
+		})
+	}
+}
+
+func TestSegmentAt(t *testing.T) {
+	points := pathTrack(
+		trackStep{count: 30, dt: time.Second, meters: 10, velocity: 10},
+		trackStep{count: 20, dt: time.Second},
+		trackStep{count: 1, dt: time.Minute},
+		trackStep{count: 30, dt: time.Second, meters: 10, velocity: 10},
+	)
+	segments := DetectSegments(points, DefaultSegmentConfig())
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		types    []SegmentType
+		want     SegmentType
+		wantFind bool
+	}{
+		{"em movimento", points[10].Time, []SegmentType{SegmentStop, SegmentAutoPause}, "", false},
+		{"início da parada", points[31].Time, []SegmentType{SegmentStop}, SegmentStop, true},
+		{"fim da parada é exclusivo", points[50].Time, []SegmentType{SegmentStop}, "", false},
+		{"durante a pausa", points[50].Time.Add(30 * time.Second), []SegmentType{SegmentStop, SegmentAutoPause}, SegmentAutoPause, true},
+		{"tipo não pedido", points[40].Time, []SegmentType{SegmentGap}, "", false},
+	}
+
+	for _, tt := range tests {
+		segment, ok := SegmentAt(segments, tt.at, tt.types...)
+		if ok != tt.wantFind || segment.Type != tt.want {
+			t.Errorf("%s: obtido %q (%v), esperado %q (%v)", tt.name, segment.Type, ok, tt.want, tt.wantFind)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package gps
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// Algoritmos de simplificação de trilha
+const (
+	SimplifyDouglasPeucker = "douglas-peucker"
+	SimplifyVisvalingam    = "visvalingam"
+)
+
+// SimplifyOptions controla a simplificação geométrica da trilha. Com TargetPoints > 0 a
+// tolerância é ajustada até a trilha caber nessa quantidade; senão Tolerance (metros) é usada.
+type SimplifyOptions struct {
+	Algorithm    string
+	Tolerance    float64
+	TargetPoints int
+
+	// Curvas com variação de direção acima deste ângulo (graus) são sempre mantidas; 0 desativa
+	PreserveTurnAngle float64
+	// Mantém o início e o fim de cada parada
+	PreserveStops bool
+}
+
+// DefaultSimplifyOptions retorna Douglas–Peucker com 5 m de tolerância preservando curvas
+// fechadas e paradas
+func DefaultSimplifyOptions() SimplifyOptions {
+	return SimplifyOptions{
+		Algorithm:         SimplifyDouglasPeucker,
+		Tolerance:         5.0,
+		PreserveTurnAngle: 60.0,
+		PreserveStops:     true,
+	}
+}
+
+// ToleranceForZoom retorna a largura de um pixel em metros no nível de zoom do mapa (Web
+// Mercator, tiles de 256 px) na latitude informada. Detalhes menores que isso não são visíveis.
+func ToleranceForZoom(zoom int, lat float64) float64 {
+	return 156543.03392 * math.Cos(lat*math.Pi/180) / math.Pow(2, float64(zoom))
+}
+
+// Simplify reduz a trilha mantendo o formato visível. Pontos de curva fechada e limites de
+// paradas são fixados antes e a simplificação corre entre eles, então nunca são removidos.
+func Simplify(points []GPSPoint, opts SimplifyOptions) []GPSPoint {
+	if len(points) < 3 {
+		return points
+	}
+
+	xy := projectMeters(points)
+	anchors := simplifyAnchors(points, xy, opts)
+
+	var keep []bool
+	if opts.TargetPoints > 0 {
+		keep = simplifyToCount(xy, anchors, opts.Algorithm, max(opts.TargetPoints, len(anchors)))
+	} else {
+		keep = simplifyWithTolerance(xy, anchors, opts.Algorithm, opts.Tolerance)
+	}
+
+	simplified := make([]GPSPoint, 0, len(points)/4)
+	for i, kept := range keep {
+		if kept {
+			simplified = append(simplified, points[i])
+		}
+	}
+	return simplified
+}
+
+// projectMeters projeta a trilha num plano local em metros (equiretangular na latitude média)
+func projectMeters(points []GPSPoint) [][2]float64 {
+	sumLat := 0.0
+	for _, point := range points {
+		sumLat += point.Lat
+	}
+	lngScale := metersPerDegree * math.Cos(sumLat/float64(len(points))*math.Pi/180)
+
+	xy := make([][2]float64, len(points))
+	for i, point := range points {
+		xy[i] = [2]float64{point.Lng * lngScale, point.Lat * metersPerDegree}
+	}
+	return xy
+}
+
+// simplifyAnchors retorna, em ordem, os índices que não podem ser removidos
+func simplifyAnchors(points []GPSPoint, xy [][2]float64, opts SimplifyOptions) []int {
+	fixed := map[int]bool{0: true, len(points) - 1: true}
+
+	if opts.PreserveStops {
+		stopSpeed := DefaultSegmentConfig().StopSpeed
+		for i := 1; i < len(points); i++ {
+			wasStopped := points[i-1].Velocity < stopSpeed
+			isStopped := points[i].Velocity < stopSpeed
+			if wasStopped != isStopped {
+				fixed[i-1] = true
+				fixed[i] = true
+			}
+		}
+	}
+
+	if opts.PreserveTurnAngle > 0 {
+		for _, i := range sharpTurns(xy, opts.PreserveTurnAngle) {
+			fixed[i] = true
+		}
+	}
+
+	anchors := make([]int, 0, len(fixed))
+	for i := range fixed {
+		anchors = append(anchors, i)
+	}
+	sort.Ints(anchors)
+	return anchors
+}
+
+// turnArm é a distância (m) antes e depois do ponto usada para medir a mudança de direção,
+// longa o bastante para ignorar o ruído de posição entre amostras de 1 segundo
+const turnArm = 15.0
+
+// sharpTurns encontra os vértices de curvas fechadas: onde a direção de chegada e de saída,
+// medidas a turnArm metros, diferem mais que minAngle. De cada curva fica o ponto de maior ângulo.
+func sharpTurns(xy [][2]float64, minAngle float64) []int {
+	n := len(xy)
+	cumulative := make([]float64, n)
+	for i := 1; i < n; i++ {
+		cumulative[i] = cumulative[i-1] + math.Hypot(xy[i][0]-xy[i-1][0], xy[i][1]-xy[i-1][1])
+	}
+
+	var turns []int
+	best, bestAngle := -1, 0.0
+	back, ahead := 0, 0
+
+	for i := 1; i < n-1; i++ {
+		for back < i && cumulative[i]-cumulative[back+1] >= turnArm {
+			back++
+		}
+		for ahead < n-1 && (ahead <= i || cumulative[ahead]-cumulative[i] < turnArm) {
+			ahead++
+		}
+
+		angle := 0.0
+		if cumulative[i]-cumulative[back] >= turnArm && cumulative[ahead]-cumulative[i] >= turnArm {
+			in := math.Atan2(xy[i][1]-xy[back][1], xy[i][0]-xy[back][0])
+			out := math.Atan2(xy[ahead][1]-xy[i][1], xy[ahead][0]-xy[i][0])
+			angle = math.Abs(bearingDelta(in*180/math.Pi, out*180/math.Pi))
+		}
+
+		if angle >= minAngle {
+			if angle > bestAngle {
+				best, bestAngle = i, angle
+			}
+			continue
+		}
+		if best >= 0 {
+			turns = append(turns, best)
+			best, bestAngle = -1, 0
+		}
+	}
+	if best >= 0 {
+		turns = append(turns, best)
+	}
+	return turns
+}
+
+// simplifyWithTolerance simplifica cada trecho entre âncoras com a tolerância em metros
+func simplifyWithTolerance(xy [][2]float64, anchors []int, algorithm string, tolerance float64) []bool {
+	if algorithm == SimplifyVisvalingam {
+		return visvalingam(xy, anchors, tolerance*tolerance, 0)
+	}
+
+	keep := make([]bool, len(xy))
+	for _, i := range anchors {
+		keep[i] = true
+	}
+	for k := 1; k < len(anchors); k++ {
+		douglasPeucker(xy, anchors[k-1], anchors[k], tolerance, keep)
+	}
+	return keep
+}
+
+// simplifyToCount reduz a trilha a no máximo target pontos
+func simplifyToCount(xy [][2]float64, anchors []int, algorithm string, target int) []bool {
+	if algorithm == SimplifyVisvalingam {
+		return visvalingam(xy, anchors, math.Inf(1), target)
+	}
+
+	// Busca binária da menor tolerância que atende ao limite de pontos
+	lo, hi := 0.0, 1.0
+	for countKept(simplifyWithTolerance(xy, anchors, algorithm, hi)) > target && hi < 1e7 {
+		hi *= 2
+	}
+	for iteration := 0; iteration < 30 && hi-lo > 0.01; iteration++ {
+		mid := (lo + hi) / 2
+		if countKept(simplifyWithTolerance(xy, anchors, algorithm, mid)) > target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return simplifyWithTolerance(xy, anchors, algorithm, hi)
+}
+
+func countKept(keep []bool) int {
+	count := 0
+	for _, kept := range keep {
+		if kept {
+			count++
+		}
+	}
+	return count
+}
+
+// douglasPeucker marca os pontos entre first e last que se afastam mais que tolerance da corda
+func douglasPeucker(xy [][2]float64, first, last int, tolerance float64, keep []bool) {
+	stack := [][2]int{{first, last}}
+	for len(stack) > 0 {
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		a, b := span[0], span[1]
+
+		farthest, maxDist := -1, tolerance
+		for i := a + 1; i < b; i++ {
+			if d := segmentDistance(xy[i], xy[a], xy[b]); d > maxDist {
+				farthest, maxDist = i, d
+			}
+		}
+		if farthest < 0 {
+			continue
+		}
+
+		keep[farthest] = true
+		stack = append(stack, [2]int{a, farthest}, [2]int{farthest, b})
+	}
+}
+
+// segmentDistance é a distância do ponto p ao segmento ab
+func segmentDistance(p, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / lengthSq
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p[0]-(a[0]+t*dx), p[1]-(a[1]+t*dy))
+}
+
+// visvalingam remove repetidamente o ponto de menor área efetiva até que a menor área supere
+// maxArea ou restem target pontos. Âncoras nunca são removidas.
+func visvalingam(xy [][2]float64, anchors []int, maxArea float64, target int) []bool {
+	n := len(xy)
+	keep := make([]bool, n)
+	prev := make([]int, n)
+	next := make([]int, n)
+	for i := range xy {
+		keep[i] = true
+		prev[i] = i - 1
+		next[i] = i + 1
+	}
+
+	fixed := make([]bool, n)
+	for _, i := range anchors {
+		fixed[i] = true
+	}
+
+	areas := make([]float64, n)
+	pq := &areaHeap{}
+	for i := 1; i < n-1; i++ {
+		if !fixed[i] {
+			areas[i] = triangleArea(xy[i-1], xy[i], xy[i+1])
+			heap.Push(pq, areaItem{index: i, area: areas[i]})
+		}
+	}
+
+	remaining := n
+	lastArea := 0.0
+	for pq.Len() > 0 {
+		if target > 0 && remaining <= target {
+			break
+		}
+
+		item := heap.Pop(pq).(areaItem)
+		i := item.index
+		if !keep[i] || item.area != areas[i] {
+			continue // Entrada obsoleta
+		}
+		if target == 0 && item.area > maxArea {
+			break
+		}
+
+		// A área efetiva nunca diminui, para que um ponto removido não proteja os vizinhos
+		lastArea = math.Max(lastArea, item.area)
+		keep[i] = false
+		remaining--
+
+		p, q := prev[i], next[i]
+		next[p], prev[q] = q, p
+
+		for _, j := range []int{p, q} {
+			if j <= 0 || j >= n-1 || fixed[j] {
+				continue
+			}
+			areas[j] = math.Max(triangleArea(xy[prev[j]], xy[j], xy[next[j]]), lastArea)
+			heap.Push(pq, areaItem{index: j, area: areas[j]})
+		}
+	}
+
+	return keep
+}
+
+func triangleArea(a, b, c [2]float64) float64 {
+	return math.Abs((b[0]-a[0])*(c[1]-a[1])-(c[0]-a[0])*(b[1]-a[1])) / 2
+}
+
+type areaItem struct {
+	index int
+	area  float64
+}
+
+// areaHeap é uma fila de prioridade de menor área, com desempate pelo índice para que o
+// resultado seja determinístico
+type areaHeap []areaItem
+
+func (h areaHeap) Len() int { return len(h) }
+func (h areaHeap) Less(i, j int) bool {
+	if h[i].area != h[j].area {
+		return h[i].area < h[j].area
+	}
+	return h[i].index < h[j].index
+}
+func (h areaHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *areaHeap) Push(x interface{}) { *h = append(*h, x.(areaItem)) }
+func (h *areaHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
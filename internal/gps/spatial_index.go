@@ -0,0 +1,137 @@
+package gps
+
+import "math"
+
+// metersPerDegree é o comprimento aproximado de um grau de latitude
+const metersPerDegree = 111320.0
+
+// SpatialIndex agrupa os pontos da trilha em células quadradas de tamanho fixo em metros,
+// permitindo encontrar o ponto mais próximo de um clique sem percorrer a trilha inteira
+type SpatialIndex struct {
+	cells    map[cellKey][]int // célula -> índices dos pontos, em ordem crescente
+	points   []GPSPoint
+	cellSize float64 // Lado da célula em metros
+	lngScale float64 // Metros por grau de longitude na latitude de referência
+	minCell  cellKey
+	maxCell  cellKey
+}
+
+type cellKey struct {
+	row, col int
+}
+
+// NewSpatialIndex cria o índice com células de ~100 m
+func NewSpatialIndex(points []GPSPoint) *SpatialIndex {
+	si := &SpatialIndex{
+		cells:    make(map[cellKey][]int),
+		points:   points,
+		cellSize: 100,
+		lngScale: metersPerDegree,
+	}
+	if len(points) == 0 {
+		return si
+	}
+
+	// Usa a latitude média como referência para que as células tenham ~100 m nos dois eixos
+	sumLat := 0.0
+	for _, point := range points {
+		sumLat += point.Lat
+	}
+	si.lngScale = metersPerDegree * math.Max(math.Cos(sumLat/float64(len(points))*math.Pi/180), 0.01)
+
+	for i, point := range points {
+		key := si.cellFor(point.Lat, point.Lng)
+		si.cells[key] = append(si.cells[key], i)
+
+		if i == 0 {
+			si.minCell, si.maxCell = key, key
+			continue
+		}
+		si.minCell.row = min(si.minCell.row, key.row)
+		si.minCell.col = min(si.minCell.col, key.col)
+		si.maxCell.row = max(si.maxCell.row, key.row)
+		si.maxCell.col = max(si.maxCell.col, key.col)
+	}
+
+	return si
+}
+
+func (si *SpatialIndex) cellFor(lat, lng float64) cellKey {
+	return cellKey{
+		row: int(math.Floor(lat * metersPerDegree / si.cellSize)),
+		col: int(math.Floor(lng * si.lngScale / si.cellSize)),
+	}
+}
+
+// Nearest retorna o ponto mais próximo da coordenada e a distância em metros. A busca
+// percorre anéis de células ao redor do alvo e para quando nenhum anel restante pode conter
+// um ponto mais próximo; empates ficam com o ponto de menor índice.
+func (si *SpatialIndex) Nearest(lat, lng float64) (GPSPoint, float64, bool) {
+	if len(si.points) == 0 {
+		return GPSPoint{}, 0, false
+	}
+
+	target := GPSPoint{Lat: lat, Lng: lng}
+	center := si.cellFor(lat, lng)
+
+	// Anel a partir do qual todas as células do índice já foram visitadas
+	lastRing := max(
+		abs(center.row-si.minCell.row), abs(center.row-si.maxCell.row),
+		abs(center.col-si.minCell.col), abs(center.col-si.maxCell.col),
+	)
+
+	bestIdx := -1
+	bestDist := math.MaxFloat64
+
+	consider := func(idx int) {
+		dist := DistanceMeters(target, si.points[idx])
+		if dist < bestDist || (dist == bestDist && idx < bestIdx) {
+			bestDist = dist
+			bestIdx = idx
+		}
+	}
+
+	// Alvo muito longe da trilha: percorrer os anéis vazios custaria mais que a busca linear
+	gap := max(si.minCell.row-center.row, center.row-si.maxCell.row, si.minCell.col-center.col, center.col-si.maxCell.col, 0)
+	if (2*gap+1)*(2*gap+1) > len(si.points) {
+		for idx := range si.points {
+			consider(idx)
+		}
+		return si.points[bestIdx], bestDist, true
+	}
+
+	visit := func(key cellKey) {
+		for _, idx := range si.cells[key] {
+			consider(idx)
+		}
+	}
+
+	for ring := 0; ring <= lastRing; ring++ {
+		// Pontos no anel r estão a pelo menos (r-1) células do alvo
+		if bestIdx >= 0 && float64(ring-1)*si.cellSize > bestDist {
+			break
+		}
+
+		if ring == 0 {
+			visit(center)
+			continue
+		}
+		for d := -ring; d <= ring; d++ {
+			visit(cellKey{center.row - ring, center.col + d})
+			visit(cellKey{center.row + ring, center.col + d})
+		}
+		for d := -ring + 1; d <= ring-1; d++ {
+			visit(cellKey{center.row + d, center.col - ring})
+			visit(cellKey{center.row + d, center.col + ring})
+		}
+	}
+
+	return si.points[bestIdx], bestDist, true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
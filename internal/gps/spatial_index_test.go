@@ -0,0 +1,148 @@
+package gps
+
+import (
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// linearNearest é a busca linear que o índice espacial substitui, usada como referência
+func linearNearest(points []GPSPoint, lat, lng float64) (int, float64) {
+	target := GPSPoint{Lat: lat, Lng: lng}
+	bestIdx, bestDist := -1, math.MaxFloat64
+	for i, point := range points {
+		if dist := DistanceMeters(target, point); dist < bestDist {
+			bestIdx, bestDist = i, dist
+		}
+	}
+	return bestIdx, bestDist
+}
+
+// linearPointForTime é a busca linear por tempo que a busca binária substitui
+func linearPointForTime(points []GPSPoint, t time.Time) GPSPoint {
+	best := points[0]
+	bestDiff := time.Duration(math.MaxInt64)
+	for _, point := range points {
+		diff := point.Time.Sub(t)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff < bestDiff {
+			best, bestDiff = point, diff
+		}
+	}
+	return best
+}
+
+// randomQueries gera coordenadas ao redor da trilha, algumas fora dela
+func randomQueries(points []GPSPoint, n int, seed int64) [][2]float64 {
+	rng := rand.New(rand.NewSource(seed))
+	queries := make([][2]float64, n)
+	for i := range queries {
+		p := points[rng.Intn(len(points))]
+		queries[i] = [2]float64{p.Lat + rng.NormFloat64()*0.002, p.Lng + rng.NormFloat64()*0.002}
+	}
+	return queries
+}
+
+// quietLogs silencia os logs de depuração do processador durante o teste
+func quietLogs(tb testing.TB) {
+	previous := log.Writer()
+	log.SetOutput(io.Discard)
+	tb.Cleanup(func() { log.SetOutput(previous) })
+}
+
+func TestSpatialIndexMatchesLinearScan(t *testing.T) {
+	points := rideTrack(2 * time.Hour)
+	index := NewSpatialIndex(points)
+
+	for _, q := range randomQueries(points, 500, 3) {
+		_, gotDist, ok := index.Nearest(q[0], q[1])
+		if !ok {
+			t.Fatalf("nenhum ponto encontrado para %v", q)
+		}
+		_, wantDist := linearNearest(points, q[0], q[1])
+		if math.Abs(gotDist-wantDist) > 1e-9 {
+			t.Fatalf("Nearest(%v) = %.3f m, busca linear %.3f m", q, gotDist, wantDist)
+		}
+	}
+
+	// Alvo muito longe da trilha cai na busca linear
+	if _, dist, _ := index.Nearest(0, 0); dist < 1000 {
+		t.Errorf("distância inesperada para alvo distante: %.0f m", dist)
+	}
+}
+
+func TestGetPointForTimeMatchesLinearScan(t *testing.T) {
+	quietLogs(t)
+	points := rideTrack(time.Hour)
+	processor := &GPSProcessor{points: points}
+
+	rng := rand.New(rand.NewSource(4))
+	start := points[0].Time
+	for i := 0; i < 500; i++ {
+		// Inclui instantes antes e depois da trilha
+		target := start.Add(time.Duration(rng.Int63n(int64(time.Hour+2*time.Minute))) - time.Minute)
+		got, _ := processor.GetPointForTime(target)
+		if want := linearPointForTime(points, target); !got.Time.Equal(want.Time) {
+			t.Fatalf("GetPointForTime(%v) = %v, busca linear %v", target, got.Time, want.Time)
+		}
+	}
+}
+
+func BenchmarkNewSpatialIndex(b *testing.B) {
+	points := rideTrack(5 * time.Hour)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewSpatialIndex(points)
+	}
+}
+
+func BenchmarkSpatialIndexNearest(b *testing.B) {
+	points := rideTrack(5 * time.Hour)
+	index := NewSpatialIndex(points)
+	queries := randomQueries(points, 1024, 5)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q := queries[i%len(queries)]
+		index.Nearest(q[0], q[1])
+	}
+}
+
+func BenchmarkLinearNearest(b *testing.B) {
+	points := rideTrack(5 * time.Hour)
+	queries := randomQueries(points, 1024, 5)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q := queries[i%len(queries)]
+		linearNearest(points, q[0], q[1])
+	}
+}
+
+func BenchmarkGetPointForTime(b *testing.B) {
+	quietLogs(b)
+	points := rideTrack(5 * time.Hour)
+	processor := &GPSProcessor{points: points}
+	start := points[0].Time
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		processor.GetPointForTime(start.Add(time.Duration(i%18000) * time.Second))
+	}
+}
+
+func BenchmarkLinearPointForTime(b *testing.B) {
+	points := rideTrack(5 * time.Hour)
+	start := points[0].Time
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		linearPointForTime(points, start.Add(time.Duration(i%18000)*time.Second))
+	}
+}
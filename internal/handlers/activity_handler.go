@@ -3,8 +3,10 @@ package handlers
 import (
 	"fmt"
 	"log"
+	"math"
 	"time"
 
+	"strava-overlay/internal/gps"
 	"strava-overlay/internal/strava"
 )
 
@@ -21,6 +23,8 @@ type FrontendActivity struct {
 	EndLatLng   []float64  `json:"end_latlng"`
 	Map         strava.Map `json:"map"`
 	HasGPS      bool       `json:"has_gps"`
+	// Miniatura do trajeto: pontos [x, y] normalizados em 0..1, com y crescendo para baixo
+	Thumbnail [][]float64 `json:"thumbnail"`
 }
 
 // PaginatedActivities representa uma resposta paginada de atividades
@@ -64,24 +68,10 @@ func (h *ActivityHandler) GetActivitiesPage(page int) (*PaginatedActivities, err
 	gpsCount := 0
 
 	for i, act := range activities {
-		hasGPS := act.Map.SummaryPolyline != ""
-		if hasGPS {
+		frontendActivities[i] = convertToFrontendActivity(act)
+		if frontendActivities[i].HasGPS {
 			gpsCount++
 		}
-
-		frontendActivities[i] = FrontendActivity{
-			ID:          act.ID,
-			Name:        act.Name,
-			Type:        act.Type,
-			StartDate:   act.StartDate.Format(time.RFC3339),
-			Distance:    act.Distance,
-			MovingTime:  act.MovingTime,
-			MaxSpeed:    act.MaxSpeed,
-			StartLatLng: act.StartLatLng,
-			EndLatLng:   act.EndLatLng,
-			Map:         act.Map,
-			HasGPS:      hasGPS,
-		}
 	}
 
 	// Determina se há mais páginas
@@ -99,6 +89,93 @@ func (h *ActivityHandler) GetActivitiesPage(page int) (*PaginatedActivities, err
 	}, nil
 }
 
+// convertToFrontendActivity converte uma atividade do Strava para o formato do frontend
+func convertToFrontendActivity(act strava.Activity) FrontendActivity {
+	thumbnail := routeThumbnail(act.Map.SummaryPolyline)
+
+	return FrontendActivity{
+		ID:          act.ID,
+		Name:        act.Name,
+		Type:        act.Type,
+		StartDate:   act.StartDate.Format(time.RFC3339),
+		Distance:    act.Distance,
+		MovingTime:  act.MovingTime,
+		MaxSpeed:    act.MaxSpeed,
+		StartLatLng: act.StartLatLng,
+		EndLatLng:   act.EndLatLng,
+		Map:         act.Map,
+		HasGPS:      hasRoute(thumbnail),
+		Thumbnail:   thumbnail,
+	}
+}
+
+// hasRoute indica se a miniatura descreve um trajeto: a polilinha resumida existe, é válida e
+// tem ao menos dois pontos
+func hasRoute(thumbnail [][]float64) bool {
+	return len(thumbnail) > 1
+}
+
+// maxThumbnailPoints limita os pontos da miniatura enviada com cada atividade da lista
+const maxThumbnailPoints = 64
+
+// routeThumbnail decodifica a polilinha resumida e projeta o trajeto num quadrado unitário,
+// mantendo a proporção, para o frontend desenhar a miniatura sem buscar os streams
+func routeThumbnail(summaryPolyline string) [][]float64 {
+	if summaryPolyline == "" {
+		return nil
+	}
+
+	coords, err := gps.DecodePolyline(summaryPolyline)
+	if err != nil {
+		log.Printf("⚠️ Polilinha resumida inválida: %v", err)
+		return nil
+	}
+	if len(coords) < 2 {
+		return nil
+	}
+
+	// Projeção equiretangular na latitude média
+	sumLat := 0.0
+	for _, c := range coords {
+		sumLat += c[0]
+	}
+	lngScale := math.Cos(sumLat / float64(len(coords)) * math.Pi / 180)
+
+	minX, minY := math.MaxFloat64, math.MaxFloat64
+	maxX, maxY := -math.MaxFloat64, -math.MaxFloat64
+	projected := make([][2]float64, len(coords))
+	for i, c := range coords {
+		x, y := c[1]*lngScale, -c[0]
+		projected[i] = [2]float64{x, y}
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+
+	span := math.Max(maxX-minX, maxY-minY)
+	if span == 0 {
+		return nil
+	}
+	offsetX := (span - (maxX - minX)) / 2
+	offsetY := (span - (maxY - minY)) / 2
+
+	step := (len(projected) + maxThumbnailPoints - 1) / maxThumbnailPoints
+	thumbnail := make([][]float64, 0, maxThumbnailPoints+1)
+	for i := 0; i < len(projected); i += step {
+		thumbnail = append(thumbnail, thumbnailPoint(projected[i], minX, minY, offsetX, offsetY, span))
+	}
+	if (len(projected)-1)%step != 0 {
+		thumbnail = append(thumbnail, thumbnailPoint(projected[len(projected)-1], minX, minY, offsetX, offsetY, span))
+	}
+
+	return thumbnail
+}
+
+func thumbnailPoint(p [2]float64, minX, minY, offsetX, offsetY, span float64) []float64 {
+	x := (p[0] - minX + offsetX) / span
+	y := (p[1] - minY + offsetY) / span
+	return []float64{math.Round(x*1000) / 1000, math.Round(y*1000) / 1000}
+}
+
 // GetActivities - mantida para compatibilidade, mas recomenda-se usar GetActivitiesPage
 func (h *ActivityHandler) GetActivities() ([]FrontendActivity, error) {
 	result, err := h.GetActivitiesPage(1)
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"testing"
+
+	"strava-overlay/internal/strava"
+)
+
+func TestConvertToFrontendActivityHasGPS(t *testing.T) {
+	tests := []struct {
+		name     string
+		polyline string
+		want     bool
+	}{
+		{"sem polilinha", "", false},
+		{"polilinha inválida", "_p~iF~ps|U_", false},
+		{"um único ponto", "_p~iF~ps|U", false},
+		{"trajeto", "_p~iF~ps|U_ulLnnqC_mqNvxq`@", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activity := convertToFrontendActivity(strava.Activity{Map: strava.Map{SummaryPolyline: tt.polyline}})
+			if activity.HasGPS != tt.want {
+				t.Errorf("HasGPS = %v, esperado %v", activity.HasGPS, tt.want)
+			}
+			if activity.HasGPS != (len(activity.Thumbnail) > 1) {
+				t.Errorf("HasGPS = %v difere da miniatura com %d pontos", activity.HasGPS, len(activity.Thumbnail))
+			}
+		})
+	}
+}
@@ -11,9 +11,21 @@ import (
 
 // AuthStatus representa o status da autenticação
 type AuthStatus struct {
-	IsAuthenticated bool   `json:"is_authenticated"`
-	Message         string `json:"message"`
-	Error           string `json:"error,omitempty"`
+	IsAuthenticated bool     `json:"is_authenticated"`
+	Message         string   `json:"message"`
+	Error           string   `json:"error,omitempty"`
+	AthleteID       int64    `json:"athlete_id,omitempty"`
+	AthleteName     string   `json:"athlete_name,omitempty"`
+	Scopes          []string `json:"scopes,omitempty"`
+	CanReadPrivate  bool     `json:"can_read_private"`
+}
+
+// FrontendAccount representa uma conta Strava conectada
+type FrontendAccount struct {
+	AthleteID int64  `json:"athlete_id"`
+	Name      string `json:"name"`
+	Username  string `json:"username"`
+	Active    bool   `json:"active"`
 }
 
 // AuthHandler gerencia todas as operações relacionadas à autenticação
@@ -50,10 +62,12 @@ func (h *AuthHandler) CheckAuthenticationStatus(ctx context.Context) AuthStatus
 
 	log.Printf("✅ Token válido encontrado - Cliente Strava inicializado")
 
-	return AuthStatus{
+	status := AuthStatus{
 		IsAuthenticated: true,
 		Message:         "Conectado automaticamente ao Strava",
 	}
+	h.fillActiveAccount(&status, client)
+	return status
 }
 
 // AuthenticateStrava handles Strava authentication (para autenticação manual)
@@ -71,3 +85,177 @@ func (h *AuthHandler) AuthenticateStrava(ctx context.Context) error {
 	log.Printf("✅ Autenticação manual concluída com sucesso")
 	return nil
 }
+
+// ListAccounts retorna as contas Strava conectadas nesta estação
+func (h *AuthHandler) ListAccounts() []FrontendAccount {
+	accounts := h.stravaAuth.Accounts()
+	active, hasActive := accounts.Active()
+
+	list := accounts.List()
+	frontendAccounts := make([]FrontendAccount, len(list))
+	for i, account := range list {
+		frontendAccounts[i] = FrontendAccount{
+			AthleteID: account.AthleteID,
+			Name:      account.DisplayName(),
+			Username:  account.Username,
+			Active:    hasActive && account.AthleteID == active.AthleteID,
+		}
+	}
+	return frontendAccounts
+}
+
+// SwitchAccount troca a conta ativa e recria o cliente Strava com o token dela
+func (h *AuthHandler) SwitchAccount(ctx context.Context, athleteID int64) AuthStatus {
+	account, err := h.stravaAuth.Accounts().SetActive(athleteID)
+	if err != nil {
+		return AuthStatus{
+			IsAuthenticated: false,
+			Message:         "Conta não encontrada",
+			Error:           err.Error(),
+		}
+	}
+
+	log.Printf("👤 Trocando para a conta %s (%d)", account.DisplayName(), account.AthleteID)
+	return h.CheckAuthenticationStatus(ctx)
+}
+
+// AddAccount autoriza uma nova conta Strava e passa a usá-la
+func (h *AuthHandler) AddAccount(ctx context.Context) AuthStatus {
+	log.Printf("➕ Adicionando nova conta Strava...")
+
+	token, err := h.stravaAuth.AddAccount(ctx)
+	if err != nil {
+		return AuthStatus{
+			IsAuthenticated: false,
+			Message:         "Falha ao adicionar conta",
+			Error:           err.Error(),
+		}
+	}
+
+	client := strava.NewClient(token)
+	h.setStravaClient(client)
+
+	status := AuthStatus{
+		IsAuthenticated: true,
+		Message:         "Conta adicionada",
+	}
+	h.fillActiveAccount(&status, client)
+	return status
+}
+
+// Logout revoga o acesso da conta ativa e remove o token salvo. Se outra conta conectada
+// passa a ser a ativa, o cliente é recriado com o token dela e o status retornado é o dessa
+// conta; sem token utilizável, ninguém fica conectado até o usuário entrar novamente.
+func (h *AuthHandler) Logout(ctx context.Context) AuthStatus {
+	account, err := h.stravaAuth.Logout(ctx)
+	if err != nil {
+		h.setStravaClient(nil)
+		log.Printf("❌ Falha ao sair da conta: %v", err)
+		return AuthStatus{
+			IsAuthenticated: false,
+			Message:         "Falha ao sair da conta",
+			Error:           err.Error(),
+		}
+	}
+	log.Printf("👋 Conta %s desconectada", account.DisplayName())
+
+	next, ok := h.stravaAuth.Accounts().Active()
+	if !ok {
+		h.setStravaClient(nil)
+		return AuthStatus{
+			IsAuthenticated: false,
+			Message:         "Desconectado do Strava",
+		}
+	}
+
+	token, err := h.stravaAuth.ActiveToken(ctx)
+	if err != nil {
+		h.setStravaClient(nil)
+		log.Printf("⚠️ Token da conta %s indisponível: %v", next.DisplayName(), err)
+		return AuthStatus{
+			IsAuthenticated: false,
+			Message:         fmt.Sprintf("Conta %s desconectada; entre novamente como %s", account.DisplayName(), next.DisplayName()),
+			Error:           err.Error(),
+		}
+	}
+
+	client := strava.NewClient(token)
+	h.setStravaClient(client)
+	log.Printf("👤 Usando a conta %s (%d)", next.DisplayName(), next.AthleteID)
+
+	status := AuthStatus{
+		IsAuthenticated: true,
+		Message:         fmt.Sprintf("Conta %s desconectada; usando %s", account.DisplayName(), next.DisplayName()),
+	}
+	h.fillActiveAccount(&status, client)
+	return status
+}
+
+// RequestPrivateActivitiesAccess pede ao Strava o escopo activity:read_all para a conta
+func (h *AuthHandler) RequestPrivateActivitiesAccess(ctx context.Context) AuthStatus {
+	log.Printf("🔓 Solicitando acesso a atividades privadas...")
+
+	token, err := h.stravaAuth.UpgradeScope(ctx)
+	if err != nil {
+		return AuthStatus{
+			IsAuthenticated: h.hasActiveAccount(),
+			Message:         "Falha ao solicitar acesso a atividades privadas",
+			Error:           err.Error(),
+		}
+	}
+
+	client := strava.NewClient(token)
+	h.setStravaClient(client)
+
+	status := AuthStatus{
+		IsAuthenticated: true,
+		Message:         "Permissões atualizadas",
+	}
+	h.fillActiveAccount(&status, client)
+
+	if !status.CanReadPrivate {
+		status.Message = "O acesso a atividades privadas não foi concedido"
+	}
+	return status
+}
+
+// hasActiveAccount informa se ainda existe uma conta ativa após uma operação que falhou
+func (h *AuthHandler) hasActiveAccount() bool {
+	_, ok := h.stravaAuth.Accounts().Active()
+	return ok
+}
+
+// fillActiveAccount preenche o atleta da conta ativa, identificando a conta provisória
+// herdada de versões sem suporte a múltiplas contas
+func (h *AuthHandler) fillActiveAccount(status *AuthStatus, client *strava.Client) {
+	accounts := h.stravaAuth.Accounts()
+	account, ok := accounts.Active()
+	if !ok {
+		return
+	}
+
+	if account.AthleteID == 0 {
+		athlete, err := client.GetAthlete()
+		if err != nil {
+			log.Printf("⚠️ Não foi possível identificar o atleta: %v", err)
+			return
+		}
+
+		account = auth.Account{
+			AthleteID: athlete.ID,
+			Username:  athlete.Username,
+			FirstName: athlete.FirstName,
+			LastName:  athlete.LastName,
+		}
+		if err := accounts.Identify(account); err != nil {
+			log.Printf("⚠️ Não foi possível associar o token ao atleta: %v", err)
+		} else if identified, ok := accounts.Active(); ok {
+			account = identified
+		}
+	}
+
+	status.AthleteID = account.AthleteID
+	status.AthleteName = account.DisplayName()
+	status.Scopes = account.Scopes
+	status.CanReadPrivate = account.HasScope(auth.ScopeActivityReadAll)
+}
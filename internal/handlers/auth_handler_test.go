@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"strava-overlay/internal/auth"
+	"strava-overlay/internal/strava"
+)
+
+// memoryStore guarda o token em memória, no lugar do keyring
+type memoryStore struct {
+	mutex sync.Mutex
+	token *oauth2.Token
+}
+
+func (m *memoryStore) Load() (*oauth2.Token, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.token == nil {
+		return nil, auth.ErrTokenNotFound
+	}
+	token := *m.token
+	return &token, nil
+}
+
+func (m *memoryStore) Save(token *oauth2.Token) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	saved := *token
+	m.token = &saved
+	return nil
+}
+
+func (m *memoryStore) Delete() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.token = nil
+	return nil
+}
+
+func (m *memoryStore) Name() string { return "memória" }
+
+// validToken não expira durante o teste
+func validToken(access string) *oauth2.Token {
+	return &oauth2.Token{AccessToken: access, RefreshToken: "refresh-" + access, Expiry: time.Now().Add(time.Hour)}
+}
+
+// unusableToken está expirado e não tem refresh token, então nem a revogação nem a renovação
+// chegam a acessar a rede
+func unusableToken(access string) *oauth2.Token {
+	return &oauth2.Token{AccessToken: access, Expiry: time.Now().Add(-time.Hour)}
+}
+
+// newTestAuthHandler cria o handler com as contas informadas, na ordem, sendo a última a ativa.
+// Retorna também o último cliente definido pelo handler.
+func newTestAuthHandler(t *testing.T, accounts []auth.Account, tokens []*oauth2.Token) (*AuthHandler, **strava.Client) {
+	t.Helper()
+
+	stores := map[string]*memoryStore{}
+	manager := auth.NewAccountManager(t.TempDir(), func(key string) auth.TokenStore {
+		if stores[key] == nil {
+			stores[key] = &memoryStore{}
+		}
+		return stores[key]
+	})
+	for i, account := range accounts {
+		if err := manager.Upsert(account, tokens[i]); err != nil {
+			t.Fatalf("Upsert: %v", err)
+		}
+	}
+
+	client := new(*strava.Client)
+	*client = strava.NewClient(tokens[len(tokens)-1])
+	handler := NewAuthHandler(auth.NewStravaAuth("client-id", "client-secret", manager), func(c *strava.Client) {
+		*client = c
+	})
+	return handler, client
+}
+
+var (
+	ana   = auth.Account{AthleteID: 1, FirstName: "Ana", LastName: "Silva"}
+	bruno = auth.Account{AthleteID: 2, FirstName: "Bruno", LastName: "Costa"}
+)
+
+func TestLogoutSwitchesToRemainingAccount(t *testing.T) {
+	handler, client := newTestAuthHandler(t,
+		[]auth.Account{bruno, ana},
+		[]*oauth2.Token{validToken("bruno"), unusableToken("ana")})
+
+	status := handler.Logout(context.Background())
+
+	if !status.IsAuthenticated {
+		t.Fatalf("a conta restante deveria assumir: %+v", status)
+	}
+	if status.AthleteID != bruno.AthleteID || status.AthleteName != "Bruno Costa" {
+		t.Errorf("status deveria ser da conta de Bruno, obtido %d %q", status.AthleteID, status.AthleteName)
+	}
+	if *client == nil {
+		t.Fatal("o cliente Strava deveria ser recriado para a conta restante")
+	}
+
+	active, ok := handler.stravaAuth.Accounts().Active()
+	if !ok || active.AthleteID != bruno.AthleteID {
+		t.Errorf("a conta ativa salva deveria ser a de Bruno, obtido %+v", active)
+	}
+	accounts := handler.ListAccounts()
+	if len(accounts) != 1 || !accounts[0].Active || accounts[0].AthleteID != bruno.AthleteID {
+		t.Errorf("deveria restar apenas a conta de Bruno, ativa: %+v", accounts)
+	}
+}
+
+func TestLogoutLastAccount(t *testing.T) {
+	handler, client := newTestAuthHandler(t,
+		[]auth.Account{ana},
+		[]*oauth2.Token{unusableToken("ana")})
+
+	status := handler.Logout(context.Background())
+
+	if status.IsAuthenticated || status.Error != "" {
+		t.Errorf("sem outras contas o status deveria ser desconectado, sem erro: %+v", status)
+	}
+	if *client != nil {
+		t.Error("o cliente Strava deveria ser descartado")
+	}
+	if len(handler.ListAccounts()) != 0 {
+		t.Error("nenhuma conta deveria restar")
+	}
+}
+
+func TestLogoutWithUnusableRemainingToken(t *testing.T) {
+	handler, client := newTestAuthHandler(t,
+		[]auth.Account{bruno, ana},
+		[]*oauth2.Token{unusableToken("bruno"), unusableToken("ana")})
+
+	status := handler.Logout(context.Background())
+
+	// Sem token utilizável não se entra silenciosamente como outro atleta
+	if status.IsAuthenticated {
+		t.Errorf("não deveria autenticar com um token inutilizável: %+v", status)
+	}
+	if status.Error == "" {
+		t.Error("o status deveria explicar por que a conta restante não foi usada")
+	}
+	if *client != nil {
+		t.Error("o cliente Strava deveria ser descartado")
+	}
+}
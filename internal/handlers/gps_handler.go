@@ -19,6 +19,102 @@ type FrontendGPSPoint struct {
 	Altitude float64 `json:"altitude"`
 	Bearing  float64 `json:"bearing"`
 	GForce   float64 `json:"gForce"`
+
+	LongitudinalG float64 `json:"longitudinalG"`
+	LateralG      float64 `json:"lateralG"`
+
+	Distance    float64 `json:"distance"`    // Metros desde o início da atividade
+	ElapsedTime float64 `json:"elapsedTime"` // Segundos desde o início da atividade
+	MovingTime  float64 `json:"movingTime"`  // Segundos em movimento
+	Ascent      float64 `json:"ascent"`
+	Descent     float64 `json:"descent"`
+	Grade       float64 `json:"grade"`
+	Paused      bool    `json:"paused"`
+	HeartRate   float64 `json:"heartRate"`
+}
+
+// FrontendTrackSegment representa uma parada, pausa automática ou lacuna de dados da trilha
+type FrontendTrackSegment struct {
+	Type            string  `json:"type"` // "stop", "auto_pause" ou "gap"
+	Start           string  `json:"start"`
+	End             string  `json:"end"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Lat             float64 `json:"lat"`
+	Lng             float64 `json:"lng"`
+}
+
+// FrontendLap representa uma volta da atividade com a posição onde começou
+type FrontendLap struct {
+	Number       int     `json:"number"`
+	Name         string  `json:"name"`
+	Start        string  `json:"start"`
+	ElapsedTime  int     `json:"elapsed_time"`
+	MovingTime   int     `json:"moving_time"`
+	Distance     float64 `json:"distance"`
+	AverageSpeed float64 `json:"average_speed"`
+	Lat          float64 `json:"lat"`
+	Lng          float64 `json:"lng"`
+}
+
+// FrontendSegmentEffort representa um esforço de segmento com a polilinha do trecho percorrido
+type FrontendSegmentEffort struct {
+	ID           int64       `json:"id"`
+	SegmentID    int64       `json:"segment_id"`
+	Name         string      `json:"name"`
+	Start        string      `json:"start"`
+	ElapsedTime  int         `json:"elapsed_time"`
+	Distance     float64     `json:"distance"`
+	AverageGrade float64     `json:"average_grade"`
+	KOMRank      int         `json:"kom_rank"` // 0 quando fora do top 10
+	PRRank       int         `json:"pr_rank"`  // 0 quando fora do top 3
+	Polyline     [][]float64 `json:"polyline"` // Pares [lat, lng]
+}
+
+// FrontendHighlightClip é um vídeo em que os destaques são procurados; manual_start_time
+// (RFC3339) substitui o horário de criação do vídeo, como na renderização
+type FrontendHighlightClip struct {
+	VideoPath       string `json:"video_path"`
+	ManualStartTime string `json:"manual_start_time,omitempty"`
+}
+
+// FrontendHighlightRange é o trecho de um vídeo, em segundos, que mostra um destaque
+type FrontendHighlightRange struct {
+	VideoPath string  `json:"video_path"`
+	Start     float64 `json:"start"`
+	End       float64 `json:"end"`
+}
+
+// FrontendHighlight é um destaque detectado na telemetria, do mais ao menos pontuado
+type FrontendHighlight struct {
+	Start         string                   `json:"start"` // RFC3339
+	End           string                   `json:"end"`
+	Score         float64                  `json:"score"`      // 0 a 1
+	Reason        string                   `json:"reason"`     // speed, gforce, grade, heart_rate ou segment
+	PeakSpeed     float64                  `json:"peak_speed"` // km/h
+	MaxGForce     float64                  `json:"max_gforce"`
+	SteepestGrade float64                  `json:"steepest_grade"`
+	HeartRateRise float64                  `json:"heart_rate_rise"`
+	Segment       string                   `json:"segment,omitempty"`
+	Clips         []FrontendHighlightRange `json:"clips"`
+}
+
+// FrontendSimplifyOptions são os parâmetros de simplificação pedidos pelo mapa. Zoom define a
+// tolerância quando ToleranceMeters é zero; TargetPoints limita o número de pontos.
+type FrontendSimplifyOptions struct {
+	Zoom            int     `json:"zoom"`
+	ToleranceMeters float64 `json:"tolerance_meters"`
+	TargetPoints    int     `json:"target_points"`
+	Algorithm       string  `json:"algorithm"` // "douglas-peucker" (padrão) ou "visvalingam"
+}
+
+// FrontendActivityMatch representa uma atividade candidata para um vídeo
+type FrontendActivityMatch struct {
+	Activity       FrontendActivity `json:"activity"`
+	TimezoneModel  string           `json:"timezone_model"`
+	VideoStart     string           `json:"video_start"`
+	VideoEnd       string           `json:"video_end"`
+	OverlapSeconds float64          `json:"overlap_seconds"`
+	OverlapPercent float64          `json:"overlap_percent"`
 }
 
 // GPSHandler gerencia todas as operações relacionadas aos dados GPS
@@ -50,6 +146,179 @@ func (h *GPSHandler) GetGPSPointForVideoTime(activityID int64, videoPath string)
 	return h.convertToFrontendGPSPoint(point), nil
 }
 
+// FindActivitiesForVideo retorna as atividades que se sobrepõem ao vídeo, ordenadas por sobreposição
+func (h *GPSHandler) FindActivitiesForVideo(videoPath string) ([]FrontendActivityMatch, error) {
+	client := h.getStravaClient()
+	if client == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	matches, err := h.gpsService.FindActivitiesForVideo(client, videoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	frontendMatches := make([]FrontendActivityMatch, len(matches))
+	for i, match := range matches {
+		frontendMatches[i] = FrontendActivityMatch{
+			Activity:       convertToFrontendActivity(match.Activity),
+			TimezoneModel:  match.TimezoneModel,
+			VideoStart:     match.VideoStart.Format(time.RFC3339),
+			VideoEnd:       match.VideoEnd.Format(time.RFC3339),
+			OverlapSeconds: match.OverlapSeconds,
+			OverlapPercent: match.OverlapPercent,
+		}
+	}
+
+	return frontendMatches, nil
+}
+
+// GetTrackSegments retorna as paradas, pausas e lacunas da atividade para exibição no mapa
+func (h *GPSHandler) GetTrackSegments(activityID int64) ([]FrontendTrackSegment, error) {
+	client := h.getStravaClient()
+	if client == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	segments, err := h.gpsService.GetTrackSegments(client, activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	frontendSegments := make([]FrontendTrackSegment, len(segments))
+	for i, segment := range segments {
+		frontendSegments[i] = FrontendTrackSegment{
+			Type:            string(segment.Type),
+			Start:           segment.Start.Format(time.RFC3339),
+			End:             segment.End.Format(time.RFC3339),
+			DurationSeconds: segment.Duration().Seconds(),
+			Lat:             segment.Lat,
+			Lng:             segment.Lng,
+		}
+	}
+
+	return frontendSegments, nil
+}
+
+// GetActivityLaps retorna as voltas da atividade para listagem e navegação no mapa
+func (h *GPSHandler) GetActivityLaps(activityID int64) ([]FrontendLap, error) {
+	client := h.getStravaClient()
+	if client == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	laps, err := h.gpsService.GetActivityLaps(client, activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	frontendLaps := make([]FrontendLap, len(laps))
+	for i, lap := range laps {
+		number := lap.LapIndex
+		if number == 0 {
+			number = i + 1
+		}
+		frontendLaps[i] = FrontendLap{
+			Number:       number,
+			Name:         lap.Name,
+			Start:        lap.StartDate.Format(time.RFC3339),
+			ElapsedTime:  lap.ElapsedTime,
+			MovingTime:   lap.MovingTime,
+			Distance:     lap.Distance,
+			AverageSpeed: lap.AverageSpeed,
+			Lat:          lap.StartPoint.Lat,
+			Lng:          lap.StartPoint.Lng,
+		}
+	}
+
+	return frontendLaps, nil
+}
+
+// GetSegmentEfforts retorna os esforços de segmento da atividade para destaque no mapa
+func (h *GPSHandler) GetSegmentEfforts(activityID int64) ([]FrontendSegmentEffort, error) {
+	client := h.getStravaClient()
+	if client == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	efforts, err := h.gpsService.GetSegmentEfforts(client, activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	frontendEfforts := make([]FrontendSegmentEffort, len(efforts))
+	for i, effort := range efforts {
+		polyline := make([][]float64, len(effort.Track))
+		for j, point := range effort.Track {
+			polyline[j] = []float64{point.Lat, point.Lng}
+		}
+
+		frontendEfforts[i] = FrontendSegmentEffort{
+			ID:           effort.ID,
+			SegmentID:    effort.Segment.ID,
+			Name:         effort.Name,
+			Start:        effort.StartDate.Format(time.RFC3339),
+			ElapsedTime:  effort.ElapsedTime,
+			Distance:     effort.Distance,
+			AverageGrade: effort.Segment.AverageGrade,
+			Polyline:     polyline,
+		}
+		if effort.KOMRank != nil {
+			frontendEfforts[i].KOMRank = *effort.KOMRank
+		}
+		if effort.PRRank != nil {
+			frontendEfforts[i].PRRank = *effort.PRRank
+		}
+	}
+
+	return frontendEfforts, nil
+}
+
+// DetectHighlights retorna os melhores momentos da atividade nos vídeos informados
+func (h *GPSHandler) DetectHighlights(activityID int64, clips []FrontendHighlightClip) ([]FrontendHighlight, error) {
+	client := h.getStravaClient()
+	if client == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	highlightClips := make([]services.HighlightClip, len(clips))
+	for i, clip := range clips {
+		highlightClips[i] = services.HighlightClip{VideoPath: clip.VideoPath, ManualStartTime: clip.ManualStartTime}
+	}
+
+	highlights, err := h.gpsService.DetectHighlights(client, activityID, highlightClips)
+	if err != nil {
+		return nil, err
+	}
+
+	frontendHighlights := make([]FrontendHighlight, len(highlights))
+	for i, highlight := range highlights {
+		ranges := make([]FrontendHighlightRange, len(highlight.Clips))
+		for j, clip := range highlight.Clips {
+			ranges[j] = FrontendHighlightRange{
+				VideoPath: clip.VideoPath,
+				Start:     clip.Start.Seconds(),
+				End:       clip.End.Seconds(),
+			}
+		}
+
+		frontendHighlights[i] = FrontendHighlight{
+			Start:         highlight.Start.Format(time.RFC3339),
+			End:           highlight.End.Format(time.RFC3339),
+			Score:         highlight.Score,
+			Reason:        highlight.Reason,
+			PeakSpeed:     highlight.PeakSpeed * 3.6,
+			MaxGForce:     highlight.MaxGForce,
+			SteepestGrade: highlight.SteepestGrade,
+			HeartRateRise: highlight.HeartRateRise,
+			Segment:       highlight.Segment,
+			Clips:         ranges,
+		}
+	}
+
+	return frontendHighlights, nil
+}
+
 // GetGPSPointForMapClick encontra o ponto GPS mais próximo de um clique no mapa
 func (h *GPSHandler) GetGPSPointForMapClick(activityID int64, lat, lng float64) (FrontendGPSPoint, error) {
 	client := h.getStravaClient()
@@ -65,6 +334,31 @@ func (h *GPSHandler) GetGPSPointForMapClick(activityID int64, lat, lng float64)
 	return h.convertToFrontendGPSPoint(point), nil
 }
 
+// GetGPSPointForSegmentClick encontra o ponto GPS mais próximo de um clique em um trecho do
+// trajeto exibido; from e to (RFC3339) são os instantes das pontas do trecho
+func (h *GPSHandler) GetGPSPointForSegmentClick(activityID int64, lat, lng float64, from, to string) (FrontendGPSPoint, error) {
+	client := h.getStravaClient()
+	if client == nil {
+		return FrontendGPSPoint{}, fmt.Errorf("not authenticated")
+	}
+
+	start, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return FrontendGPSPoint{}, fmt.Errorf("início do trecho inválido: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, to)
+	if err != nil {
+		return FrontendGPSPoint{}, fmt.Errorf("fim do trecho inválido: %w", err)
+	}
+
+	point, err := h.gpsService.GetGPSPointForSegmentClick(client, activityID, lat, lng, start, end)
+	if err != nil {
+		return FrontendGPSPoint{}, err
+	}
+
+	return h.convertToFrontendGPSPoint(point), nil
+}
+
 // GetAllGPSPoints retorna pontos GPS selecionados inteligentemente para marcadores
 func (h *GPSHandler) GetAllGPSPoints(activityID int64) ([]FrontendGPSPoint, error) {
 	client := h.getStravaClient()
@@ -96,6 +390,29 @@ func (h *GPSHandler) GetFullGPSTrajectory(activityID int64) ([]FrontendGPSPoint,
 	return h.convertToFrontendGPSPoints(points), nil
 }
 
+// GetSimplifiedTrajectory retorna o trajeto simplificado para o zoom ou a quantidade de pontos
+// pedida, preservando paradas e curvas fechadas
+func (h *GPSHandler) GetSimplifiedTrajectory(activityID int64, options FrontendSimplifyOptions) ([]FrontendGPSPoint, error) {
+	client := h.getStravaClient()
+	if client == nil {
+		return nil, fmt.Errorf("not authenticated")
+	}
+
+	opts := gps.DefaultSimplifyOptions()
+	opts.Tolerance = options.ToleranceMeters
+	opts.TargetPoints = options.TargetPoints
+	if options.Algorithm == gps.SimplifyVisvalingam {
+		opts.Algorithm = gps.SimplifyVisvalingam
+	}
+
+	points, err := h.gpsService.GetSimplifiedTrajectory(client, activityID, options.Zoom, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.convertToFrontendGPSPoints(points), nil
+}
+
 // GetGPSPointsWithDensity - Versão com densidade customizável
 func (h *GPSHandler) GetGPSPointsWithDensity(activityID int64, density string) ([]FrontendGPSPoint, error) {
 	client := h.getStravaClient()
@@ -112,6 +429,24 @@ func (h *GPSHandler) GetGPSPointsWithDensity(activityID int64, density string) (
 	return h.convertToFrontendGPSPoints(points), nil
 }
 
+// GetEncodedGPSTrajectory retorna a trajetória na densidade pedida codificada como polilinha,
+// bem menor que a lista de pontos quando só as posições são necessárias
+func (h *GPSHandler) GetEncodedGPSTrajectory(activityID int64, density string) (string, error) {
+	client := h.getStravaClient()
+	if client == nil {
+		return "", fmt.Errorf("not authenticated")
+	}
+
+	points, err := h.gpsService.GetGPSPointsWithDensity(client, activityID, density)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := gps.EncodePointsPolyline(points)
+	log.Printf("DEBUG: Densidade '%s' - %d pontos codificados em %d bytes", density, len(points), len(encoded))
+	return encoded, nil
+}
+
 // Métodos auxiliares para conversão de tipos
 
 func (h *GPSHandler) convertToFrontendGPSPoint(point gps.GPSPoint) FrontendGPSPoint {
@@ -123,6 +458,18 @@ func (h *GPSHandler) convertToFrontendGPSPoint(point gps.GPSPoint) FrontendGPSPo
 		Altitude: point.Altitude,
 		Bearing:  point.Bearing,
 		GForce:   point.GForce,
+
+		LongitudinalG: point.LongitudinalG,
+		LateralG:      point.LateralG,
+
+		Distance:    point.Distance,
+		ElapsedTime: point.ElapsedTime.Seconds(),
+		MovingTime:  point.MovingTime.Seconds(),
+		Ascent:      point.Ascent,
+		Descent:     point.Descent,
+		Grade:       point.Grade,
+		Paused:      point.Paused,
+		HeartRate:   point.HeartRate,
 	}
 }
 
@@ -2,10 +2,10 @@ package handlers
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
+	"time"
 
 	"strava-overlay/internal/services"
 	"strava-overlay/internal/strava"
@@ -18,6 +18,41 @@ type VideoHandler struct {
 	gpsService      *services.GPSService
 }
 
+// FrontendTimeWindow é um trecho a renderizar: em segundos do vídeo ou, quando gpsStart e
+// gpsEnd são informados (RFC3339), em horário GPS da atividade
+type FrontendTimeWindow struct {
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	GPSStart string  `json:"gpsStart,omitempty"`
+	GPSEnd   string  `json:"gpsEnd,omitempty"`
+}
+
+// ParseTimeWindows converte os trechos vindos do frontend para o serviço de vídeo
+func ParseTimeWindows(windows []FrontendTimeWindow) ([]services.TimeWindow, error) {
+	parsed := make([]services.TimeWindow, 0, len(windows))
+	for i, window := range windows {
+		tw := services.TimeWindow{
+			Start: time.Duration(window.Start * float64(time.Second)),
+			End:   time.Duration(window.End * float64(time.Second)),
+		}
+
+		var err error
+		if window.GPSStart != "" {
+			if tw.GPSStart, err = time.Parse(time.RFC3339, window.GPSStart); err != nil {
+				return nil, fmt.Errorf("início do trecho %d inválido: %w", i+1, err)
+			}
+		}
+		if window.GPSEnd != "" {
+			if tw.GPSEnd, err = time.Parse(time.RFC3339, window.GPSEnd); err != nil {
+				return nil, fmt.Errorf("fim do trecho %d inválido: %w", i+1, err)
+			}
+		}
+
+		parsed = append(parsed, tw)
+	}
+	return parsed, nil
+}
+
 // NewVideoHandler cria um novo handler de vídeo
 func NewVideoHandler(
 	getStravaClient func() *strava.Client,
@@ -60,6 +95,7 @@ func (h *VideoHandler) ProcessVideoOverlay(activityID int64, videoPath string, m
 		manualStartTimeStr,
 		overlayPosition,
 		h.gpsService,
+		services.RenderOptions{},
 	)
 
 	if err != nil {
@@ -67,10 +103,34 @@ func (h *VideoHandler) ProcessVideoOverlay(activityID int64, videoPath string, m
 		return "", err
 	}
 
-	homeDir, _ := os.UserHomeDir()
-	outputDir := filepath.Join(homeDir, "Strava Add Overlay")
-	fullOutputPath := filepath.Join(outputDir, filepath.Base(outputPath))
+	log.Printf("✅ Vídeo processado com sucesso: %s", outputPath)
+	return outputPath, nil
+}
+
+// PreviewOverlayFrame retorna o quadro do vídeo em videoTimeSeconds com o overlay aplicado,
+// como data URL JPEG reduzido pronto para um <img>
+func (h *VideoHandler) PreviewOverlayFrame(activityID int64, videoPath string, manualStartTimeStr string, overlayPosition string, videoTimeSeconds float64) (string, error) {
+	client := h.getStravaClient()
+	if client == nil {
+		return "", fmt.Errorf("not authenticated")
+	}
+
+	started := time.Now()
+	frame, err := h.videoService.PreviewOverlayFrame(
+		context.Background(),
+		client,
+		activityID,
+		videoPath,
+		manualStartTimeStr,
+		overlayPosition,
+		time.Duration(videoTimeSeconds*float64(time.Second)),
+		h.gpsService,
+	)
+	if err != nil {
+		log.Printf("❌ Erro na pré-visualização do overlay: %v", err)
+		return "", err
+	}
 
-	log.Printf("✅ Vídeo processado com sucesso: %s", fullOutputPath)
-	return fullOutputPath, nil
+	log.Printf("🖼️ Pré-visualização em %.1fs gerada em %v", videoTimeSeconds, time.Since(started).Round(time.Millisecond))
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(frame), nil
 }
@@ -2,35 +2,194 @@ package overlay
 
 import (
 	"fmt"
+	"image"
 	"image/color"
 	"log"
 	"math"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"strava-overlay/internal/gps"
 
 	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
 	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
 )
 
 // Generator cria imagens de overlay.
 type Generator struct {
 	width, height    int
+	scale            float64 // Fator aplicado ao desenho base de BaseCanvasSize pixels
 	tempDir          string
 	fontLoaded       bool
+	fontFailed       bool
 	fontPath         string
+	ttf              *truetype.Font        // Fonte do sistema já interpretada, compartilhada entre workers
+	faces            map[float64]font.Face // Faces por tamanho em pixels; uma por worker (ver renderWorker)
+	fadeMask         *image.Alpha          // Máscara do velocímetro para o layout em fadeMaskKey
+	fadeMaskKey      [3]float64
 	overlayPosition  string
+	gMeterStyle      string
+	widgets          []string
+	stopBehavior     string
+	stopSegments     []gps.TrackSegment
+	laps             *gps.LapTimeline
+	efforts          *gps.EffortTimeline
+	showPaused       bool
 	progressCallback ProgressCallback
 }
 
+// Comportamento do overlay durante paradas e pausas automáticas
+const (
+	StopBehaviorNone   = "none"   // Continua desenhando normalmente
+	StopBehaviorFreeze = "freeze" // Mantém o último quadro antes da parada
+	StopBehaviorHide   = "hide"   // Esconde o overlay até o movimento recomeçar
+)
+
+// Estilos do widget de G-force
+const (
+	GMeterText           = "text"            // Apenas a magnitude em G
+	GMeterFrictionCircle = "friction-circle" // Círculo de atrito com as componentes lateral e longitudinal
+)
+
 type ProgressCallback func(current, total int)
 
+// BaseCanvasSize é o lado do canvas, em pixels, do desenho base do overlay, pensado para
+// vídeos 1080p. Medidas, fontes e traços do desenho são multiplicados pela escala do gerador.
+const BaseCanvasSize = 340
+
+// referenceShortSide é o lado menor do vídeo em que o overlay tem o tamanho do desenho base
+const referenceShortSide = 1080.0
+
+// ScaleForVideo retorna a escala do overlay para um vídeo com as dimensões de exibição
+// informadas: proporcional ao lado menor (a largura em vídeos verticais), multiplicada pelo
+// fator do usuário e limitada para que o canvas caiba no vídeo. Sem dimensões, usa só o fator.
+func ScaleForVideo(width, height int, userScale float64) float64 {
+	if userScale <= 0 {
+		userScale = 1
+	}
+	shortSide := float64(min(width, height))
+	if shortSide <= 0 {
+		return userScale
+	}
+
+	scale := shortSide / referenceShortSide * userScale
+	maxScale := shortSide * 0.9 / BaseCanvasSize
+	return math.Max(0.25, math.Min(scale, maxScale))
+}
+
+// ParseScale interpreta o fator de escala do overlay definido pelo usuário ("1", "1.5").
+// Vazio mantém 1; valores inválidos retornam 1 e um erro.
+func ParseScale(value string) (float64, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "x")
+	if value == "" {
+		return 1, nil
+	}
+	scale, err := strconv.ParseFloat(value, 64)
+	if err != nil || scale < 0.25 || scale > 4 {
+		return 1, fmt.Errorf("escala do overlay inválida '%s' (use de 0.25 a 4)", value)
+	}
+	return scale, nil
+}
+
+// SetScale redimensiona o canvas e todo o desenho pelo fator informado (ver ScaleForVideo)
+func (g *Generator) SetScale(scale float64) {
+	if scale <= 0 {
+		scale = 1
+	}
+	g.scale = scale
+	g.width = int(math.Round(BaseCanvasSize * scale))
+	g.height = g.width
+	log.Printf("📐 Overlay em escala %.2f (%dx%d)", scale, g.width, g.height)
+}
+
+// px converte uma medida do desenho base para pixels do canvas
+func (g *Generator) px(value float64) float64 {
+	return value * g.scale
+}
+
+// MaxOverlayFrameRate limita a taxa de quadros do overlay em vídeos de alta velocidade
+// (120/240 fps), onde gerar uma imagem por quadro não traz ganho visível
+const MaxOverlayFrameRate = 60.0
+
+// MaxOverlayFrames limita o número de quadros de overlay de uma renderização (30 minutos a
+// 60 fps). Vídeos mais longos têm a taxa do overlay reduzida para caber no limite, evitando que
+// uma atividade de várias horas encha o disco de PNGs.
+const MaxOverlayFrames = 108000
+
+// OverlayFrameRate retorna a taxa de quadros do overlay para a taxa do vídeo
+func OverlayFrameRate(videoFrameRate float64) float64 {
+	if videoFrameRate <= 0 {
+		return 1
+	}
+	return math.Min(videoFrameRate, MaxOverlayFrameRate)
+}
+
+// OverlayFrameRateFor retorna a taxa do overlay para um vídeo com a duração informada,
+// reduzida quando necessário para não ultrapassar MaxOverlayFrames quadros (mínimo de 1 fps)
+func OverlayFrameRateFor(videoFrameRate float64, duration time.Duration) float64 {
+	rate := OverlayFrameRate(videoFrameRate)
+	if duration <= 0 {
+		return rate
+	}
+	if limit := MaxOverlayFrames / duration.Seconds(); rate > limit {
+		rate = math.Max(1, limit)
+	}
+	return rate
+}
+
 func (g *Generator) SetProgressCallback(callback ProgressCallback) {
 	g.progressCallback = callback
 }
 
+// SetGMeterStyle escolhe como o widget de G-force é desenhado (GMeterText ou GMeterFrictionCircle)
+func (g *Generator) SetGMeterStyle(style string) {
+	if style != GMeterFrictionCircle {
+		style = GMeterText
+	}
+	g.gMeterStyle = style
+}
+
+// SetWidgets define os widgets da pilha, na ordem de exibição (ver ParseWidgets)
+func (g *Generator) SetWidgets(widgets []string) {
+	if len(widgets) == 0 {
+		widgets = DefaultWidgets
+	}
+	g.widgets = widgets
+}
+
+// SetStopBehavior define o que acontece com o overlay nos trechos de parada informados
+func (g *Generator) SetStopBehavior(behavior string, segments []gps.TrackSegment) {
+	if behavior != StopBehaviorFreeze && behavior != StopBehaviorHide {
+		behavior = StopBehaviorNone
+	}
+	g.stopBehavior = behavior
+	g.stopSegments = segments
+}
+
+// SetLapTimeline define as voltas e parciais usados pelos widgets de volta e parcial
+func (g *Generator) SetLapTimeline(laps *gps.LapTimeline) {
+	g.laps = laps
+}
+
+// SetSegmentEfforts habilita o banner exibido enquanto o vídeo passa por um segmento
+func (g *Generator) SetSegmentEfforts(efforts *gps.EffortTimeline) {
+	g.efforts = efforts
+}
+
+// SetShowPaused exibe a indicação "PAUSED" nos pontos que o Strava marcou como parados
+func (g *Generator) SetShowPaused(show bool) {
+	g.showPaused = show
+}
+
 func NewGeneratorWithPosition(position string) *Generator {
 	g := NewGenerator()
 	g.overlayPosition = position
@@ -66,44 +225,334 @@ func NewGenerator() *Generator {
 	}
 
 	return &Generator{
-		width:           340,
-		height:          340,
+		width:           BaseCanvasSize,
+		height:          BaseCanvasSize,
+		scale:           1,
 		tempDir:         tempDir,
 		fontLoaded:      false,
 		fontPath:        fontPath,
 		overlayPosition: "bottom-left", // Padrão
+		gMeterStyle:     GMeterText,
+		widgets:         DefaultWidgets,
+		stopBehavior:    StopBehaviorNone,
 	}
 }
 
-// loadFont tenta carregar uma fonte do sistema.
+// loadFont aplica a fonte do sistema no tamanho indicado (no desenho base). As faces ficam em
+// cache por tamanho: carregar a fonte do disco a cada texto custava mais que o próprio desenho.
 func (g *Generator) loadFont(dc *gg.Context, size float64) {
-	if g.fontLoaded && g.fontPath != "" {
-		if err := dc.LoadFontFace(g.fontPath, size); err == nil {
-			return
+	size = g.px(size)
+	if face, ok := g.faces[size]; ok {
+		dc.SetFontFace(face)
+		return
+	}
+
+	var face font.Face = basicfont.Face7x13
+	if g.parseFont() {
+		face = ggFace{
+			Face:   truetype.NewFace(g.ttf, &truetype.Options{Size: size}),
+			height: fixed.Int26_6(math.Round(size * 72 / 96 * 64)),
 		}
 	}
+	if g.faces == nil {
+		g.faces = make(map[float64]font.Face)
+	}
+	g.faces[size] = face
+	dc.SetFontFace(face)
+}
 
-	if g.fontPath == "" {
-		dc.SetFontFace(basicfont.Face7x13)
+// ggFace mantém a altura de linha que gg.Context.LoadFontFace usa (pontos × 72/96) em vez da
+// métrica da fonte, para que textos ancorados fiquem onde sempre estiveram
+type ggFace struct {
+	font.Face
+	height fixed.Int26_6
+}
+
+func (f ggFace) Metrics() font.Metrics {
+	metrics := f.Face.Metrics()
+	metrics.Height = f.height
+	return metrics
+}
+
+// parseFont lê a fonte do sistema uma única vez; sem fonte, o desenho usa a fonte básica
+func (g *Generator) parseFont() bool {
+	if g.ttf != nil {
+		return true
+	}
+	if g.fontPath == "" || g.fontFailed {
 		g.fontLoaded = false
-		return
+		return false
 	}
 
-	err := dc.LoadFontFace(g.fontPath, size)
+	data, err := os.ReadFile(g.fontPath)
+	if err == nil {
+		g.ttf, err = truetype.Parse(data)
+	}
 	if err != nil {
 		log.Printf("Não foi possível carregar a fonte do sistema de %s: %v. Usando fonte básica.", g.fontPath, err)
-		dc.SetFontFace(basicfont.Face7x13)
+		g.fontFailed = true
 		g.fontLoaded = false
-	} else {
-		g.fontLoaded = true
+		return false
 	}
+	g.fontLoaded = true
+	return true
+}
+
+// TimelineWindow é um trecho da trilha, em horário GPS, coberto por um trecho do vídeo
+type TimelineWindow struct {
+	Start    time.Time
+	Duration time.Duration
+}
+
+// GenerateOverlaySequenceForTimeline gera um overlay por quadro entre start e start+duration,
+// amostrando a trilha pelo sampler em vez de repetir o mesmo ponto durante um segundo inteiro
+func (g *Generator) GenerateOverlaySequenceForTimeline(sampler *gps.FrameSampler, start time.Time, duration time.Duration) ([]string, error) {
+	return g.GenerateOverlaySequenceForWindows(sampler, []TimelineWindow{{Start: start, Duration: duration}})
+}
+
+// GenerateOverlaySequenceForWindows gera os overlays de vários trechos em uma única sequência,
+// na ordem em que os trechos aparecem no vídeo de saída
+func (g *Generator) GenerateOverlaySequenceForWindows(sampler *gps.FrameSampler, windows []TimelineWindow) ([]string, error) {
+	var samples []gps.GPSPoint
+	for _, window := range windows {
+		samples = append(samples, sampler.Sample(window.Start, window.Duration)...)
+	}
+	log.Printf("🎞️ Amostrando %d quadros de overlay em %d trecho(s) a %.2f fps", len(samples), len(windows), sampler.FrameRate())
+	return g.renderFrames(samples, g.stopFrameModes(samples))
 }
 
 func (g *Generator) GenerateOverlaySequence(points []gps.GPSPoint, frameRate float64) ([]string, error) {
+	return g.renderFrames(points, nil)
+}
+
+// frameMode indica como um quadro é produzido
+type frameMode int
+
+const (
+	frameRender frameMode = iota // Desenha o overlay do ponto
+	frameFreeze                  // Repete o último quadro desenhado
+	frameHide                    // Quadro transparente
+)
+
+// stopFrameModes marca os quadros que caem em paradas ou pausas conforme o comportamento
+// configurado; retorna nil quando todos os quadros devem ser desenhados
+func (g *Generator) stopFrameModes(points []gps.GPSPoint) []frameMode {
+	if g.stopBehavior == StopBehaviorNone || len(g.stopSegments) == 0 {
+		return nil
+	}
+
+	mode := frameFreeze
+	if g.stopBehavior == StopBehaviorHide {
+		mode = frameHide
+	}
+
+	modes := make([]frameMode, len(points))
+	stopped := 0
+	for i, point := range points {
+		if _, ok := gps.SegmentAt(g.stopSegments, point.Time, gps.SegmentStop, gps.SegmentAutoPause); ok {
+			modes[i] = mode
+			stopped++
+		}
+	}
+
+	log.Printf("⏸️ %d de %d quadros em paradas (%s)", stopped, len(points), g.stopBehavior)
+	return modes
+}
+
+// renderFrames gera um PNG por quadro. Quadros congelados reutilizam a imagem anterior,
+// quadros ocultos compartilham uma única imagem transparente e quadros com as mesmas entradas
+// de desenho (ver frameInputs) apontam para o mesmo arquivo, de modo que cada imagem distinta é
+// desenhada uma única vez. As imagens distintas são desenhadas em paralelo.
+func (g *Generator) renderFrames(points []gps.GPSPoint, modes []frameMode) ([]string, error) {
 	if len(points) == 0 {
 		return nil, fmt.Errorf("nenhum ponto GPS fornecido")
 	}
 
+	maxSpeedScale := SpeedScale(points)
+
+	// Associa cada quadro a uma imagem; -1 é o quadro transparente
+	var jobs []renderJob
+	frameJobs := make([]int, len(points))
+	seen := make(map[frameInputs]int)
+	lastRendered := -1
+	hidden := false
+
+	for i, point := range points {
+		mode := frameRender
+		if modes != nil {
+			mode = modes[i]
+		}
+		if mode == frameFreeze && lastRendered >= 0 {
+			frameJobs[i] = lastRendered
+			continue
+		}
+		if mode == frameHide {
+			frameJobs[i] = -1
+			hidden = true
+			continue
+		}
+
+		inputs := g.frameInputsFor(point)
+		job, ok := seen[inputs]
+		if !ok {
+			job = len(jobs)
+			jobs = append(jobs, renderJob{
+				point: point,
+				path:  filepath.Join(g.tempDir, fmt.Sprintf("overlay_%06d.png", i)),
+			})
+			seen[inputs] = job
+		}
+		frameJobs[i] = job
+		lastRendered = job
+	}
+
+	blankPath := ""
+	if hidden {
+		blankPath = filepath.Join(g.tempDir, "overlay_blank.png")
+		if err := gg.NewContext(g.width, g.height).SavePNG(blankPath); err != nil {
+			g.Cleanup()
+			return nil, fmt.Errorf("erro ao gerar o frame transparente: %w", err)
+		}
+	}
+
+	if err := g.renderJobs(jobs, maxSpeedScale); err != nil {
+		g.Cleanup()
+		return nil, err
+	}
+
+	imagePaths := make([]string, len(points))
+	for i, job := range frameJobs {
+		if job < 0 {
+			imagePaths[i] = blankPath
+		} else {
+			imagePaths[i] = jobs[job].path
+		}
+	}
+
+	log.Printf("✅ %d overlays gerados (%d imagens distintas) na posição: %s", len(imagePaths), len(jobs), g.overlayPosition)
+	return imagePaths, nil
+}
+
+// renderJob é uma imagem distinta a desenhar e o arquivo em que ela é gravada
+type renderJob struct {
+	point gps.GPSPoint
+	path  string
+}
+
+// frameInputs reúne tudo o que o desenho de um quadro lê além da configuração do gerador.
+// Tempos entram arredondados ao segundo, a resolução exibida (ver formatClock).
+type frameInputs struct {
+	point    gps.GPSPoint
+	lap      gps.LapStatus
+	effort   gps.EffortStatus
+	inEffort bool
+}
+
+// frameInputsFor calcula as entradas de desenho do ponto
+func (g *Generator) frameInputsFor(point gps.GPSPoint) frameInputs {
+	var inputs frameInputs
+	if g.laps != nil {
+		inputs.lap = g.laps.At(point)
+		inputs.lap.LapElapsed = inputs.lap.LapElapsed.Round(time.Second)
+	}
+	if g.efforts != nil {
+		inputs.effort, inputs.inEffort = g.efforts.At(point)
+		inputs.effort.Elapsed = inputs.effort.Elapsed.Round(time.Second)
+	}
+
+	point.Time = time.Time{}
+	point.ElapsedTime = point.ElapsedTime.Round(time.Second)
+	point.MovingTime = point.MovingTime.Round(time.Second)
+	inputs.point = point
+	return inputs
+}
+
+// renderJobs desenha e grava as imagens com um worker por CPU
+func (g *Generator) renderJobs(jobs []renderJob, maxSpeed float64) error {
+	// A fonte é interpretada antes dos workers, que a compartilham
+	g.parseFont()
+
+	progress := newProgressThrottle(g.progressCallback, len(jobs))
+	work := make(chan int)
+	var failed atomic.Bool
+	var firstErr error
+	var errOnce sync.Once
+	var wg sync.WaitGroup
+
+	for w := 0; w < min(runtime.GOMAXPROCS(0), len(jobs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker := g.renderWorker()
+			for i := range work {
+				if err := worker.drawOverlay(jobs[i].point, maxSpeed).SavePNG(jobs[i].path); err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("erro ao gerar o frame de overlay %s: %w", filepath.Base(jobs[i].path), err)
+					})
+					failed.Store(true)
+					continue
+				}
+				progress.done()
+			}
+		}()
+	}
+
+	for i := range jobs {
+		if failed.Load() {
+			break
+		}
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return firstErr
+}
+
+// renderWorker retorna uma cópia do gerador para um worker de desenho, com caches próprios: as
+// faces de fonte guardam estado de rasterização e não podem ser compartilhadas entre goroutines.
+func (g *Generator) renderWorker() *Generator {
+	worker := *g
+	worker.faces = nil
+	worker.fadeMask = nil
+	return &worker
+}
+
+// progressSteps é quantas vezes, no máximo, o progresso da geração é reportado
+const progressSteps = 100
+
+// progressThrottle repassa ao callback o progresso dos workers a cada 1% concluído, em vez de
+// a cada imagem
+type progressThrottle struct {
+	mu       sync.Mutex
+	callback ProgressCallback
+	total    int
+	current  int
+	reported int
+}
+
+func newProgressThrottle(callback ProgressCallback, total int) *progressThrottle {
+	return &progressThrottle{callback: callback, total: total}
+}
+
+// done registra uma imagem concluída
+func (p *progressThrottle) done() {
+	if p.callback == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current++
+	step := p.current * progressSteps / p.total
+	if step > p.reported || p.current == p.total {
+		p.reported = step
+		p.callback(p.current, p.total)
+	}
+}
+
+// SpeedScale retorna o fundo de escala do velocímetro (km/h) para os pontos: a velocidade
+// máxima arredondada para cima em dezenas, com mínimo de 50
+func SpeedScale(points []gps.GPSPoint) float64 {
 	maxSpeed := 0.0
 	for _, point := range points {
 		speed := point.Velocity * 3.6
@@ -115,37 +564,23 @@ func (g *Generator) GenerateOverlaySequence(points []gps.GPSPoint, frameRate flo
 	if maxSpeedScale < 50 {
 		maxSpeedScale = 50
 	}
+	return maxSpeedScale
+}
 
-	var imagePaths []string
-	totalPoints := len(points)
-
-	for i, point := range points {
-		// Reporta progresso
-		if g.progressCallback != nil {
-			g.progressCallback(i+1, totalPoints)
-		}
-
-		imagePath := filepath.Join(g.tempDir, fmt.Sprintf("overlay_%06d.png", i))
-		err := g.generateEnhancedOverlay(point, maxSpeedScale, imagePath)
-		if err != nil {
-			g.Cleanup()
-			return nil, fmt.Errorf("erro ao gerar o frame de overlay %d: %w", i, err)
-		}
-		imagePaths = append(imagePaths, imagePath)
-	}
-
-	log.Printf("✅ %d overlays gerados na posição: %s", len(imagePaths), g.overlayPosition)
-	return imagePaths, nil
+// RenderOverlay desenha o overlay de um único ponto em memória, sem gravar arquivo. Usado na
+// pré-visualização de um quadro; maxSpeed é o fundo de escala do velocímetro (ver SpeedScale).
+func (g *Generator) RenderOverlay(point gps.GPSPoint, maxSpeed float64) image.Image {
+	return g.drawOverlay(point, maxSpeed).Image()
 }
 
-// generateEnhancedOverlay cria o overlay principal com velocímetro e widgets
-func (g *Generator) generateEnhancedOverlay(point gps.GPSPoint, maxSpeed float64, outputPath string) error {
+// drawOverlay desenha o velocímetro, os widgets, a pausa e o banner do segmento do ponto
+func (g *Generator) drawOverlay(point gps.GPSPoint, maxSpeed float64) *gg.Context {
 	dc := gg.NewContext(g.width, g.height)
 	dc.SetRGBA(0, 0, 0, 0)
 	dc.Clear()
 
-	radius := 95.0
-	margin := 15.0
+	radius := g.px(95)
+	margin := g.px(15)
 
 	// CORRIGIDO: Calcula a posição do centro do velocímetro baseado em g.overlayPosition
 	var centerX, centerY float64
@@ -174,17 +609,50 @@ func (g *Generator) generateEnhancedOverlay(point gps.GPSPoint, maxSpeed float64
 	// 2. Desenha os widgets empilhados à esquerda
 	g.drawStackedWidgets(dc, point, centerX, centerY, radius)
 
-	return dc.SavePNG(outputPath)
+	// 3. Indicação de pausa abaixo da velocidade digital
+	if g.showPaused && point.Paused {
+		g.drawPausedBadge(dc, centerX, centerY+radius-g.px(4))
+	}
+
+	// 4. Banner do segmento em andamento
+	if g.efforts != nil {
+		if status, ok := g.efforts.At(point); ok {
+			g.drawSegmentBanner(dc, status)
+		}
+	}
+
+	return dc
 }
 
 // drawStackedWidgets desenha os widgets empilhados à esquerda com fundo
 func (g *Generator) drawStackedWidgets(dc *gg.Context, point gps.GPSPoint, speedometerCenterX, speedometerCenterY, speedometerRadius float64) {
-	spacing := 35.0
-	widgetHeight := 25.0
-	padding := 10.0
+	spacing := g.px(35)
+	widgetHeight := g.px(25)
+	padding := g.px(10)
+
+	// Altura de cada widget na pilha; o círculo de atrito ocupa mais espaço que o texto.
+	// Widgets que não cabem no canvas são descartados.
+	var widgets []string
+	var heights []float64
+	totalHeight := (padding * 2) - (spacing - widgetHeight)
+	for _, widget := range g.widgets {
+		height := spacing
+		if widget == WidgetGForce && g.gMeterStyle == GMeterFrictionCircle {
+			height = g.px(frictionCircleHeight)
+		}
+		if totalHeight+height > float64(g.height) {
+			break
+		}
+		widgets = append(widgets, widget)
+		heights = append(heights, height)
+		totalHeight += height
+	}
+	if len(widgets) == 0 {
+		return
+	}
 
-	totalHeight := (spacing * 3) + widgetHeight + (padding * 2)
-	containerWidth := 95.0
+	containerWidth := g.px(95)
+	edge := g.px(10)
 
 	// CORRIGIDO: Ajusta posição dos widgets baseado na posição do overlay
 	var containerX, containerY float64
@@ -192,49 +660,39 @@ func (g *Generator) drawStackedWidgets(dc *gg.Context, point gps.GPSPoint, speed
 	switch g.overlayPosition {
 	case "top-right", "bottom-right":
 		// Widgets à esquerda do velocímetro
-		containerX = 10.0
+		containerX = edge
 	case "top-left", "bottom-left":
 		// Widgets à direita do velocímetro
-		containerX = speedometerCenterX + speedometerRadius + 20.0
+		containerX = speedometerCenterX + speedometerRadius + g.px(20)
 		// Garante que não ultrapassa a largura
 		if containerX+containerWidth > float64(g.width) {
-			containerX = 10.0 // Fallback para esquerda
+			containerX = edge // Fallback para esquerda
 		}
 	default:
-		containerX = 10.0
+		containerX = edge
 	}
 
 	containerY = speedometerCenterY - (totalHeight / 2)
+	// Mantém o container dentro do canvas
+	containerY = math.Max(0, math.Min(containerY, float64(g.height)-totalHeight))
 
 	// Desenha fundo escuro com transparência
 	dc.SetRGBA(0.1, 0.1, 0.1, 0.5)
-	dc.DrawRoundedRectangle(containerX, containerY, containerWidth, totalHeight, 8)
+	dc.DrawRoundedRectangle(containerX, containerY, containerWidth, totalHeight, g.px(8))
 	dc.Fill()
 
 	startX := containerX + padding
 	startY := containerY + padding
 
-	// G-Force
-	gForce := math.Abs(point.GForce)
-	g.drawTextWidget(dc, startX, startY, "G-FORCE", fmt.Sprintf("%.2f G", gForce),
-		color.RGBA{R: 255, G: 100, B: 50, A: 255})
-
-	// Altitude
-	startY += spacing
-	g.drawTextWidget(dc, startX, startY, "ALTITUDE", fmt.Sprintf("%.0f m", point.Altitude),
-		color.RGBA{R: 100, G: 255, B: 150, A: 255})
-
-	// Cadência estimada
-	startY += spacing
-	cadence := g.estimateCadence(point)
-	g.drawTextWidget(dc, startX, startY, "CADENCE", fmt.Sprintf("%.0f RPM", cadence),
-		color.RGBA{R: 255, G: 200, B: 50, A: 255})
-
-	// BPM estimado
-	startY += spacing
-	heartRate := g.estimateHeartRate(point)
-	g.drawTextWidget(dc, startX, startY, "HEART", fmt.Sprintf("%.0f BPM", heartRate),
-		color.RGBA{R: 255, G: 50, B: 200, A: 255})
+	for i, widget := range widgets {
+		if widget == WidgetGForce && g.gMeterStyle == GMeterFrictionCircle {
+			g.drawFrictionCircle(dc, startX, startY, containerWidth-(padding*2), point)
+		} else {
+			label, value, textColor := g.widgetText(widget, point)
+			g.drawTextWidget(dc, startX, startY, label, value, textColor)
+		}
+		startY += heights[i]
+	}
 }
 
 // drawTextWidget desenha um widget de texto individual
@@ -245,7 +703,52 @@ func (g *Generator) drawTextWidget(dc *gg.Context, x, y float64, label, value st
 
 	g.loadFont(dc, 16)
 	dc.SetRGBA(float64(textColor.R)/255, float64(textColor.G)/255, float64(textColor.B)/255, 1.0)
-	dc.DrawString(value, x, y+15)
+	dc.DrawString(value, x, y+g.px(15))
+}
+
+// frictionCircleHeight é a altura (no desenho base) reservada ao círculo de atrito na pilha de widgets
+const frictionCircleHeight = 100.0
+
+// frictionCircleMaxG é a aceleração correspondente à borda do círculo de atrito
+const frictionCircleMaxG = 1.0
+
+// drawFrictionCircle desenha o G-meter como círculo de atrito: o ponto se move para a direita
+// em curvas à direita e para cima ao acelerar
+func (g *Generator) drawFrictionCircle(dc *gg.Context, x, y, width float64, point gps.GPSPoint) {
+	g.loadFont(dc, 9)
+	dc.SetRGBA(0.6, 0.6, 0.6, 0.9)
+	dc.DrawString("G-FORCE", x, y)
+
+	radius := g.px(30)
+	cx := x + width/2
+	cy := y + g.px(8) + radius
+
+	// Anéis de 0.5 G e 1 G com eixos
+	dc.SetLineWidth(g.px(1))
+	dc.SetRGBA(0.5, 0.5, 0.5, 0.6)
+	dc.DrawCircle(cx, cy, radius)
+	dc.Stroke()
+	dc.SetRGBA(0.5, 0.5, 0.5, 0.35)
+	dc.DrawCircle(cx, cy, radius/2)
+	dc.Stroke()
+	dc.DrawLine(cx-radius, cy, cx+radius, cy)
+	dc.DrawLine(cx, cy-radius, cx, cy+radius)
+	dc.Stroke()
+
+	// Limita o ponto à borda do círculo
+	dx := point.LateralG / frictionCircleMaxG
+	dy := -point.LongitudinalG / frictionCircleMaxG
+	if magnitude := math.Hypot(dx, dy); magnitude > 1 {
+		dx /= magnitude
+		dy /= magnitude
+	}
+
+	dc.SetRGBA(1, 100.0/255, 50.0/255, 1)
+	dc.DrawCircle(cx+dx*radius, cy+dy*radius, g.px(4))
+	dc.Fill()
+
+	g.loadFont(dc, 14)
+	dc.DrawStringAnchored(fmt.Sprintf("%.2f G", point.GForce), cx, cy+radius+g.px(12), 0.5, 0.5)
 }
 
 // estimateCadence estima a cadência baseada na velocidade
@@ -257,8 +760,11 @@ func (g *Generator) estimateCadence(p gps.GPSPoint) float64 {
 	return math.Min(120, 70+(speed_kmh*1.5))
 }
 
-// estimateHeartRate estima a frequência cardíaca
+// estimateHeartRate usa o stream de frequência cardíaca e, sem ele, estima pela intensidade
 func (g *Generator) estimateHeartRate(p gps.GPSPoint) float64 {
+	if p.HeartRate > 0 {
+		return p.HeartRate
+	}
 	if p.Velocity < 1.0 {
 		return 65
 	}
@@ -287,34 +793,48 @@ func (g *Generator) getSpeedColor(speed float64) color.RGBA {
 	}
 }
 
-// drawMainSpeedometer desenha o velocímetro principal
-func (g *Generator) drawMainSpeedometer(dc *gg.Context, cx, cy float64, speed, maxSpeed float64, point gps.GPSPoint, radius float64) {
-	fontSize := 11.0
-	textOffset := 22.0
-
-	startAngle := gg.Radians(135)
-	totalArc := gg.Radians(270)
-	g.loadFont(dc, fontSize)
+// fadeMaskFor retorna a máscara que desvanece a base do círculo do velocímetro. Ela só depende
+// do layout, então é montada uma vez por worker em vez de a cada quadro.
+func (g *Generator) fadeMaskFor(cx, cy, radius float64) *image.Alpha {
+	key := [3]float64{cx, cy, radius}
+	if g.fadeMask != nil && g.fadeMaskKey == key {
+		return g.fadeMask
+	}
 
-	// 1. Máscara para efeito de desvanecimento
 	maskContext := gg.NewContext(g.width, g.height)
-	maskGradient := gg.NewLinearGradient(cx, cy+radius-30, cx, cy+radius+15)
+	maskGradient := gg.NewLinearGradient(cx, cy+radius-g.px(30), cx, cy+radius+g.px(15))
 	maskGradient.AddColorStop(0, color.White)
 	maskGradient.AddColorStop(1, color.Black)
 	maskContext.SetFillStyle(maskGradient)
 	maskContext.DrawRectangle(0, 0, float64(g.width), float64(g.height))
 	maskContext.Fill()
 
-	// 2. Círculo de fundo com máscara
+	g.fadeMask, g.fadeMaskKey = maskContext.AsMask(), key
+	return g.fadeMask
+}
+
+// drawMainSpeedometer desenha o velocímetro principal
+func (g *Generator) drawMainSpeedometer(dc *gg.Context, cx, cy float64, speed, maxSpeed float64, point gps.GPSPoint, radius float64) {
+	fontSize := 11.0
+	textOffset := g.px(22)
+
+	startAngle := gg.Radians(135)
+	totalArc := gg.Radians(270)
+	g.loadFont(dc, fontSize)
+
+	// 1. Círculo de fundo com máscara de desvanecimento
 	dc.Push()
-	dc.SetMask(maskContext.AsMask())
-	dc.SetLineWidth(16.0)
+	dc.SetMask(g.fadeMaskFor(cx, cy, radius))
+	dc.SetLineWidth(g.px(16))
 	dc.SetRGBA(0.1, 0.1, 0.1, 0.5)
 	dc.DrawCircle(cx, cy, radius)
 	dc.Stroke()
 	dc.Pop()
+	// O Pop do gg não restaura a máscara: sem isto o desvanecimento valeria para todo o resto
+	// do desenho, e cada texto seria composto sobre o canvas inteiro
+	dc.ResetClip()
 
-	// 3. Desenha os traços de velocidade
+	// 2. Desenha os traços de velocidade
 	for kmh := 0.0; kmh <= maxSpeed; kmh++ {
 		angle := startAngle + (totalArc * (kmh / maxSpeed))
 
@@ -322,11 +842,11 @@ func (g *Generator) drawMainSpeedometer(dc *gg.Context, cx, cy float64, speed, m
 		isMajor := int(kmh)%10 == 0
 
 		if isMajor {
-			tickLength = 14.0
-			tickWidth = 2.5
+			tickLength = g.px(14)
+			tickWidth = g.px(2.5)
 		} else {
-			tickLength = 9.0
-			tickWidth = 1.0
+			tickLength = g.px(9)
+			tickWidth = g.px(1)
 		}
 
 		innerRadius := radius - tickLength
@@ -347,8 +867,8 @@ func (g *Generator) drawMainSpeedometer(dc *gg.Context, cx, cy float64, speed, m
 		dc.Stroke()
 	}
 
-	// 4. Marcadores numéricos
-	dc.SetLineWidth(2)
+	// 3. Marcadores numéricos
+	dc.SetLineWidth(g.px(2))
 	dc.SetRGBA(1, 1, 1, 0.9)
 	for i := 0.0; i <= maxSpeed; i += 10 {
 		angle := startAngle + (totalArc * (i / maxSpeed))
@@ -359,16 +879,16 @@ func (g *Generator) drawMainSpeedometer(dc *gg.Context, cx, cy float64, speed, m
 		}
 	}
 
-	// 5. Bússola interna
+	// 4. Bússola interna
 	g.drawCompactCompass(dc, cx, cy, point.Bearing)
 
-	// 6. Velocidade digital
-	g.drawDigitalSpeed(dc, cx, cy+58, speed)
+	// 5. Velocidade digital
+	g.drawDigitalSpeed(dc, cx, cy+g.px(58), speed)
 }
 
 // drawCompactCompass desenha uma bússola compacta no centro
 func (g *Generator) drawCompactCompass(dc *gg.Context, cx, cy, bearing float64) {
-	radius := 42.0
+	radius := g.px(42)
 	fontSize := 10.0
 
 	g.loadFont(dc, fontSize)
@@ -379,7 +899,7 @@ func (g *Generator) drawCompactCompass(dc *gg.Context, cx, cy, bearing float64)
 	dc.Fill()
 
 	// Borda da bússola
-	dc.SetLineWidth(1.0)
+	dc.SetLineWidth(g.px(1))
 	dc.SetRGBA(0.5, 0.5, 0.5, 1)
 	dc.DrawCircle(cx, cy, radius)
 	dc.Stroke()
@@ -390,8 +910,8 @@ func (g *Generator) drawCompactCompass(dc *gg.Context, cx, cy, bearing float64)
 		cardinals := map[string]float64{"N": 270, "E": 0, "S": 90, "W": 180}
 		for text, angle := range cardinals {
 			rad := gg.Radians(angle)
-			textX := cx + (radius-10)*math.Cos(rad)
-			textY := cy + (radius-10)*math.Sin(rad)
+			textX := cx + (radius-g.px(10))*math.Cos(rad)
+			textY := cy + (radius-g.px(10))*math.Sin(rad)
 			dc.DrawStringAnchored(text, textX, textY, 0.5, 0.5)
 		}
 	}
@@ -400,8 +920,10 @@ func (g *Generator) drawCompactCompass(dc *gg.Context, cx, cy, bearing float64)
 	dc.Push()
 	dc.Translate(cx, cy)
 	dc.Rotate(gg.Radians(bearing))
+	// A agulha só tem preenchimentos: é desenhada em medidas do desenho base e escalada pela transformação
+	dc.Scale(g.scale, g.scale)
 
-	needleLength := radius - 13
+	needleLength := 42.0 - 13
 	needleWidth := 8.0
 
 	// Ponta vermelha (Norte)
@@ -457,7 +979,20 @@ func (g *Generator) drawDigitalSpeed(dc *gg.Context, cx, cy, speed float64) {
 
 	g.loadFont(dc, 12)
 	dc.SetRGB255(0, 221, 255)
-	dc.DrawStringAnchored("km/h", cx, cy+14, 0.5, 0.5)
+	dc.DrawStringAnchored("km/h", cx, cy+g.px(14), 0.5, 0.5)
+}
+
+// drawPausedBadge desenha a etiqueta "PAUSED" centralizada em (cx, cy)
+func (g *Generator) drawPausedBadge(dc *gg.Context, cx, cy float64) {
+	width, height := g.px(70), g.px(18)
+
+	dc.SetRGBA(0.1, 0.1, 0.1, 0.75)
+	dc.DrawRoundedRectangle(cx-width/2, cy-height/2, width, height, height/2)
+	dc.Fill()
+
+	g.loadFont(dc, 11)
+	dc.SetRGB255(255, 200, 50)
+	dc.DrawStringAnchored("PAUSED", cx, cy, 0.5, 0.5)
 }
 
 // Cleanup remove o diretório temporário.
@@ -0,0 +1,174 @@
+package overlay
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"strava-overlay/internal/gps"
+)
+
+func TestOverlayFrameRateFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     float64
+		duration time.Duration
+		want     float64
+	}{
+		{"vídeo curto mantém a taxa", 30, 10 * time.Minute, 30},
+		{"alta velocidade limitada a 60 fps", 240, time.Minute, MaxOverlayFrameRate},
+		{"5 horas a 60 fps", 60, 5 * time.Hour, MaxOverlayFrames / (5 * 3600.0)},
+		{"mínimo de 1 fps", 60, 48 * time.Hour, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OverlayFrameRateFor(tt.rate, tt.duration); got != tt.want {
+				t.Errorf("OverlayFrameRateFor(%v, %v) = %v, esperado %v", tt.rate, tt.duration, got, tt.want)
+			}
+		})
+	}
+
+	frames := OverlayFrameRateFor(60, 5*time.Hour) * (5 * time.Hour).Seconds()
+	if frames > MaxOverlayFrames {
+		t.Errorf("5 horas a 60 fps geram %.0f quadros, acima do limite de %d", frames, MaxOverlayFrames)
+	}
+}
+
+func TestRenderFramesDeduplicatesIdenticalFrames(t *testing.T) {
+	g := NewGenerator()
+	defer g.Cleanup()
+
+	start := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	var points []gps.GPSPoint
+	// Dez quadros parados no mesmo ponto e dez com velocidades diferentes
+	for i := 0; i < 10; i++ {
+		points = append(points, gps.GPSPoint{Time: start, Lat: -23.55, Lng: -46.63})
+	}
+	for i := 0; i < 10; i++ {
+		points = append(points, gps.GPSPoint{Time: start, Lat: -23.55, Lng: -46.63, Velocity: float64(i + 1)})
+	}
+
+	paths, err := g.GenerateOverlaySequence(points, 30)
+	if err != nil {
+		t.Fatalf("GenerateOverlaySequence: %v", err)
+	}
+	if len(paths) != len(points) {
+		t.Fatalf("esperava um caminho por quadro (%d), obteve %d", len(points), len(paths))
+	}
+
+	entries, err := os.ReadDir(g.tempDir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 11 {
+		t.Errorf("esperava 11 PNGs distintos no disco, obteve %d", len(entries))
+	}
+	for i := 1; i < 10; i++ {
+		if paths[i] != paths[0] {
+			t.Fatalf("quadro %d idêntico ao primeiro deveria reutilizar %s, usou %s", i, paths[0], paths[i])
+		}
+	}
+}
+
+func TestRenderFramesSkipsUnchangedInputs(t *testing.T) {
+	g := NewGenerator()
+	defer g.Cleanup()
+
+	// Parado por 2 s a 30 fps: o tempo decorrido só muda o desenho na virada do segundo
+	start := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	var points []gps.GPSPoint
+	for i := 0; i < 60; i++ {
+		offset := time.Duration(i) * time.Second / 30
+		points = append(points, gps.GPSPoint{
+			Time:        start.Add(offset),
+			Lat:         -23.55,
+			Lng:         -46.63,
+			ElapsedTime: 10*time.Second + offset,
+		})
+	}
+
+	paths, err := g.GenerateOverlaySequence(points, 30)
+	if err != nil {
+		t.Fatalf("GenerateOverlaySequence: %v", err)
+	}
+
+	distinct := make(map[string]bool)
+	for _, path := range paths {
+		distinct[path] = true
+	}
+	// 10,0–10,4 s arredondam para 10 s, 10,5–11,4 s para 11 s e 11,5–11,97 s para 12 s
+	if len(distinct) != 3 {
+		t.Errorf("esperava 3 imagens distintas, obteve %d", len(distinct))
+	}
+	if paths[0] != paths[14] || paths[14] == paths[15] {
+		t.Errorf("a troca de imagem deveria ocorrer quando o tempo exibido muda (quadro 15)")
+	}
+}
+
+func TestRenderFramesThrottlesProgress(t *testing.T) {
+	g := NewGenerator()
+	defer g.Cleanup()
+
+	start := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	points := make([]gps.GPSPoint, 250)
+	for i := range points {
+		points[i] = gps.GPSPoint{Time: start, Velocity: float64(i) / 10}
+	}
+
+	var calls, last, lastTotal int
+	g.SetProgressCallback(func(current, total int) {
+		calls++
+		last, lastTotal = current, total
+	})
+	if _, err := g.GenerateOverlaySequence(points, 30); err != nil {
+		t.Fatalf("GenerateOverlaySequence: %v", err)
+	}
+
+	if calls > progressSteps+1 {
+		t.Errorf("progresso reportado %d vezes, esperado no máximo %d", calls, progressSteps+1)
+	}
+	if last != lastTotal || lastTotal != len(points) {
+		t.Errorf("o último progresso deveria ser %d/%d, obtido %d/%d", len(points), len(points), last, lastTotal)
+	}
+}
+
+func TestFitText(t *testing.T) {
+	g := goldenGenerator(t)
+	dc := g.drawOverlay(gps.GPSPoint{}, 50)
+	g.loadFont(dc, 13)
+
+	text := "Subida do Pico do Jaraguá - trecho final"
+	full, _ := dc.MeasureString(text)
+
+	if got := fitText(dc, text, full); got != text {
+		t.Errorf("texto que cabe não deveria ser cortado: %q", got)
+	}
+
+	got := fitText(dc, text, full/2)
+	if w, _ := dc.MeasureString(got); w > full/2 || !strings.HasSuffix(got, "...") {
+		t.Fatalf("texto cortado %q (%.1f px) deveria caber em %.1f px com reticências", got, w, full/2)
+	}
+	// Um caractere a mais já não caberia
+	prefix := []rune(strings.TrimSuffix(got, "..."))
+	longer := string([]rune(text)[:len(prefix)+1]) + "..."
+	if w, _ := dc.MeasureString(longer); w <= full/2 {
+		t.Errorf("o corte deveria manter o maior prefixo possível, %q também cabe", longer)
+	}
+
+	if got := fitText(dc, text, 1); got != "" {
+		t.Errorf("largura menor que as reticências deveria retornar vazio, obtido %q", got)
+	}
+}
+
+// BenchmarkDrawOverlay mede o desenho de um quadro 1080p com as faces de fonte e a máscara em cache
+func BenchmarkDrawOverlay(b *testing.B) {
+	g := NewGenerator()
+	defer g.Cleanup()
+	point := goldenPoint()
+
+	for i := 0; i < b.N; i++ {
+		g.drawOverlay(point, 60)
+	}
+}
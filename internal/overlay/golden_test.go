@@ -0,0 +1,157 @@
+package overlay
+
+import (
+	"flag"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/image/font/gofont/goregular"
+
+	"strava-overlay/internal/gps"
+)
+
+// Regrave as referências com: go test ./internal/overlay -run TestRenderOverlayGolden -update
+var update = flag.Bool("update", false, "regrava os PNGs de referência em testdata")
+
+// goldenTolerance é a diferença máxima por canal aceita entre a renderização e a referência,
+// para absorver variações de arredondamento do antialiasing entre arquiteturas
+const goldenTolerance = 3
+
+// goldenGenerator cria um gerador com a fonte Go Regular, para que a renderização não dependa
+// das fontes instaladas no sistema
+func goldenGenerator(t *testing.T) *Generator {
+	t.Helper()
+
+	fontPath := filepath.Join(t.TempDir(), "goregular.ttf")
+	if err := os.WriteFile(fontPath, goregular.TTF, 0644); err != nil {
+		t.Fatalf("erro ao gravar a fonte: %v", err)
+	}
+
+	g := NewGenerator()
+	t.Cleanup(g.Cleanup)
+	g.fontPath = fontPath
+	g.fontLoaded = true
+	return g
+}
+
+// goldenPoint é um ponto com todos os campos usados pelo velocímetro e pelos widgets
+func goldenPoint() gps.GPSPoint {
+	return gps.GPSPoint{
+		Time:          time.Date(2024, 3, 10, 8, 42, 17, 0, time.UTC),
+		Lat:           -23.55,
+		Lng:           -46.63,
+		Velocity:      9.7,
+		Altitude:      812.4,
+		Bearing:       135,
+		GForce:        0.42,
+		LongitudinalG: 0.18,
+		LateralG:      -0.38,
+		Distance:      18420,
+		ElapsedTime:   42*time.Minute + 17*time.Second,
+		MovingTime:    39*time.Minute + 5*time.Second,
+		Ascent:        214,
+		Descent:       198,
+		Grade:         3.2,
+		HeartRate:     152,
+	}
+}
+
+func TestRenderOverlayGolden(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		gMeterStyle   string
+	}{
+		{"720p", 1280, 720, GMeterText},
+		{"1080p", 1920, 1080, GMeterText},
+		{"4k", 3840, 2160, GMeterFrictionCircle},
+		{"retrato_1080x1920", 1080, 1920, GMeterFrictionCircle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := goldenGenerator(t)
+			g.SetScale(ScaleForVideo(tt.width, tt.height, 1))
+			g.SetGMeterStyle(tt.gMeterStyle)
+
+			got := g.RenderOverlay(goldenPoint(), 60)
+
+			wantSide := int(math.Round(BaseCanvasSize * float64(min(tt.width, tt.height)) / referenceShortSide))
+			if size := got.Bounds().Size(); size.X != wantSide || size.Y != wantSide {
+				t.Fatalf("canvas %dx%d, esperado %dx%d", size.X, size.Y, wantSide, wantSide)
+			}
+
+			goldenPath := filepath.Join("testdata", "overlay_"+tt.name+".png")
+			if *update {
+				writePNG(t, goldenPath, got)
+				return
+			}
+
+			want := readPNG(t, goldenPath)
+			if x, y, ok := firstDifference(got, want); !ok {
+				actualPath := filepath.Join(os.TempDir(), "overlay_"+tt.name+"_obtido.png")
+				writePNG(t, actualPath, got)
+				t.Errorf("renderização difere de %s em (%d, %d); obtido gravado em %s (use -update se a mudança for intencional)",
+					goldenPath, x, y, actualPath)
+			}
+		})
+	}
+}
+
+// firstDifference compara as imagens pixel a pixel dentro de goldenTolerance e retorna o
+// primeiro pixel divergente
+func firstDifference(got, want image.Image) (int, int, bool) {
+	if got.Bounds().Size() != want.Bounds().Size() {
+		return 0, 0, false
+	}
+
+	gotMin, wantMin := got.Bounds().Min, want.Bounds().Min
+	for y := 0; y < got.Bounds().Dy(); y++ {
+		for x := 0; x < got.Bounds().Dx(); x++ {
+			r1, g1, b1, a1 := got.At(gotMin.X+x, gotMin.Y+y).RGBA()
+			r2, g2, b2, a2 := want.At(wantMin.X+x, wantMin.Y+y).RGBA()
+			for _, d := range []int{int(r1>>8) - int(r2>>8), int(g1>>8) - int(g2>>8), int(b1>>8) - int(b2>>8), int(a1>>8) - int(a2>>8)} {
+				if d > goldenTolerance || d < -goldenTolerance {
+					return x, y, false
+				}
+			}
+		}
+	}
+	return 0, 0, true
+}
+
+func readPNG(t *testing.T, path string) image.Image {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("referência ausente (rode com -update para gerá-la): %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("erro ao decodificar %s: %v", path, err)
+	}
+	return img
+}
+
+func writePNG(t *testing.T, path string, img image.Image) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("erro ao criar %s: %v", filepath.Dir(path), err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("erro ao criar %s: %v", path, err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		t.Fatalf("erro ao gravar %s: %v", path, err)
+	}
+}
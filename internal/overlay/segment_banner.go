@@ -0,0 +1,107 @@
+package overlay
+
+import (
+	"fmt"
+	"sort"
+
+	"strava-overlay/internal/gps"
+
+	"github.com/fogleman/gg"
+)
+
+// segmentBannerHeight é a altura do banner de segmento no desenho base
+const segmentBannerHeight = 62.0
+
+// drawSegmentBanner desenha o nome do segmento, o tempo decorrido nele, a distância restante e
+// o rank KOM/PR. O banner fica no lado oposto ao velocímetro para não sobrepor os widgets.
+func (g *Generator) drawSegmentBanner(dc *gg.Context, status gps.EffortStatus) {
+	margin := g.px(6)
+	padding := g.px(10)
+	bannerHeight := g.px(segmentBannerHeight)
+	width := float64(g.width) - margin*2
+
+	y := margin
+	if g.overlayPosition == "top-left" || g.overlayPosition == "top-right" {
+		y = float64(g.height) - bannerHeight - margin
+	}
+
+	dc.SetRGBA(0.1, 0.1, 0.1, 0.7)
+	dc.DrawRoundedRectangle(margin, y, width, bannerHeight, g.px(8))
+	dc.Fill()
+
+	// Faixa lateral na cor do Strava
+	dc.SetRGB255(252, 76, 2)
+	dc.DrawRoundedRectangle(margin, y, g.px(5), bannerHeight, g.px(2))
+	dc.Fill()
+
+	x := margin + padding + g.px(4)
+	textWidth := width - padding*2 - g.px(4)
+
+	// Rank à direita da primeira linha
+	rank := effortRank(status.Effort)
+	rankWidth := 0.0
+	if rank != "" {
+		g.loadFont(dc, 11)
+		rankWidth, _ = dc.MeasureString(rank)
+		dc.SetRGB255(255, 200, 50)
+		dc.DrawStringAnchored(rank, margin+width-padding, y+g.px(18), 1, 0.5)
+		rankWidth += g.px(8)
+	}
+
+	g.loadFont(dc, 13)
+	dc.SetRGBA(1, 1, 1, 0.95)
+	dc.DrawStringAnchored(fitText(dc, status.Effort.Name, textWidth-rankWidth), x, y+g.px(18), 0, 0.5)
+
+	// Segunda linha: tempo no segmento e distância restante
+	g.loadFont(dc, 20)
+	dc.SetRGB255(252, 76, 2)
+	dc.DrawStringAnchored(formatClock(status.Elapsed), x, y+g.px(43), 0, 0.5)
+
+	g.loadFont(dc, 12)
+	dc.SetRGBA(0.8, 0.8, 0.8, 0.95)
+	dc.DrawStringAnchored(fmt.Sprintf("%s left", formatDistance(status.Remaining)), margin+width-padding, y+g.px(43), 1, 0.5)
+}
+
+// effortRank formata o rank do esforço, priorizando a classificação geral sobre o PR
+func effortRank(effort gps.SegmentEffort) string {
+	switch {
+	case effort.KOMRank == 1:
+		return "KOM"
+	case effort.KOMRank > 1:
+		return fmt.Sprintf("TOP %d", effort.KOMRank)
+	case effort.PRRank == 1:
+		return "PR"
+	case effort.PRRank > 1:
+		return fmt.Sprintf("PR #%d", effort.PRRank)
+	default:
+		return ""
+	}
+}
+
+// formatDistance usa metros abaixo de 1 km
+func formatDistance(meters float64) string {
+	if meters < 1000 {
+		return fmt.Sprintf("%.0f m", meters)
+	}
+	return fmt.Sprintf("%.2f km", meters/1000)
+}
+
+// fitText corta o texto com reticências até caber na largura, buscando o maior prefixo que
+// cabe por bisseção em vez de medir um caractere a menos por vez
+func fitText(dc *gg.Context, text string, maxWidth float64) string {
+	if w, _ := dc.MeasureString(text); w <= maxWidth {
+		return text
+	}
+
+	runes := []rune(text)
+	fits := func(n int) bool {
+		w, _ := dc.MeasureString(string(runes[:n]) + "...")
+		return w <= maxWidth
+	}
+	// Maior n em [0, len) cujo prefixo cabe; sort.Search acha o primeiro que não cabe
+	n := sort.Search(len(runes), func(n int) bool { return !fits(n) }) - 1
+	if n < 0 {
+		return ""
+	}
+	return string(runes[:n]) + "..."
+}
@@ -0,0 +1,135 @@
+package overlay
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"strings"
+	"time"
+
+	"strava-overlay/internal/gps"
+)
+
+// Widgets disponíveis na pilha ao lado do velocímetro
+const (
+	WidgetGForce   = "gforce"
+	WidgetAltitude = "altitude"
+	WidgetCadence  = "cadence"
+	WidgetHeart    = "heart"
+	WidgetDistance = "distance"
+	WidgetElapsed  = "elapsed"
+	WidgetMoving   = "moving"
+	WidgetAscent   = "ascent"
+	WidgetGrade    = "grade"
+	WidgetLap      = "lap"
+	WidgetSplit    = "split"
+)
+
+// DefaultWidgets é a pilha usada quando nenhuma é configurada
+var DefaultWidgets = []string{WidgetGForce, WidgetAltitude, WidgetCadence, WidgetHeart}
+
+var knownWidgets = map[string]bool{
+	WidgetGForce: true, WidgetAltitude: true, WidgetCadence: true, WidgetHeart: true,
+	WidgetDistance: true, WidgetElapsed: true, WidgetMoving: true, WidgetAscent: true, WidgetGrade: true,
+	WidgetLap: true, WidgetSplit: true,
+}
+
+// ParseWidgets interpreta uma lista separada por vírgulas ("distance,elapsed,ascent"),
+// ignorando nomes desconhecidos ou repetidos. Lista vazia retorna DefaultWidgets.
+func ParseWidgets(list string) []string {
+	var widgets []string
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if !knownWidgets[name] {
+			log.Printf("⚠️ Widget de overlay desconhecido ignorado: %s", name)
+			continue
+		}
+		seen[name] = true
+		widgets = append(widgets, name)
+	}
+
+	if len(widgets) == 0 {
+		return DefaultWidgets
+	}
+	return widgets
+}
+
+// widgetText retorna o rótulo, o valor formatado e a cor de um widget de texto
+func (g *Generator) widgetText(widget string, point gps.GPSPoint) (string, string, color.RGBA) {
+	switch widget {
+	case WidgetGForce:
+		return "G-FORCE", fmt.Sprintf("%.2f G", point.GForce), color.RGBA{R: 255, G: 100, B: 50, A: 255}
+	case WidgetAltitude:
+		return "ALTITUDE", fmt.Sprintf("%.0f m", point.Altitude), color.RGBA{R: 100, G: 255, B: 150, A: 255}
+	case WidgetCadence:
+		return "CADENCE", fmt.Sprintf("%.0f RPM", g.estimateCadence(point)), color.RGBA{R: 255, G: 200, B: 50, A: 255}
+	case WidgetHeart:
+		return "HEART", fmt.Sprintf("%.0f BPM", g.estimateHeartRate(point)), color.RGBA{R: 255, G: 50, B: 200, A: 255}
+	case WidgetDistance:
+		return "DISTANCE", fmt.Sprintf("%.2f km", point.Distance/1000), color.RGBA{R: 0, G: 221, B: 255, A: 255}
+	case WidgetElapsed:
+		return "ELAPSED", formatClock(point.ElapsedTime), color.RGBA{R: 230, G: 230, B: 230, A: 255}
+	case WidgetMoving:
+		return "MOVING", formatClock(point.MovingTime), color.RGBA{R: 230, G: 230, B: 230, A: 255}
+	case WidgetAscent:
+		return "ELEV GAIN", fmt.Sprintf("+%.0f m", point.Ascent), color.RGBA{R: 100, G: 255, B: 150, A: 255}
+	case WidgetGrade:
+		return "GRADE", fmt.Sprintf("%.1f %%", point.Grade), gradeColor(point.Grade)
+	case WidgetLap:
+		status := g.lapStatus(point)
+		if !status.HasLap {
+			return "LAP", "-", color.RGBA{R: 252, G: 76, B: 2, A: 255}
+		}
+		return fmt.Sprintf("LAP %d/%d", status.Lap.Number, status.LapCount), formatClock(status.LapElapsed),
+			color.RGBA{R: 252, G: 76, B: 2, A: 255}
+	case WidgetSplit:
+		status := g.lapStatus(point)
+		if !status.HasSplit {
+			return "LAST SPLIT", "-", color.RGBA{R: 0, G: 221, B: 255, A: 255}
+		}
+		return fmt.Sprintf("SPLIT %d", status.LastSplit.Number), formatClock(status.LastSplit.ElapsedTime),
+			color.RGBA{R: 0, G: 221, B: 255, A: 255}
+	default:
+		return strings.ToUpper(widget), "-", color.RGBA{R: 200, G: 200, B: 200, A: 255}
+	}
+}
+
+// lapStatus consulta a volta e o parcial do ponto; sem voltas configuradas retorna vazio
+func (g *Generator) lapStatus(point gps.GPSPoint) gps.LapStatus {
+	if g.laps == nil {
+		return gps.LapStatus{}
+	}
+	return g.laps.At(point)
+}
+
+// formatClock formata uma duração como h:mm:ss, ou mm:ss abaixo de uma hora
+func formatClock(d time.Duration) string {
+	total := int(d.Round(time.Second).Seconds())
+	if total < 0 {
+		total = 0
+	}
+	hours, minutes, seconds := total/3600, (total/60)%60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%02d:%02d", minutes, seconds)
+}
+
+// gradeColor vai do verde (plano ou descida) ao vermelho (subida íngreme)
+func gradeColor(grade float64) color.RGBA {
+	switch {
+	case grade < 3:
+		return color.RGBA{R: 100, G: 255, B: 150, A: 255}
+	case grade < 6:
+		return color.RGBA{R: 255, G: 200, B: 50, A: 255}
+	case grade < 10:
+		return color.RGBA{R: 255, G: 130, B: 30, A: 255}
+	default:
+		return color.RGBA{R: 230, G: 40, B: 40, A: 255}
+	}
+}
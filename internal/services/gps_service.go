@@ -4,20 +4,97 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"strava-overlay/internal/cache"
 	"strava-overlay/internal/gps"
 	"strava-overlay/internal/strava"
 	"strava-overlay/internal/video"
 )
 
+// Modelos de fuso horário considerados ao interpretar o creation_time do vídeo
+const (
+	TimezoneModelUTC   = "utc"   // creation_time já está em UTC (celulares)
+	TimezoneModelLocal = "local" // creation_time é o relógio local da câmera gravado como UTC (GoPro e similares)
+)
+
+// ActivityMatch representa uma atividade candidata para um vídeo
+type ActivityMatch struct {
+	Activity       strava.Activity
+	TimezoneModel  string
+	VideoStart     time.Time
+	VideoEnd       time.Time
+	OverlapSeconds float64
+	OverlapPercent float64
+}
+
 // GPSService encapsula toda a lógica complexa de processamento de GPS
-type GPSService struct{}
+type GPSService struct {
+	streamOptions   strava.StreamOptions
+	highlightConfig gps.HighlightConfig
+	filterConfig    gps.FilterConfig
+	cacheFor        func() *cache.CacheManager
+
+	// Trilhas processadas recentemente, da mais antiga para a mais nova (ver activityProcessor)
+	processorMutex sync.Mutex
+	processors     []cachedProcessor
+}
+
+// processorCacheSize limita as trilhas processadas mantidas em memória para as consultas do mapa
+const processorCacheSize = 4
+
+type cachedProcessor struct {
+	activityID int64
+	processor  *gps.GPSProcessor
+}
+
+// HighlightClip é um vídeo em que os destaques são procurados. ManualStartTime (RFC3339)
+// substitui o creation_time do vídeo na sincronização, como na renderização.
+type HighlightClip struct {
+	VideoPath       string
+	ManualStartTime string
+}
+
+// ClipRange é o trecho de um vídeo, em tempo do vídeo, que mostra um destaque
+type ClipRange struct {
+	VideoPath string
+	Start     time.Duration
+	End       time.Duration
+}
+
+// ActivityHighlight é um destaque da atividade com os trechos dos vídeos que o mostram
+type ActivityHighlight struct {
+	gps.Highlight
+	Clips []ClipRange
+}
 
 // NewGPSService cria um novo serviço de GPS
 func NewGPSService() *GPSService {
-	return &GPSService{}
+	return &GPSService{
+		streamOptions:   strava.DefaultStreamOptions(),
+		highlightConfig: gps.DefaultHighlightConfig(),
+		filterConfig:    gps.DefaultFilterConfig(),
+	}
+}
+
+// SetHighlightConfig define a duração, o número e os pesos dos destaques detectados
+func (s *GPSService) SetHighlightConfig(cfg gps.HighlightConfig) {
+	s.highlightConfig = cfg
+}
+
+// SetFilterConfig define os filtros aplicados à trilha bruta antes da interpolação
+func (s *GPSService) SetFilterConfig(cfg gps.FilterConfig) {
+	s.filterConfig = cfg
+	s.forgetProcessors()
+}
+
+// SetStreamOptions define a resolução e o eixo dos streams buscados no Strava
+func (s *GPSService) SetStreamOptions(opts strava.StreamOptions) {
+	s.streamOptions = opts
+	s.forgetProcessors()
 }
 
 // GetGPSPointForVideoTime encontra o ponto GPS correspondente ao tempo de início do vídeo
@@ -42,12 +119,7 @@ func (s *GPSService) GetGPSPointForVideoTime(client *strava.Client, activityID i
 	fmt.Printf("Atividade início: %s\n", detail.StartDate.Format("15:04:05 MST"))
 	fmt.Printf("Diferença temporal: %.1f segundos\n", correctedVideoStartTime.Sub(detail.StartDate).Seconds())
 
-	streams, err := client.GetActivityStreams(activityID)
-	if err != nil {
-		return gps.GPSPoint{}, fmt.Errorf("failed to get activity streams: %w", err)
-	}
-
-	processor, err := s.createGPSProcessor(streams, detail.StartDate)
+	processor, err := s.detailProcessor(client, activityID, detail)
 	if err != nil {
 		return gps.GPSPoint{}, err
 	}
@@ -69,19 +141,105 @@ func (s *GPSService) GetGPSPointForVideoTime(client *strava.Client, activityID i
 	return point, nil
 }
 
-// GetGPSPointForMapClick encontra o ponto GPS mais próximo de um clique no mapa
-func (s *GPSService) GetGPSPointForMapClick(client *strava.Client, activityID int64, lat, lng float64) (gps.GPSPoint, error) {
-	detail, err := client.GetActivityDetail(activityID)
+// FindActivitiesForVideo busca atividades cujo intervalo de tempo se sobrepõe ao vídeo,
+// ordenadas pela porcentagem do vídeo coberta pela atividade
+func (s *GPSService) FindActivitiesForVideo(client *strava.Client, videoPath string) ([]ActivityMatch, error) {
+	videoMeta, err := video.GetVideoMetadata(videoPath)
 	if err != nil {
-		return gps.GPSPoint{}, fmt.Errorf("failed to get activity detail: %w", err)
+		return nil, fmt.Errorf("failed to get video metadata: %w", err)
 	}
 
-	streams, err := client.GetActivityStreams(activityID)
+	if videoMeta.CreationTime.IsZero() {
+		return nil, fmt.Errorf("vídeo sem creation_time nos metadados")
+	}
+
+	// Fusos vão de UTC-12 a UTC+14 e uma atividade pode ter começado bem antes do vídeo
+	after := videoMeta.CreationTime.Add(-48 * time.Hour)
+	before := videoMeta.CreationTime.Add(14 * time.Hour).Add(videoMeta.Duration)
+
+	activities, err := client.GetActivitiesInRange(after, before)
 	if err != nil {
-		return gps.GPSPoint{}, fmt.Errorf("failed to get activity streams: %w", err)
+		return nil, fmt.Errorf("failed to get activities: %w", err)
 	}
 
-	processor, err := s.createGPSProcessor(streams, detail.StartDate)
+	log.Printf("🔎 Procurando atividades para o vídeo (%s, %.0fs) entre %d candidatas",
+		videoMeta.CreationTime.Format(time.RFC3339), videoMeta.Duration.Seconds(), len(activities))
+
+	var matches []ActivityMatch
+	for _, activity := range activities {
+		if match, ok := s.matchActivityToVideo(activity, videoMeta); ok {
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].OverlapPercent != matches[j].OverlapPercent {
+			return matches[i].OverlapPercent > matches[j].OverlapPercent
+		}
+		return matches[i].OverlapSeconds > matches[j].OverlapSeconds
+	})
+
+	log.Printf("✅ %d atividades compatíveis encontradas", len(matches))
+	return matches, nil
+}
+
+// matchActivityToVideo avalia a sobreposição do vídeo com a atividade em cada modelo de fuso
+// horário e retorna o melhor resultado
+func (s *GPSService) matchActivityToVideo(activity strava.Activity, videoMeta *video.VideoMetadata) (ActivityMatch, bool) {
+	elapsed := activity.ElapsedTime
+	if elapsed <= 0 {
+		elapsed = activity.MovingTime
+	}
+	activityStart := activity.StartDate
+	activityEnd := activityStart.Add(time.Duration(elapsed) * time.Second)
+
+	candidates := map[string]time.Time{
+		TimezoneModelUTC:   videoMeta.CreationTime,
+		TimezoneModelLocal: s.correctVideoTimeZone(videoMeta.CreationTime, activity.Timezone),
+	}
+
+	var best ActivityMatch
+	found := false
+
+	for _, model := range []string{TimezoneModelUTC, TimezoneModelLocal} {
+		videoStart := candidates[model]
+		videoEnd := videoStart.Add(videoMeta.Duration)
+
+		overlapStart := videoStart
+		if activityStart.After(overlapStart) {
+			overlapStart = activityStart
+		}
+		overlapEnd := videoEnd
+		if activityEnd.Before(overlapEnd) {
+			overlapEnd = activityEnd
+		}
+
+		overlap := overlapEnd.Sub(overlapStart)
+		if overlap <= 0 {
+			continue
+		}
+
+		percent := math.Min(100, overlap.Seconds()/videoMeta.Duration.Seconds()*100)
+
+		if !found || percent > best.OverlapPercent {
+			best = ActivityMatch{
+				Activity:       activity,
+				TimezoneModel:  model,
+				VideoStart:     videoStart,
+				VideoEnd:       videoEnd,
+				OverlapSeconds: overlap.Seconds(),
+				OverlapPercent: percent,
+			}
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// GetGPSPointForMapClick encontra o ponto GPS mais próximo de um clique no mapa
+func (s *GPSService) GetGPSPointForMapClick(client *strava.Client, activityID int64, lat, lng float64) (gps.GPSPoint, error) {
+	processor, err := s.activityProcessor(client, activityID)
 	if err != nil {
 		return gps.GPSPoint{}, err
 	}
@@ -94,14 +252,45 @@ func (s *GPSService) GetGPSPointForMapClick(client *strava.Client, activityID in
 	return point, nil
 }
 
-// GetIntelligentGPSPoints retorna pontos GPS selecionados inteligentemente para marcadores
-func (s *GPSService) GetIntelligentGPSPoints(client *strava.Client, activityID int64) ([]gps.GPSPoint, error) {
+// GetGPSPointForSegmentClick encontra o ponto GPS mais próximo de um clique em um trecho do
+// trajeto exibido, procurando apenas entre os instantes das pontas do trecho
+func (s *GPSService) GetGPSPointForSegmentClick(client *strava.Client, activityID int64, lat, lng float64, from, to time.Time) (gps.GPSPoint, error) {
+	processor, err := s.activityProcessor(client, activityID)
+	if err != nil {
+		return gps.GPSPoint{}, err
+	}
+
+	point, found := processor.GetPointForCoordsBetween(lat, lng, from, to)
+	if !found {
+		return gps.GPSPoint{}, fmt.Errorf("nenhum ponto GPS entre %s e %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	return point, nil
+}
+
+// activityProcessor retorna a trilha processada da atividade, reaproveitando as mais recentes
+// para que cada consulta do mapa ou renderização não busque e processe os streams de novo
+func (s *GPSService) activityProcessor(client *strava.Client, activityID int64) (*gps.GPSProcessor, error) {
+	if processor, ok := s.cachedProcessor(activityID); ok {
+		return processor, nil
+	}
+
 	detail, err := client.GetActivityDetail(activityID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get activity detail: %w", err)
 	}
 
-	streams, err := client.GetActivityStreams(activityID)
+	return s.detailProcessor(client, activityID, detail)
+}
+
+// detailProcessor é activityProcessor para quem já buscou o detalhe da atividade, evitando
+// buscá-lo de novo quando a trilha não está em memória
+func (s *GPSService) detailProcessor(client *strava.Client, activityID int64, detail *strava.ActivityDetail) (*gps.GPSProcessor, error) {
+	if processor, ok := s.cachedProcessor(activityID); ok {
+		return processor, nil
+	}
+
+	streams, err := s.activityStreams(client, activityID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get activity streams: %w", err)
 	}
@@ -111,6 +300,54 @@ func (s *GPSService) GetIntelligentGPSPoints(client *strava.Client, activityID i
 		return nil, err
 	}
 
+	s.rememberProcessor(activityID, processor)
+	return processor, nil
+}
+
+// cachedProcessor procura a trilha processada da atividade em memória
+func (s *GPSService) cachedProcessor(activityID int64) (*gps.GPSProcessor, bool) {
+	s.processorMutex.Lock()
+	defer s.processorMutex.Unlock()
+
+	for _, cached := range s.processors {
+		if cached.activityID == activityID {
+			return cached.processor, true
+		}
+	}
+	return nil, false
+}
+
+// rememberProcessor guarda a trilha processada, descartando a mais antiga acima do limite
+func (s *GPSService) rememberProcessor(activityID int64, processor *gps.GPSProcessor) {
+	s.processorMutex.Lock()
+	defer s.processorMutex.Unlock()
+
+	for i, cached := range s.processors {
+		if cached.activityID == activityID {
+			s.processors = append(s.processors[:i], s.processors[i+1:]...)
+			break
+		}
+	}
+	s.processors = append(s.processors, cachedProcessor{activityID: activityID, processor: processor})
+	if len(s.processors) > processorCacheSize {
+		s.processors = s.processors[len(s.processors)-processorCacheSize:]
+	}
+}
+
+// forgetProcessors descarta as trilhas em memória, processadas com a configuração anterior
+func (s *GPSService) forgetProcessors() {
+	s.processorMutex.Lock()
+	defer s.processorMutex.Unlock()
+	s.processors = nil
+}
+
+// GetIntelligentGPSPoints retorna pontos GPS selecionados inteligentemente para marcadores
+func (s *GPSService) GetIntelligentGPSPoints(client *strava.Client, activityID int64) ([]gps.GPSPoint, error) {
+	processor, err := s.activityProcessor(client, activityID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Pega todos os pontos interpolados
 	allPoints := processor.GetAllPoints()
 
@@ -125,37 +362,56 @@ func (s *GPSService) GetIntelligentGPSPoints(client *strava.Client, activityID i
 
 // GetFullGPSTrajectory retorna TODOS os pontos GPS interpolados
 func (s *GPSService) GetFullGPSTrajectory(client *strava.Client, activityID int64) ([]gps.GPSPoint, error) {
-	detail, err := client.GetActivityDetail(activityID)
+	processor, err := s.activityProcessor(client, activityID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get activity detail: %w", err)
+		return nil, err
 	}
 
-	streams, err := client.GetActivityStreams(activityID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get activity streams: %w", err)
-	}
+	return processor.GetAllPoints(), nil
+}
 
-	processor, err := s.createGPSProcessor(streams, detail.StartDate)
+// GetSimplifiedTrajectory retorna a trilha simplificada geometricamente. Sem tolerância
+// explícita, usa a largura de um pixel no zoom informado (ou a padrão, com zoom 0 e sem alvo).
+// Com tolerância, TargetPoints é um teto: só é aplicado se a trilha ainda tiver pontos demais.
+func (s *GPSService) GetSimplifiedTrajectory(client *strava.Client, activityID int64, zoom int, opts gps.SimplifyOptions) ([]gps.GPSPoint, error) {
+	points, err := s.GetFullGPSTrajectory(client, activityID)
 	if err != nil {
 		return nil, err
 	}
+	if len(points) == 0 {
+		return points, nil
+	}
 
-	return processor.GetAllPoints(), nil
+	return simplifyTrajectory(points, zoom, opts), nil
 }
 
-// GetGPSPointsWithDensity retorna pontos com densidade customizável
-func (s *GPSService) GetGPSPointsWithDensity(client *strava.Client, activityID int64, density string) ([]gps.GPSPoint, error) {
-	detail, err := client.GetActivityDetail(activityID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get activity detail: %w", err)
+// simplifyTrajectory resolve a tolerância pelo zoom e aplica o teto de pontos
+func simplifyTrajectory(points []gps.GPSPoint, zoom int, opts gps.SimplifyOptions) []gps.GPSPoint {
+	if opts.Tolerance <= 0 && zoom > 0 {
+		opts.Tolerance = gps.ToleranceForZoom(zoom, points[0].Lat)
+	}
+	if opts.Tolerance <= 0 && opts.TargetPoints <= 0 {
+		opts.Tolerance = gps.DefaultSimplifyOptions().Tolerance
 	}
 
-	streams, err := client.GetActivityStreams(activityID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get activity streams: %w", err)
+	limit := opts.TargetPoints
+	if opts.Tolerance > 0 {
+		opts.TargetPoints = 0
+	}
+	simplified := gps.Simplify(points, opts)
+	if limit > 0 && len(simplified) > limit {
+		opts.TargetPoints = limit
+		simplified = gps.Simplify(points, opts)
 	}
 
-	processor, err := s.createGPSProcessor(streams, detail.StartDate)
+	log.Printf("✂️ Trajeto simplificado (%s, zoom %d, tolerância %.1f m, teto %d): %d -> %d pontos",
+		opts.Algorithm, zoom, opts.Tolerance, limit, len(points), len(simplified))
+	return simplified
+}
+
+// GetGPSPointsWithDensity retorna pontos com densidade customizável
+func (s *GPSService) GetGPSPointsWithDensity(client *strava.Client, activityID int64, density string) ([]gps.GPSPoint, error) {
+	processor, err := s.activityProcessor(client, activityID)
 	if err != nil {
 		return nil, err
 	}
@@ -182,35 +438,288 @@ func (s *GPSService) GetGPSPointsWithDensity(client *strava.Client, activityID i
 
 // GetPointsForTimeRange retorna pontos GPS para um intervalo de tempo específico
 func (s *GPSService) GetPointsForTimeRange(client *strava.Client, activityID int64, startTime, endTime time.Time) ([]gps.GPSPoint, error) {
+	processor, err := s.activityProcessor(client, activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	return processor.GetPointsForTimeRange(startTime, endTime), nil
+}
+
+// GetTrack retorna a trilha processada da atividade, usada pela renderização para amostrar os
+// dados GPS em cada quadro do vídeo e consultar paradas
+func (s *GPSService) GetTrack(client *strava.Client, activityID int64) (*gps.GPSProcessor, error) {
+	return s.activityProcessor(client, activityID)
+}
+
+// GetTrackSegments retorna as paradas, pausas automáticas e lacunas de dados da atividade
+func (s *GPSService) GetTrackSegments(client *strava.Client, activityID int64) ([]gps.TrackSegment, error) {
+	processor, err := s.GetTrack(client, activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := processor.Segments()
+	log.Printf("⏸️ %d trechos de parada/pausa/lacuna detectados na atividade %d", len(segments), activityID)
+	return segments, nil
+}
+
+// SetCacheProvider define como obter o cache da conta ativa. É consultado a cada acesso para
+// acompanhar trocas de conta; nil (ou um provedor que retorna nil) desativa o cache.
+func (s *GPSService) SetCacheProvider(cacheFor func() *cache.CacheManager) {
+	s.cacheFor = cacheFor
+}
+
+// activityStreams busca os streams da atividade no cache da conta ativa ou, sem eles, no Strava
+func (s *GPSService) activityStreams(client *strava.Client, activityID int64) (*strava.StreamSet, error) {
+	var cacheManager *cache.CacheManager
+	if s.cacheFor != nil {
+		cacheManager = s.cacheFor()
+	}
+
+	variant := s.streamOptions.Resolution + "_" + s.streamOptions.SeriesType
+	if cacheManager != nil {
+		if streams, ok := cacheManager.GetCachedStreams(activityID, variant); ok {
+			return streams, nil
+		}
+	}
+
+	streams, err := client.GetActivityStreams(activityID, s.streamOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheManager != nil {
+		if err := cacheManager.CacheStreams(activityID, variant, streams); err != nil {
+			log.Printf("⚠️ Não foi possível guardar os streams da atividade %d no cache: %v", activityID, err)
+		}
+	}
+	return streams, nil
+}
+
+// HighlightConfig retorna a configuração de detecção de destaques
+func (s *GPSService) HighlightConfig() gps.HighlightConfig {
+	return s.highlightConfig
+}
+
+// DetectHighlights procura os melhores momentos da atividade nos trechos filmados pelos
+// vídeos e os retorna do mais ao menos pontuado, com o trecho de cada vídeo que os mostra.
+// Sem vídeos, considera a atividade inteira.
+func (s *GPSService) DetectHighlights(client *strava.Client, activityID int64, clips []HighlightClip) ([]ActivityHighlight, error) {
 	detail, err := client.GetActivityDetail(activityID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get activity detail: %w", err)
 	}
 
-	streams, err := client.GetActivityStreams(activityID)
+	processor, err := s.detailProcessor(client, activityID, detail)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get activity streams: %w", err)
+		return nil, err
 	}
 
-	processor, err := s.createGPSProcessor(streams, detail.StartDate)
+	type clipSpan struct {
+		path  string
+		start time.Time
+		end   time.Time
+	}
+	var spans []clipSpan
+	var coverage []gps.TimeRange
+	for _, clip := range clips {
+		videoMeta, err := video.GetVideoMetadata(clip.VideoPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get video metadata: %w", err)
+		}
+
+		start := s.correctVideoTimeZone(videoMeta.CreationTime, detail.Timezone)
+		if clip.ManualStartTime != "" {
+			if start, err = time.Parse(time.RFC3339, clip.ManualStartTime); err != nil {
+				return nil, fmt.Errorf("failed to parse manual start time: %w", err)
+			}
+		}
+
+		end := start.Add(videoMeta.Duration)
+		spans = append(spans, clipSpan{path: clip.VideoPath, start: start, end: end})
+		coverage = append(coverage, gps.TimeRange{Start: start, End: end})
+	}
+
+	highlights := s.HighlightsWithin(processor, detail, coverage, 0)
+	if len(clips) > 0 && len(highlights) == 0 {
+		return nil, fmt.Errorf("no GPS data found for the videos time range")
+	}
+
+	result := make([]ActivityHighlight, 0, len(highlights))
+	for _, highlight := range highlights {
+		activityHighlight := ActivityHighlight{Highlight: highlight}
+		for _, span := range spans {
+			start, end := highlight.Start, highlight.End
+			if start.Before(span.start) {
+				start = span.start
+			}
+			if end.After(span.end) {
+				end = span.end
+			}
+			if end.After(start) {
+				activityHighlight.Clips = append(activityHighlight.Clips, ClipRange{
+					VideoPath: span.path,
+					Start:     start.Sub(span.start),
+					End:       end.Sub(span.start),
+				})
+			}
+		}
+		result = append(result, activityHighlight)
+	}
+
+	log.Printf("⭐ %d destaques detectados na atividade %d (%d vídeos)", len(result), activityID, len(clips))
+	return result, nil
+}
+
+// HighlightsWithin detecta os destaques da trilha dentro dos intervalos informados (nil =
+// atividade inteira). limit > 0 substitui o número de destaques configurado.
+func (s *GPSService) HighlightsWithin(processor *gps.GPSProcessor, detail *strava.ActivityDetail, coverage []gps.TimeRange, limit int) []gps.Highlight {
+	cfg := s.highlightConfig
+	if limit > 0 {
+		cfg.MaxHighlights = limit
+	}
+	return gps.DetectHighlights(processor.GetAllPoints(), segmentEfforts(detail), coverage, cfg)
+}
+
+// ActivityLap é uma volta da atividade com o ponto da trilha onde ela começou
+type ActivityLap struct {
+	strava.Lap
+	StartPoint gps.GPSPoint
+}
+
+// GetActivityLaps retorna as voltas da atividade localizadas na trilha, para listagem no mapa
+func (s *GPSService) GetActivityLaps(client *strava.Client, activityID int64) ([]ActivityLap, error) {
+	detail, err := client.GetActivityDetail(activityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity detail: %w", err)
+	}
+
+	processor, err := s.detailProcessor(client, activityID, detail)
 	if err != nil {
 		return nil, err
 	}
 
-	return processor.GetPointsForTimeRange(startTime, endTime), nil
+	laps := make([]ActivityLap, 0, len(detail.Laps))
+	for _, lap := range detail.Laps {
+		start, _ := processor.GetPointForTime(lap.StartDate)
+		laps = append(laps, ActivityLap{Lap: lap, StartPoint: start})
+	}
+
+	return laps, nil
+}
+
+// lapTimeline converte as voltas e os parciais métricos do Strava para a linha do tempo da trilha
+func lapTimeline(detail *strava.ActivityDetail) *gps.LapTimeline {
+	laps := make([]gps.Lap, 0, len(detail.Laps))
+	for i, lap := range detail.Laps {
+		number := lap.LapIndex
+		if number == 0 {
+			number = i + 1
+		}
+		laps = append(laps, gps.Lap{
+			Number:      number,
+			Name:        lap.Name,
+			Start:       lap.StartDate,
+			ElapsedTime: time.Duration(lap.ElapsedTime) * time.Second,
+			Distance:    lap.Distance,
+		})
+	}
+
+	splits := make([]gps.Split, 0, len(detail.SplitsMetric))
+	for _, split := range detail.SplitsMetric {
+		splits = append(splits, gps.Split{
+			Number:      split.Split,
+			Distance:    split.Distance,
+			ElapsedTime: time.Duration(split.ElapsedTime) * time.Second,
+			MovingTime:  time.Duration(split.MovingTime) * time.Second,
+		})
+	}
+
+	return gps.NewLapTimeline(laps, splits)
+}
+
+// ActivitySegmentEffort é um esforço de segmento com o trecho da trilha que ele percorre
+type ActivitySegmentEffort struct {
+	strava.SegmentEffort
+	Track []gps.GPSPoint
+}
+
+// maxSegmentTrackPoints limita os pontos do trecho de cada segmento enviado ao frontend
+const maxSegmentTrackPoints = 200
+
+// GetSegmentEfforts retorna os esforços de segmento da atividade com seus trechos na trilha
+func (s *GPSService) GetSegmentEfforts(client *strava.Client, activityID int64) ([]ActivitySegmentEffort, error) {
+	detail, err := client.GetActivityDetail(activityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity detail: %w", err)
+	}
+
+	processor, err := s.detailProcessor(client, activityID, detail)
+	if err != nil {
+		return nil, err
+	}
+
+	efforts := make([]ActivitySegmentEffort, 0, len(detail.SegmentEfforts))
+	for _, effort := range detail.SegmentEfforts {
+		end := effort.StartDate.Add(time.Duration(effort.ElapsedTime) * time.Second)
+		track := processor.GetPointsForTimeRange(effort.StartDate, end)
+		if len(track) > maxSegmentTrackPoints {
+			interval := time.Duration(len(track)/maxSegmentTrackPoints+1) * time.Second
+			track = s.selectPointsByInterval(track, interval)
+		}
+		efforts = append(efforts, ActivitySegmentEffort{SegmentEffort: effort, Track: track})
+	}
+
+	log.Printf("🏔️ %d esforços de segmento na atividade %d", len(efforts), activityID)
+	return efforts, nil
+}
+
+// effortTimeline converte os esforços de segmento do Strava para a linha do tempo da trilha
+func effortTimeline(detail *strava.ActivityDetail, track *gps.Interpolator) *gps.EffortTimeline {
+	return gps.NewEffortTimeline(segmentEfforts(detail), track)
+}
+
+// segmentEfforts converte os esforços em segmentos do Strava para a linha do tempo da trilha
+func segmentEfforts(detail *strava.ActivityDetail) []gps.SegmentEffort {
+	efforts := make([]gps.SegmentEffort, 0, len(detail.SegmentEfforts))
+	for _, effort := range detail.SegmentEfforts {
+		segmentEffort := gps.SegmentEffort{
+			Name:        effort.Name,
+			Start:       effort.StartDate,
+			ElapsedTime: time.Duration(effort.ElapsedTime) * time.Second,
+			Distance:    effort.Distance,
+		}
+		if effort.KOMRank != nil {
+			segmentEffort.KOMRank = *effort.KOMRank
+		}
+		if effort.PRRank != nil {
+			segmentEffort.PRRank = *effort.PRRank
+		}
+		efforts = append(efforts, segmentEffort)
+	}
+
+	return efforts
 }
 
 // === MÉTODOS AUXILIARES PRIVADOS ===
 
-// correctVideoTimeZone corrige o fuso horário do vídeo baseado na atividade
-func (s *GPSService) correctVideoTimeZone(videoTimeUTC time.Time, timezone string) time.Time {
+// parseStravaTimezone extrai o fuso IANA do timezone do Strava ("(GMT-03:00)
+// America/Sao_Paulo"). Fusos desconhecidos caem para UTC com um aviso.
+func parseStravaTimezone(timezone string) *time.Location {
 	tzParts := strings.Split(timezone, " ")
 	ianaTZ := tzParts[len(tzParts)-1]
 	location, err := time.LoadLocation(ianaTZ)
 	if err != nil {
 		log.Printf("Aviso: fuso horário desconhecido '%s', usando UTC. Erro: %v", ianaTZ, err)
-		location = time.UTC
+		return time.UTC
 	}
+	return location
+}
+
+// correctVideoTimeZone corrige o fuso horário do vídeo baseado na atividade
+func (s *GPSService) correctVideoTimeZone(videoTimeUTC time.Time, timezone string) time.Time {
+	location := parseStravaTimezone(timezone)
 
 	return time.Date(
 		videoTimeUTC.Year(), videoTimeUTC.Month(), videoTimeUTC.Day(),
@@ -220,37 +729,38 @@ func (s *GPSService) correctVideoTimeZone(videoTimeUTC time.Time, timezone strin
 }
 
 // createGPSProcessor cria um processador GPS a partir dos streams
-func (s *GPSService) createGPSProcessor(streams map[string]strava.ActivityStream, startDate time.Time) (*gps.GPSProcessor, error) {
-	// Valida streams
-	timeStream, timeExists := streams["time"]
-	latlngStream, latlngExists := streams["latlng"]
-	if !timeExists || !latlngExists || timeStream.Data == nil || latlngStream.Data == nil {
+func (s *GPSService) createGPSProcessor(streams *strava.StreamSet, startDate time.Time) (*gps.GPSProcessor, error) {
+	if streams == nil || streams.Len() == 0 {
 		return nil, fmt.Errorf("streams GPS ausentes ou vazios")
 	}
+	log.Printf("📡 Streams: %d amostras, resolução %q, série %q", streams.Len(), streams.Resolution(), streams.SeriesType())
+
+	data := gps.StreamData{
+		Time:   streams.Time.Data,
+		LatLng: streams.LatLng.Data,
+	}
+	if streams.Velocity != nil {
+		data.Velocity = streams.Velocity.Data
+	}
+	if streams.Altitude != nil {
+		data.Altitude = streams.Altitude.Data
+	}
+	if streams.Moving != nil {
+		data.Moving = streams.Moving.Data
+	}
+	if streams.HeartRate != nil {
+		data.HeartRate = streams.HeartRate.Data
+	}
 
 	processor := gps.NewGPSProcessor()
-	err := processor.ProcessStreamData(
-		timeStream.Data.([]interface{}),
-		latlngStream.Data.([]interface{}),
-		s.getOptionalStreamData(streams, "velocity_smooth"),
-		s.getOptionalStreamData(streams, "altitude"),
-		startDate,
-	)
-	if err != nil {
+	processor.SetFilterChain(gps.NewFilterChain(s.filterConfig))
+	if err := processor.ProcessStreamData(data, startDate); err != nil {
 		return nil, fmt.Errorf("failed to process GPS data: %w", err)
 	}
 
 	return processor, nil
 }
 
-// getOptionalStreamData obtém dados de stream opcionais
-func (s *GPSService) getOptionalStreamData(streams map[string]strava.ActivityStream, key string) []interface{} {
-	if stream, exists := streams[key]; exists && stream.Data != nil {
-		return stream.Data.([]interface{})
-	}
-	return nil
-}
-
 // validateGPSCoordinates valida se as coordenadas GPS são válidas
 func (s *GPSService) validateGPSCoordinates(point gps.GPSPoint) error {
 	if point.Lat == 0 && point.Lng == 0 {
@@ -289,7 +799,7 @@ func (s *GPSService) selectIntelligentGPSPoints(allPoints []gps.GPSPoint) []gps.
 		speedChange := math.Abs(currentPoint.Velocity - lastSelectedSpeed)
 
 		// Calcula distância desde o último ponto selecionado
-		distance := s.calculateDistance(lastSelectedPoint, currentPoint)
+		distance := gps.DistanceMeters(lastSelectedPoint, currentPoint)
 
 		// Critérios para seleção:
 		shouldSelect := false
@@ -380,22 +890,3 @@ func (s *GPSService) selectPointsByInterval(allPoints []gps.GPSPoint, interval t
 
 	return selectedPoints
 }
-
-// calculateDistance calcula distância entre dois pontos GPS usando fórmula de Haversine
-func (s *GPSService) calculateDistance(p1, p2 gps.GPSPoint) float64 {
-	const R = 6371000 // Raio da Terra em metros
-
-	lat1Rad := p1.Lat * math.Pi / 180
-	lon1Rad := p1.Lng * math.Pi / 180
-	lat2Rad := p2.Lat * math.Pi / 180
-	lon2Rad := p2.Lng * math.Pi / 180
-
-	dLat := lat2Rad - lat1Rad
-	dLon := lon2Rad - lon1Rad
-
-	haversineA := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(lat1Rad)*math.Cos(lat2Rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
-	c := 2 * math.Atan2(math.Sqrt(haversineA), math.Sqrt(1-haversineA))
-
-	return R * c
-}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"strava-overlay/internal/gps"
+	"strava-overlay/internal/strava"
+)
+
+var testStart = time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+
+// quietLogs silencia os logs de depuração do processamento durante o teste
+func quietLogs(tb testing.TB) {
+	previous := log.Writer()
+	log.SetOutput(io.Discard)
+	tb.Cleanup(func() { log.SetOutput(previous) })
+}
+
+// syntheticStreams gera streams de 1 Hz com curvas, variação de velocidade e paradas
+func syntheticStreams(duration time.Duration, seed int64) *strava.StreamSet {
+	rng := rand.New(rand.NewSource(seed))
+	n := int(duration.Seconds())
+	streams := &strava.StreamSet{
+		Time:     &strava.IntStream{Data: make([]int, n)},
+		LatLng:   &strava.LatLngStream{Data: make([][2]float64, n)},
+		Velocity: &strava.FloatStream{Data: make([]float64, n)},
+		Altitude: &strava.FloatStream{Data: make([]float64, n)},
+	}
+
+	lat, lng, heading := -23.55, -46.63, 0.0
+	for i := 0; i < n; i++ {
+		speed := 8 + 3*math.Sin(float64(i)/120)
+		if i%3600 < 120 {
+			speed = 0 // Parada de dois minutos a cada hora
+		}
+		heading += rng.NormFloat64() * 4
+		lat += speed * math.Cos(heading*math.Pi/180) / 111320
+		lng += speed * math.Sin(heading*math.Pi/180) / (111320 * math.Cos(lat*math.Pi/180))
+
+		streams.Time.Data[i] = i
+		streams.LatLng.Data[i] = [2]float64{lat, lng}
+		streams.Velocity.Data[i] = speed
+		streams.Altitude.Data[i] = 700 + 50*math.Sin(float64(i)/900)
+	}
+	return streams
+}
+
+// outAndBackStreams gera um trajeto de ida e volta pela mesma rua, 600 s para cada lado
+func outAndBackStreams() *strava.StreamSet {
+	const n = 1200
+	streams := &strava.StreamSet{
+		Time:   &strava.IntStream{Data: make([]int, n)},
+		LatLng: &strava.LatLngStream{Data: make([][2]float64, n)},
+	}
+	for i := 0; i < n; i++ {
+		offset := i
+		if i >= n/2 {
+			offset = n - 1 - i
+		}
+		streams.Time.Data[i] = i
+		streams.LatLng.Data[i] = [2]float64{-23.55, -46.63 + float64(offset)*0.00005}
+	}
+	return streams
+}
+
+func processedService(t testing.TB, activityID int64, streams *strava.StreamSet) (*GPSService, *gps.GPSProcessor) {
+	t.Helper()
+	s := NewGPSService()
+	processor, err := s.createGPSProcessor(streams, testStart)
+	if err != nil {
+		t.Fatalf("createGPSProcessor: %v", err)
+	}
+	s.rememberProcessor(activityID, processor)
+	return s, processor
+}
+
+func TestProcessorCacheAvoidsRefetch(t *testing.T) {
+	quietLogs(t)
+	s, processor := processedService(t, 1, outAndBackStreams())
+
+	// Com a trilha em memória, nenhum acesso ao Strava é necessário (cliente nil)
+	got, err := s.activityProcessor(nil, 1)
+	if err != nil {
+		t.Fatalf("activityProcessor: %v", err)
+	}
+	if got != processor {
+		t.Error("a trilha processada deveria ser reaproveitada")
+	}
+	if _, err := s.GetGPSPointForMapClick(nil, 1, -23.55, -46.63); err != nil {
+		t.Errorf("GetGPSPointForMapClick: %v", err)
+	}
+	if track, err := s.GetTrack(nil, 1); err != nil || track != processor {
+		t.Errorf("GetTrack deveria reaproveitar a trilha: %v", err)
+	}
+	if _, err := s.GetIntelligentGPSPoints(nil, 1); err != nil {
+		t.Errorf("GetIntelligentGPSPoints: %v", err)
+	}
+	if _, err := s.GetGPSPointsWithDensity(nil, 1, "high"); err != nil {
+		t.Errorf("GetGPSPointsWithDensity: %v", err)
+	}
+	if _, err := s.GetPointsForTimeRange(nil, 1, testStart, testStart.Add(time.Minute)); err != nil {
+		t.Errorf("GetPointsForTimeRange: %v", err)
+	}
+}
+
+func TestProcessorCacheResetOnConfigChange(t *testing.T) {
+	quietLogs(t)
+
+	s, _ := processedService(t, 1, outAndBackStreams())
+	s.SetFilterConfig(gps.DefaultFilterConfig())
+	if _, ok := s.cachedProcessor(1); ok {
+		t.Error("mudar os filtros deveria descartar as trilhas processadas")
+	}
+
+	s, _ = processedService(t, 1, outAndBackStreams())
+	s.SetStreamOptions(strava.DefaultStreamOptions())
+	if _, ok := s.cachedProcessor(1); ok {
+		t.Error("mudar a resolução dos streams deveria descartar as trilhas processadas")
+	}
+}
+
+func TestProcessorCacheEvictsOldest(t *testing.T) {
+	quietLogs(t)
+	s, _ := processedService(t, 1, outAndBackStreams())
+	for id := int64(2); id <= processorCacheSize+1; id++ {
+		s.rememberProcessor(id, gps.NewGPSProcessor())
+	}
+	// Reusar uma trilha a move para o fim da fila
+	s.rememberProcessor(2, gps.NewGPSProcessor())
+
+	if len(s.processors) != processorCacheSize {
+		t.Fatalf("esperava %d trilhas em memória, obteve %d", processorCacheSize, len(s.processors))
+	}
+	for _, cached := range s.processors {
+		if cached.activityID == 1 {
+			t.Error("a trilha mais antiga deveria ter sido descartada")
+		}
+	}
+	if last := s.processors[len(s.processors)-1].activityID; last != 2 {
+		t.Errorf("a trilha usada por último deveria ficar no fim, ficou %d", last)
+	}
+}
+
+func TestGetGPSPointForSegmentClickOutAndBack(t *testing.T) {
+	quietLogs(t)
+	s, _ := processedService(t, 1, outAndBackStreams())
+
+	// O ponto a 100 s da largada é o mesmo lugar do ponto a 1099 s, na volta
+	lat, lng := -23.55, -46.63+100*0.00005
+
+	nearest, err := s.GetGPSPointForMapClick(nil, 1, lat, lng)
+	if err != nil {
+		t.Fatalf("GetGPSPointForMapClick: %v", err)
+	}
+	if got := nearest.Time.Sub(testStart); got != 100*time.Second {
+		t.Fatalf("a busca só por posição deveria ficar com a ida (100s), ficou com %v", got)
+	}
+
+	point, err := s.GetGPSPointForSegmentClick(nil, 1, lat, lng, testStart.Add(1090*time.Second), testStart.Add(1110*time.Second))
+	if err != nil {
+		t.Fatalf("GetGPSPointForSegmentClick: %v", err)
+	}
+	if got := point.Time.Sub(testStart); got != 1099*time.Second {
+		t.Errorf("o clique no trecho da volta deveria resolver para 1099s, resolveu para %v", got)
+	}
+
+	if _, err := s.GetGPSPointForSegmentClick(nil, 1, lat, lng, testStart.Add(2*time.Hour), testStart.Add(3*time.Hour)); err == nil {
+		t.Error("trecho fora da trilha deveria falhar")
+	}
+}
+
+func TestSimplifyTrajectoryZoomAndLimit(t *testing.T) {
+	quietLogs(t)
+	_, processor := processedService(t, 1, syntheticStreams(2*time.Hour, 1))
+	points := processor.GetAllPoints()
+	opts := gps.DefaultSimplifyOptions()
+	opts.Tolerance = 0
+
+	coarse := simplifyTrajectory(points, 11, opts)
+	fine := simplifyTrajectory(points, 17, opts)
+	if len(coarse) >= len(fine) {
+		t.Errorf("zoom maior deveria manter mais pontos: zoom 11 = %d, zoom 17 = %d", len(coarse), len(fine))
+	}
+
+	opts.TargetPoints = 200
+	if limited := simplifyTrajectory(points, 17, opts); len(limited) > 200 {
+		t.Errorf("o teto de 200 pontos foi ultrapassado: %d", len(limited))
+	}
+	if capped := simplifyTrajectory(points, 11, opts); len(capped) != len(coarse) {
+		t.Errorf("abaixo do teto a tolerância do zoom deveria prevalecer: %d != %d", len(capped), len(coarse))
+	}
+}
+
+// BenchmarkSimplifiedTrajectory10h mede a latência e o tamanho do JSON do trajeto enviado ao
+// mapa para uma atividade de 10 horas, no zoom do enquadramento e no limite de pontos do mapa,
+// contra o envio da trilha completa
+func TestParseStravaTimezone(t *testing.T) {
+	quietLogs(t)
+
+	tests := []struct {
+		timezone string
+		want     string
+	}{
+		{"(GMT-03:00) America/Sao_Paulo", "America/Sao_Paulo"},
+		{"(GMT+01:00) Europe/Lisbon", "Europe/Lisbon"},
+		{"America/Sao_Paulo", "America/Sao_Paulo"},
+		{"(GMT+00:00) Fuso/Inexistente", "UTC"},
+		{"", "UTC"},
+	}
+
+	for _, tt := range tests {
+		if got := parseStravaTimezone(tt.timezone).String(); got != tt.want {
+			t.Errorf("parseStravaTimezone(%q) = %s, esperado %s", tt.timezone, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkSimplifiedTrajectory10h(b *testing.B) {
+	quietLogs(b)
+	s, processor := processedService(b, 1, syntheticStreams(10*time.Hour, 2))
+
+	opts := gps.DefaultSimplifyOptions()
+	opts.Tolerance = 0
+	opts.TargetPoints = 3000
+
+	full, err := json.Marshal(processor.GetAllPoints())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, zoom := range []int{0, 11, 14} {
+		b.Run(fmt.Sprintf("zoom%d", zoom), func(b *testing.B) {
+			var payload []byte
+			var points int
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				simplified, err := s.GetSimplifiedTrajectory(nil, 1, zoom, opts)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if payload, err = json.Marshal(simplified); err != nil {
+					b.Fatal(err)
+				}
+				points = len(simplified)
+			}
+			b.ReportMetric(float64(points), "points")
+			b.ReportMetric(float64(len(payload)), "payload_bytes")
+			b.ReportMetric(float64(len(full)), "full_bytes")
+		})
+	}
+}
+
+// BenchmarkProcessStreams10h mede o processamento de uma atividade de 10 horas, o custo que a
+// trilha em memória evita a cada clique no mapa
+func BenchmarkProcessStreams10h(b *testing.B) {
+	quietLogs(b)
+	streams := syntheticStreams(10*time.Hour, 2)
+	s := NewGPSService()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.createGPSProcessor(streams, testStart); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
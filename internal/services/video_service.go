@@ -6,9 +6,14 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"strava-overlay/internal/export"
+	"strava-overlay/internal/gps"
 	"strava-overlay/internal/overlay"
 	"strava-overlay/internal/strava"
 	"strava-overlay/internal/video"
@@ -17,19 +22,61 @@ import (
 // ProgressCallback é chamado durante o processamento para reportar progresso
 type ProgressCallback func(stage string, progress float64, message string)
 
+// TimeWindow é um trecho a renderizar, com entrada e saída em tempo do vídeo ou em horário GPS
+type TimeWindow struct {
+	Start, End       time.Duration // Tempo do vídeo
+	GPSStart, GPSEnd time.Time     // Horário GPS; quando preenchido substitui o tempo do vídeo
+}
+
+// RenderOptions ajusta uma renderização
+type RenderOptions struct {
+	Windows []TimeWindow         // Trechos incluídos, concatenados na ordem dada; vazio = vídeo inteiro
+	Draft   *video.DraftSettings // Rascunho rápido em baixa resolução; nil = renderização final
+	// Número de destaques detectados automaticamente na telemetria e cortados em ordem
+	// cronológica quando nenhum trecho é informado; 0 = desativado
+	Highlights int
+}
+
 // VideoService encapsula toda a lógica complexa de processamento de vídeo
 type VideoService struct {
 	progressCallback   ProgressCallback
-	completionCallback func(success bool, outputPath string, err error)
+	completionCallback func(success bool, outputPath string, draft bool, err error)
+	gMeterStyle        string
+	overlayWidgets     []string
+	stopBehavior       string
+	segmentBanner      bool
+	showPaused         bool
+	trackExportFormats []string
+	subtitleFormats    []string
+	subtitleTemplate   string
+	muxSubtitles       bool
+	embedTelemetry     bool
+	outputContainer    string
+	draftSettings      video.DraftSettings
+	overlayScale       float64
+
+	previewMu sync.Mutex
+	preview   *previewSession // Dados da última pré-visualização, reaproveitados entre quadros
+}
+
+// previewSession guarda o que a pré-visualização precisa de uma atividade e um vídeo, para que
+// quadros seguintes só extraiam o quadro e desenhem o overlay
+type previewSession struct {
+	activityID int64
+	videoPath  string
+	videoMeta  *video.VideoMetadata
+	detail     *strava.ActivityDetail
+	processor  *gps.GPSProcessor
+	track      *gps.Interpolator
 }
 
-func (s *VideoService) SetCompletionCallback(callback func(success bool, outputPath string, err error)) {
+func (s *VideoService) SetCompletionCallback(callback func(success bool, outputPath string, draft bool, err error)) {
 	s.completionCallback = callback
 }
 
 // NewVideoService cria um novo serviço de vídeo
 func NewVideoService() *VideoService {
-	return &VideoService{}
+	return &VideoService{draftSettings: video.DefaultDraftSettings(), overlayScale: 1}
 }
 
 // SetProgressCallback define o callback de progresso
@@ -37,6 +84,78 @@ func (s *VideoService) SetProgressCallback(callback ProgressCallback) {
 	s.progressCallback = callback
 }
 
+// SetGMeterStyle define o estilo do widget de G-force usado nos overlays
+func (s *VideoService) SetGMeterStyle(style string) {
+	s.gMeterStyle = style
+}
+
+// SetOverlayWidgets define os widgets exibidos na pilha ao lado do velocímetro
+func (s *VideoService) SetOverlayWidgets(widgets []string) {
+	s.overlayWidgets = widgets
+}
+
+// SetStopBehavior define se o overlay continua, congela ou some durante paradas
+func (s *VideoService) SetStopBehavior(behavior string) {
+	s.stopBehavior = behavior
+}
+
+// SetSegmentBanner liga ou desliga o banner dos segmentos do Strava no overlay
+func (s *VideoService) SetSegmentBanner(enabled bool) {
+	s.segmentBanner = enabled
+}
+
+// SetShowPaused liga ou desliga a indicação "PAUSED" nos trechos marcados como parados
+func (s *VideoService) SetShowPaused(enabled bool) {
+	s.showPaused = enabled
+}
+
+// SetTrackExportFormats define em quais formatos a trilha do clipe é exportada junto ao vídeo
+func (s *VideoService) SetTrackExportFormats(formats []string) {
+	s.trackExportFormats = formats
+}
+
+// SetSubtitleFormats define os formatos das legendas de telemetria gravadas junto ao vídeo
+func (s *VideoService) SetSubtitleFormats(formats []string) {
+	s.subtitleFormats = formats
+}
+
+// SetSubtitleTemplate define o modelo de texto de cada legenda (ver export.SubtitleFields)
+func (s *VideoService) SetSubtitleTemplate(template string) {
+	s.subtitleTemplate = template
+}
+
+// SetMuxSubtitles inclui as legendas geradas como faixas no próprio vídeo de saída
+func (s *VideoService) SetMuxSubtitles(enabled bool) {
+	s.muxSubtitles = enabled
+}
+
+// SetEmbedTelemetry grava a telemetria sincronizada no próprio vídeo de saída: faixa GPMF no
+// MP4, anexo JSON no MKV e a localização ISO 6709 nos metadados do container
+func (s *VideoService) SetEmbedTelemetry(enabled bool) {
+	s.embedTelemetry = enabled
+}
+
+// SetOutputContainer define o container do vídeo de saída ("mp4" ou "mkv")
+func (s *VideoService) SetOutputContainer(container string) {
+	s.outputContainer = container
+}
+
+// SetOverlayScale define o fator do usuário aplicado sobre a escala automática do overlay,
+// que acompanha a resolução do vídeo
+func (s *VideoService) SetOverlayScale(scale float64) {
+	s.overlayScale = scale
+}
+
+// SetDraftSettings define a resolução, a taxa de quadros e a duração padrão dos rascunhos
+func (s *VideoService) SetDraftSettings(settings video.DraftSettings) {
+	s.draftSettings = settings
+}
+
+// DraftSettings retorna as configurações de rascunho, base para escolher o trecho a renderizar
+func (s *VideoService) DraftSettings() video.DraftSettings {
+	return s.draftSettings
+}
+
 // reportProgress envia atualização de progresso se callback estiver definido
 func (s *VideoService) reportProgress(stage string, progress float64, message string) {
 	if s.progressCallback != nil {
@@ -45,7 +164,10 @@ func (s *VideoService) reportProgress(stage string, progress float64, message st
 	log.Printf("📊 [%s] %.1f%% - %s", stage, progress, message)
 }
 
-// ProcessVideoWithOverlay processa um vídeo aplicando overlay com dados GPS
+// ProcessVideoWithOverlay processa um vídeo aplicando overlay com dados GPS. Com trechos, só
+// eles são renderizados; vários trechos formam um highlight reel. Com rascunho, gera um vídeo
+// rápido e reduzido (por padrão do trecho das configurações de rascunho). Legendas, telemetria
+// embutida e exportação da trilha só acompanham renderizações finais de um trecho contínuo.
 func (s *VideoService) ProcessVideoWithOverlay(
 	ctx context.Context,
 	client *strava.Client,
@@ -54,7 +176,10 @@ func (s *VideoService) ProcessVideoWithOverlay(
 	manualStartTimeStr string,
 	overlayPosition string,
 	gpsService *GPSService,
+	options RenderOptions,
 ) (string, error) {
+	draft := options.Draft
+
 	s.reportProgress("init", 0, "Iniciando processamento...")
 
 	// Verifica cancelamento em cada etapa
@@ -81,6 +206,11 @@ func (s *VideoService) ProcessVideoWithOverlay(
 	}
 	s.reportProgress("activity", 20, fmt.Sprintf("Atividade: %s", detail.Name))
 
+	athlete, err := client.GetAthlete()
+	if err != nil {
+		log.Printf("⚠️ Não foi possível identificar o atleta da atividade: %v", err)
+	}
+
 	if ctx.Err() != nil {
 		return "", ctx.Err()
 	}
@@ -98,21 +228,72 @@ func (s *VideoService) ProcessVideoWithOverlay(
 
 	s.reportProgress("gps", 35, "Carregando dados GPS...")
 	correctedVideoEndTime := correctedVideoStartTime.Add(videoMeta.Duration)
-	gpsPoints, err := gpsService.GetPointsForTimeRange(client, activityID, correctedVideoStartTime, correctedVideoEndTime)
+	processor, err := gpsService.GetTrack(client, activityID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get GPS points: %w", err)
 	}
-	if len(gpsPoints) == 0 {
+	track := processor.Interpolator()
+	trackStart, trackEnd, ok := track.Range()
+	if !ok || trackEnd.Before(correctedVideoStartTime) || trackStart.After(correctedVideoEndTime) {
 		return "", fmt.Errorf("no GPS data found for video time range")
 	}
-	s.reportProgress("gps", 40, fmt.Sprintf("%d pontos GPS carregados", len(gpsPoints)))
+	// Trechos renderizados: os informados, a janela do rascunho ou o vídeo inteiro (nenhum)
+	windows := resolveWindows(options.Windows, correctedVideoStartTime, videoMeta.Duration)
+	if len(options.Windows) > 0 && len(windows) == 0 {
+		return "", fmt.Errorf("nenhum trecho informado está dentro do vídeo")
+	}
+	if len(windows) == 0 && options.Highlights > 0 {
+		windows = highlightWindows(gpsService, processor, detail, correctedVideoStartTime, videoMeta.Duration, options.Highlights)
+		if len(windows) == 0 {
+			return "", fmt.Errorf("nenhum destaque encontrado no trecho do vídeo")
+		}
+	}
+	frameRate := videoMeta.FrameRate
+	if draft != nil {
+		if len(windows) == 0 {
+			start, duration, err := draft.Window(videoMeta.Duration)
+			if err != nil {
+				return "", err
+			}
+			windows = []video.TimeWindow{{Start: start, End: start + duration}}
+		}
+		frameRate = draft.FrameRateFor(videoMeta.FrameRate)
+		log.Printf("📝 Rascunho em %dp a %.0f fps", draft.Height, frameRate)
+	}
+	for i, window := range windows {
+		log.Printf("✂️ Trecho %d: %.1fs a %.1fs do vídeo", i+1, window.Start.Seconds(), window.End.Seconds())
+	}
+
+	timeline := []overlay.TimelineWindow{{Start: correctedVideoStartTime, Duration: videoMeta.Duration}}
+	if len(windows) > 0 {
+		timeline = make([]overlay.TimelineWindow, len(windows))
+		for i, window := range windows {
+			timeline[i] = overlay.TimelineWindow{Start: correctedVideoStartTime.Add(window.Start), Duration: window.Duration()}
+		}
+	}
+	var timelineDuration time.Duration
+	for _, window := range timeline {
+		timelineDuration += window.Duration
+	}
+	overlayRate := overlay.OverlayFrameRateFor(frameRate, timelineDuration)
+	if overlayRate < overlay.OverlayFrameRate(frameRate) {
+		log.Printf("🎞️ Overlay reduzido para %.2f fps para não ultrapassar %d quadros", overlayRate, overlay.MaxOverlayFrames)
+	}
+	sampler := gps.NewFrameSampler(track, overlayRate)
+	s.reportProgress("gps", 40, fmt.Sprintf("Trilha GPS carregada (%.2f fps)", sampler.FrameRate()))
 
 	if ctx.Err() != nil {
 		return "", ctx.Err()
 	}
 
 	s.reportProgress("overlay", 45, "Gerando overlays...")
-	overlayGen := overlay.NewGeneratorWithPosition(overlayPosition)
+	// O overlay acompanha a resolução de saída: no rascunho, a do vídeo já reduzido
+	outputWidth, outputHeight := videoMeta.Width, videoMeta.Height
+	if draft != nil {
+		scale := draft.Scale(videoMeta.Width, videoMeta.Height)
+		outputWidth, outputHeight = int(float64(outputWidth)*scale), int(float64(outputHeight)*scale)
+	}
+	overlayGen := s.newOverlayGenerator(overlayPosition, outputWidth, outputHeight, detail, processor, track)
 	defer overlayGen.Cleanup()
 
 	overlayGen.SetProgressCallback(func(current, total int) {
@@ -123,7 +304,7 @@ func (s *VideoService) ProcessVideoWithOverlay(
 		s.reportProgress("overlay", progress, fmt.Sprintf("Gerando overlay %d/%d", current, total))
 	})
 
-	overlayImages, err := overlayGen.GenerateOverlaySequence(gpsPoints, videoMeta.FrameRate)
+	overlayImages, err := overlayGen.GenerateOverlaySequenceForWindows(sampler, timeline)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate overlays: %w", err)
 	}
@@ -135,30 +316,86 @@ func (s *VideoService) ProcessVideoWithOverlay(
 	}
 
 	s.reportProgress("output", 65, "Preparando arquivo de saída...")
-	outputPath, err := s.generateOutputPath(activityID)
+	outputPath, err := s.generateOutputPath(activityID, athlete, outputSuffix(draft != nil, len(windows)))
 	if err != nil {
 		return "", fmt.Errorf("failed to generate output path: %w", err)
 	}
 	s.reportProgress("output", 70, "Caminho definido")
 
+	// Com um único trecho o clipe acompanha o corte, mantendo legendas e telemetria alinhadas
+	clip := s.clipFor(detail, processor, timeline[0].Start, timeline[0].Duration)
+	final := draft == nil
+	withTelemetry := final && len(timeline) == 1
+	if final && !withTelemetry {
+		log.Printf("ℹ️ Highlight reel: legendas, telemetria embutida e exportação da trilha desativadas")
+	}
+
+	var subtitlePaths []string
+	if withTelemetry && len(s.subtitleFormats) > 0 {
+		s.reportProgress("output", 70, "Gerando legendas de telemetria...")
+		subtitlePaths = s.writeSubtitles(outputPath, clip, detail.Timezone)
+	}
+
 	s.reportProgress("encoding", 70, "Iniciando codificação do vídeo...")
 	videoProcessor := video.NewProcessor()
+	metadata := s.renderMetadata(athlete, detail)
+	if s.muxSubtitles {
+		videoProcessor.SetSubtitles(subtitlePaths)
+	}
+	if withTelemetry && s.embedTelemetry && len(clip.Points) > 0 {
+		location := export.ISO6709(clip.Points[0])
+		metadata["location"] = location
+		metadata["com.apple.quicktime.location.ISO6709"] = location
+
+		if video.IsMKVContainer(outputPath) {
+			if jsonPath := s.writeTelemetryAttachment(clip); jsonPath != "" {
+				defer os.Remove(jsonPath)
+				videoProcessor.SetAttachments([]video.Attachment{{
+					Path:     jsonPath,
+					MimeType: "application/json",
+					FileName: "telemetry.json",
+				}})
+			}
+		}
+	}
+	videoProcessor.SetMetadata(metadata)
+	videoProcessor.SetWindows(windows)
 
 	videoProcessor.SetProgressCallback(func(progress float64) {
 		encodingProgress := 70 + (25 * progress / 100)
 		s.reportProgress("encoding", encodingProgress, fmt.Sprintf("Codificando: %.1f%%", progress))
 	})
 
-	err = videoProcessor.ApplyOverlaysWithPosition(ctx, videoPath, overlayImages, outputPath, overlayPosition)
+	err = videoProcessor.ApplyOverlaysWithPosition(ctx, videoPath, overlayImages, outputPath, overlayPosition, draft)
 	if err != nil {
 		if s.completionCallback != nil {
-			s.completionCallback(false, "", err)
+			s.completionCallback(false, "", !final, err)
 		}
 		return "", fmt.Errorf("failed to apply overlays: %w", err)
 	}
 
+	if withTelemetry && s.embedTelemetry && video.IsMOVContainer(outputPath) {
+		s.reportProgress("export", 96, "Embutindo telemetria GPMF...")
+		if err := s.embedGPMFTrack(outputPath, clip, timeline[0].Duration); err != nil {
+			// A faixa é gravada de forma atômica: o vídeo continua íntegro, só sem a telemetria
+			log.Printf("⚠️ Vídeo gerado sem a telemetria GPMF: %v", err)
+			s.reportProgress("export", 96, "Vídeo gerado sem a telemetria GPMF")
+		}
+	}
+
+	if withTelemetry && len(s.trackExportFormats) > 0 {
+		s.reportProgress("export", 97, "Exportando trilha do clipe...")
+		s.exportClipTrack(outputPath, clip)
+	}
+
 	if s.completionCallback != nil {
-		s.completionCallback(true, outputPath, nil)
+		s.completionCallback(true, outputPath, !final, nil)
+	}
+
+	if !final {
+		s.reportProgress("complete", 100, "Rascunho concluído!")
+		log.Printf("📝 Rascunho gerado: %s", outputPath)
+		return outputPath, nil
 	}
 
 	s.reportProgress("complete", 100, "Processamento concluído!")
@@ -168,6 +405,203 @@ func (s *VideoService) ProcessVideoWithOverlay(
 
 // === MÉTODOS AUXILIARES (sem mudanças) ===
 
+// newOverlayGenerator configura o gerador de overlays com as opções do serviço, em escala para
+// um vídeo de width x height pixels
+func (s *VideoService) newOverlayGenerator(position string, width, height int, detail *strava.ActivityDetail, processor *gps.GPSProcessor, track *gps.Interpolator) *overlay.Generator {
+	overlayGen := overlay.NewGeneratorWithPosition(position)
+	overlayGen.SetScale(overlay.ScaleForVideo(width, height, s.overlayScale))
+	overlayGen.SetGMeterStyle(s.gMeterStyle)
+	overlayGen.SetWidgets(s.overlayWidgets)
+	overlayGen.SetLapTimeline(lapTimeline(detail))
+	overlayGen.SetShowPaused(s.showPaused)
+	if s.segmentBanner && len(detail.SegmentEfforts) > 0 {
+		overlayGen.SetSegmentEfforts(effortTimeline(detail, track))
+	}
+	if s.stopBehavior != "" && s.stopBehavior != overlay.StopBehaviorNone {
+		overlayGen.SetStopBehavior(s.stopBehavior, processor.Segments())
+	}
+	return overlayGen
+}
+
+// PreviewOverlayFrame extrai o quadro do vídeo em videoTime e sobrepõe o overlay do ponto GPS
+// correspondente, retornando um JPEG reduzido (ver video.EncodePreview). A sincronização segue ProcessVideoWithOverlay
+// (manualStartTimeStr vazio usa o creation_time do vídeo). Metadados, atividade e trilha
+// ficam em cache, então trocar o instante, a posição ou a sincronização só custa a extração
+// de um quadro.
+func (s *VideoService) PreviewOverlayFrame(
+	ctx context.Context,
+	client *strava.Client,
+	activityID int64,
+	videoPath string,
+	manualStartTimeStr string,
+	overlayPosition string,
+	videoTime time.Duration,
+	gpsService *GPSService,
+) ([]byte, error) {
+	session, err := s.previewSessionFor(client, activityID, videoPath, gpsService)
+	if err != nil {
+		return nil, err
+	}
+	if videoTime < 0 || videoTime > session.videoMeta.Duration {
+		return nil, fmt.Errorf("instante %.1fs fora do vídeo (duração %.1fs)",
+			videoTime.Seconds(), session.videoMeta.Duration.Seconds())
+	}
+
+	videoStart, err := s.determineVideoStartTime(session.videoMeta, session.detail, manualStartTimeStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine video start time: %w", err)
+	}
+
+	point, ok := session.track.At(videoStart.Add(videoTime))
+	if !ok {
+		return nil, fmt.Errorf("no GPS data found for video time %.1fs", videoTime.Seconds())
+	}
+
+	extractStarted := time.Now()
+	frame, err := video.ExtractFrame(ctx, videoPath, videoTime)
+	if err != nil {
+		return nil, err
+	}
+	extracted := time.Since(extractStarted)
+	renderStarted := time.Now()
+
+	// Mesmo fundo de escala do velocímetro que a renderização completa usaria
+	clipPoints := session.processor.GetPointsForTimeRange(videoStart, videoStart.Add(session.videoMeta.Duration))
+	overlayGen := s.newOverlayGenerator(overlayPosition, frame.Bounds().Dx(), frame.Bounds().Dy(), session.detail, session.processor, session.track)
+	defer overlayGen.Cleanup()
+	overlayImage := overlayGen.RenderOverlay(point, overlay.SpeedScale(clipPoints))
+
+	// O overlay é composto na resolução original para ficar idêntico ao vídeo final; só a
+	// imagem enviada ao frontend é reduzida
+	preview, err := video.EncodePreview(video.CompositeOverlay(frame, overlayImage, overlayPosition))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🖼️ Quadro %dx%d extraído em %v, overlay e codificação em %v (%d KB)",
+		frame.Bounds().Dx(), frame.Bounds().Dy(), extracted.Round(time.Millisecond),
+		time.Since(renderStarted).Round(time.Millisecond), len(preview)/1024)
+	return preview, nil
+}
+
+// previewSessionFor retorna os dados da pré-visualização, carregando-os só quando a atividade
+// ou o vídeo mudam
+func (s *VideoService) previewSessionFor(client *strava.Client, activityID int64, videoPath string, gpsService *GPSService) (*previewSession, error) {
+	s.previewMu.Lock()
+	defer s.previewMu.Unlock()
+
+	if s.preview != nil && s.preview.activityID == activityID && s.preview.videoPath == videoPath {
+		return s.preview, nil
+	}
+
+	videoMeta, err := video.GetVideoMetadata(videoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video metadata: %w", err)
+	}
+
+	detail, err := client.GetActivityDetail(activityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity detail: %w", err)
+	}
+
+	processor, err := gpsService.GetTrack(client, activityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GPS points: %w", err)
+	}
+
+	s.preview = &previewSession{
+		activityID: activityID,
+		videoPath:  videoPath,
+		videoMeta:  videoMeta,
+		detail:     detail,
+		processor:  processor,
+		track:      processor.Interpolator(),
+	}
+	log.Printf("🖼️ Pré-visualização preparada para atividade %d e vídeo %s", activityID, filepath.Base(videoPath))
+	return s.preview, nil
+}
+
+// clipFor monta o trecho da atividade coberto pelo vídeo
+func (s *VideoService) clipFor(detail *strava.ActivityDetail, processor *gps.GPSProcessor, videoStart time.Time, duration time.Duration) export.Clip {
+	return export.Clip{
+		Name:         detail.Name,
+		ActivityID:   detail.ID,
+		ActivityType: detail.Type,
+		VideoStart:   videoStart,
+		Points:       processor.GetPointsForTimeRange(videoStart, videoStart.Add(duration)),
+	}
+}
+
+// writeSubtitles grava as legendas de telemetria ao lado do vídeo. Falhas são apenas
+// registradas para não impedir a renderização.
+func (s *VideoService) writeSubtitles(outputPath string, clip export.Clip, timezone string) []string {
+	subtitles, err := export.NewSubtitles(s.subtitleTemplate)
+	if err != nil {
+		log.Printf("⚠️ %v", err)
+		return nil
+	}
+	subtitles.SetLocation(parseStravaTimezone(timezone))
+
+	paths, err := subtitles.WriteFiles(outputPath, clip, s.subtitleFormats)
+	if err != nil {
+		log.Printf("⚠️ Falha ao gerar legendas de telemetria: %v", err)
+	}
+	for _, path := range paths {
+		log.Printf("💬 Legenda de telemetria gerada: %s", path)
+	}
+	return paths
+}
+
+// exportClipTrack grava ao lado do vídeo a trilha GPS do intervalo coberto por ele. Falhas são
+// apenas registradas, pois o vídeo já foi gerado.
+func (s *VideoService) exportClipTrack(outputPath string, clip export.Clip) {
+	paths, err := export.WriteClip(outputPath, clip, s.trackExportFormats)
+	if err != nil {
+		log.Printf("⚠️ Falha ao exportar trilha do clipe: %v", err)
+	}
+	for _, path := range paths {
+		log.Printf("🗺️ Trilha do clipe exportada: %s", path)
+	}
+}
+
+// writeTelemetryAttachment grava a telemetria do clipe num JSON temporário para ser anexado ao
+// MKV. Retorna "" em caso de falha, que é apenas registrada.
+func (s *VideoService) writeTelemetryAttachment(clip export.Clip) string {
+	file, err := os.CreateTemp("", "telemetry_*.json")
+	if err != nil {
+		log.Printf("⚠️ Falha ao criar anexo de telemetria: %v", err)
+		return ""
+	}
+	defer file.Close()
+
+	if err := export.WriteTelemetryJSON(file, clip); err != nil {
+		log.Printf("⚠️ Falha ao gravar anexo de telemetria: %v", err)
+		os.Remove(file.Name())
+		return ""
+	}
+	return file.Name()
+}
+
+// embedGPMFTrack acrescenta ao MP4 já codificado uma faixa GPMF com o GPS do clipe, no formato
+// da telemetria da GoPro. Em caso de erro o vídeo permanece como o ffmpeg o gerou.
+func (s *VideoService) embedGPMFTrack(outputPath string, clip export.Clip, duration time.Duration) error {
+	gpmf := export.GPMFSamples(clip, duration)
+	if len(gpmf) == 0 {
+		return fmt.Errorf("nenhum ponto GPS no intervalo do vídeo")
+	}
+
+	samples := make([]video.TimedSample, len(gpmf))
+	for i, sample := range gpmf {
+		samples[i] = video.TimedSample{Duration: sample.Duration, Data: sample.Data}
+	}
+
+	if err := video.AddTimedMetadataTrack(outputPath, "gpmd", "GoPro MET", samples); err != nil {
+		return fmt.Errorf("falha ao embutir telemetria GPMF: %w", err)
+	}
+	log.Printf("📡 Telemetria GPMF embutida: %d amostras", len(samples))
+	return nil
+}
+
 func (s *VideoService) determineVideoStartTime(
 	videoMeta *video.VideoMetadata,
 	detail *strava.ActivityDetail,
@@ -183,13 +617,7 @@ func (s *VideoService) determineVideoStartTime(
 	}
 
 	videoTimeUTC := videoMeta.CreationTime
-	tzParts := strings.Split(detail.Timezone, " ")
-	ianaTZ := tzParts[len(tzParts)-1]
-	location, err := time.LoadLocation(ianaTZ)
-	if err != nil {
-		log.Printf("Aviso: fuso horário desconhecido '%s', usando UTC como padrão. Erro: %v", ianaTZ, err)
-		location = time.UTC
-	}
+	location := parseStravaTimezone(detail.Timezone)
 
 	correctedTime := time.Date(
 		videoTimeUTC.Year(), videoTimeUTC.Month(), videoTimeUTC.Day(),
@@ -201,22 +629,124 @@ func (s *VideoService) determineVideoStartTime(
 	return correctedTime, nil
 }
 
-func (s *VideoService) generateOutputPath(activityID int64) (string, error) {
+// resolveWindows converte os trechos para tempo do vídeo, limitando-os à sua duração. Trechos
+// vazios ou fora do vídeo são descartados.
+func resolveWindows(windows []TimeWindow, videoStart time.Time, videoDuration time.Duration) []video.TimeWindow {
+	var resolved []video.TimeWindow
+	for i, window := range windows {
+		start, end := window.Start, window.End
+		if !window.GPSStart.IsZero() {
+			start = window.GPSStart.Sub(videoStart)
+		}
+		if !window.GPSEnd.IsZero() {
+			end = window.GPSEnd.Sub(videoStart)
+		}
+
+		start, end = max(start, 0), min(end, videoDuration)
+		if end <= start {
+			log.Printf("⚠️ Trecho %d ignorado: fora do vídeo ou vazio", i+1)
+			continue
+		}
+		resolved = append(resolved, video.TimeWindow{Start: start, End: end})
+	}
+	return resolved
+}
+
+// highlightWindows detecta os destaques no trecho filmado e os converte para tempo do vídeo,
+// em ordem cronológica
+func highlightWindows(gpsService *GPSService, processor *gps.GPSProcessor, detail *strava.ActivityDetail, videoStart time.Time, videoDuration time.Duration, count int) []video.TimeWindow {
+	coverage := []gps.TimeRange{{Start: videoStart, End: videoStart.Add(videoDuration)}}
+	highlights := gpsService.HighlightsWithin(processor, detail, coverage, count)
+	sort.Slice(highlights, func(i, j int) bool { return highlights[i].Start.Before(highlights[j].Start) })
+
+	windows := make([]TimeWindow, len(highlights))
+	for i, highlight := range highlights {
+		log.Printf("⭐ Destaque %d: %s (pontuação %.2f)", i+1, highlight.Reason, highlight.Score)
+		windows[i] = TimeWindow{GPSStart: highlight.Start, GPSEnd: highlight.End}
+	}
+	return resolveWindows(windows, videoStart, videoDuration)
+}
+
+// outputSuffix identifica o tipo de renderização no nome do arquivo de saída
+func outputSuffix(draft bool, windows int) string {
+	switch {
+	case draft:
+		return "draft"
+	case windows > 1:
+		return "highlights"
+	case windows == 1:
+		return "trim"
+	default:
+		return "overlay"
+	}
+}
+
+// generateOutputPath gera o caminho de saída dentro do diretório do atleta, com o tipo de
+// renderização no nome para que rascunhos e cortes não sobrescrevam o vídeo completo.
+// Rascunhos são sempre MP4.
+func (s *VideoService) generateOutputPath(activityID int64, athlete *strava.Athlete, suffix string) (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
 
 	outputDir := filepath.Join(homeDir, "Strava Add Overlay")
+	if athlete != nil {
+		outputDir = filepath.Join(outputDir, athleteDirName(athlete))
+	}
 	err = os.MkdirAll(outputDir, 0755)
 	if err != nil {
 		return "", fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	outputPath := filepath.Join(outputDir, fmt.Sprintf("activity_%d_overlay.mp4", activityID))
+	container := "mp4"
+	if strings.EqualFold(s.outputContainer, "mkv") {
+		container = "mkv"
+	}
+
+	if suffix == "draft" {
+		container = "mp4"
+	}
+
+	outputPath := filepath.Join(outputDir, fmt.Sprintf("activity_%d_%s.%s", activityID, suffix, container))
 	return outputPath, nil
 }
 
+// renderMetadata registra no vídeo de saída de qual atleta e atividade vieram os dados
+func (s *VideoService) renderMetadata(athlete *strava.Athlete, detail *strava.ActivityDetail) map[string]string {
+	metadata := map[string]string{
+		"strava_activity_id": fmt.Sprintf("%d", detail.ID),
+		"title":              detail.Name,
+	}
+
+	if athlete != nil {
+		name := strings.TrimSpace(athlete.FirstName + " " + athlete.LastName)
+		if name == "" {
+			name = athlete.Username
+		}
+		metadata["artist"] = name
+		metadata["strava_athlete_id"] = fmt.Sprintf("%d", athlete.ID)
+		metadata["comment"] = fmt.Sprintf("Strava athlete %d, activity %d", athlete.ID, detail.ID)
+	}
+
+	return metadata
+}
+
+var unsafePathChars = regexp.MustCompile(`[^\pL\pN _.-]+`)
+
+// athleteDirName gera um nome de diretório legível e estável para o atleta
+func athleteDirName(athlete *strava.Athlete) string {
+	name := strings.TrimSpace(athlete.FirstName + " " + athlete.LastName)
+	if name == "" {
+		name = athlete.Username
+	}
+	name = strings.TrimSpace(unsafePathChars.ReplaceAllString(name, ""))
+	if name == "" {
+		return fmt.Sprintf("%d", athlete.ID)
+	}
+	return fmt.Sprintf("%s (%d)", name, athlete.ID)
+}
+
 func (s *VideoService) ValidateVideoFile(videoPath string) error {
 	if _, err := os.Stat(videoPath); os.IsNotExist(err) {
 		return fmt.Errorf("video file does not exist: %s", videoPath)
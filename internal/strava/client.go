@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -13,6 +15,17 @@ import (
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
+
+	// athleteMutex garante uma única busca do atleta por vez (ver GetAthlete)
+	athleteMutex sync.Mutex
+	athlete      *Athlete
+}
+
+type Athlete struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"firstname"`
+	LastName  string `json:"lastname"`
 }
 
 type Activity struct {
@@ -23,6 +36,7 @@ type Activity struct {
 	Timezone     string    `json:"timezone"`
 	Distance     float64   `json:"distance"`
 	MovingTime   int       `json:"moving_time"`
+	ElapsedTime  int       `json:"elapsed_time"`
 	MaxSpeed     float64   `json:"max_speed"`
 	HasHeartrate bool      `json:"has_heartrate"`
 	StartLatLng  []float64 `json:"start_latlng"`
@@ -38,13 +52,81 @@ type Map struct {
 
 type ActivityDetail struct {
 	*Activity
-	Calories      float64 `json:"calories"`
-	ElevationGain float64 `json:"total_elevation_gain"`
+	Calories       float64         `json:"calories"`
+	ElevationGain  float64         `json:"total_elevation_gain"`
+	Laps           []Lap           `json:"laps"`
+	SplitsMetric   []Split         `json:"splits_metric"`
+	SplitsStandard []Split         `json:"splits_standard"`
+	BestEfforts    []BestEffort    `json:"best_efforts"`
+	SegmentEfforts []SegmentEffort `json:"segment_efforts"`
+}
+
+// Lap é uma volta registrada pelo dispositivo (manual ou automática)
+type Lap struct {
+	ID                 int64     `json:"id"`
+	Name               string    `json:"name"`
+	LapIndex           int       `json:"lap_index"`
+	StartDate          time.Time `json:"start_date"`
+	ElapsedTime        int       `json:"elapsed_time"`
+	MovingTime         int       `json:"moving_time"`
+	Distance           float64   `json:"distance"`
+	StartIndex         int       `json:"start_index"`
+	EndIndex           int       `json:"end_index"`
+	TotalElevationGain float64   `json:"total_elevation_gain"`
+	AverageSpeed       float64   `json:"average_speed"`
+	MaxSpeed           float64   `json:"max_speed"`
+	AverageHeartrate   float64   `json:"average_heartrate"`
+}
+
+// Split é o parcial calculado pelo Strava a cada quilômetro (metric) ou milha (standard)
+type Split struct {
+	Split               int     `json:"split"`
+	Distance            float64 `json:"distance"`
+	ElapsedTime         int     `json:"elapsed_time"`
+	MovingTime          int     `json:"moving_time"`
+	ElevationDifference float64 `json:"elevation_difference"`
+	AverageSpeed        float64 `json:"average_speed"`
+	AverageHeartrate    float64 `json:"average_heartrate"`
+	PaceZone            int     `json:"pace_zone"`
 }
 
-type ActivityStream struct {
-	Type string      `json:"type"`
-	Data interface{} `json:"data"`
+// SegmentEffort é a passagem do atleta por um segmento do Strava durante a atividade
+type SegmentEffort struct {
+	ID          int64          `json:"id"`
+	Name        string         `json:"name"`
+	StartDate   time.Time      `json:"start_date"`
+	ElapsedTime int            `json:"elapsed_time"`
+	MovingTime  int            `json:"moving_time"`
+	Distance    float64        `json:"distance"`
+	StartIndex  int            `json:"start_index"`
+	EndIndex    int            `json:"end_index"`
+	KOMRank     *int           `json:"kom_rank"`
+	PRRank      *int           `json:"pr_rank"`
+	Segment     SummarySegment `json:"segment"`
+}
+
+// SummarySegment são os dados resumidos do segmento incluídos em cada esforço
+type SummarySegment struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	Distance      float64   `json:"distance"`
+	AverageGrade  float64   `json:"average_grade"`
+	ClimbCategory int       `json:"climb_category"`
+	StartLatLng   []float64 `json:"start_latlng"`
+	EndLatLng     []float64 `json:"end_latlng"`
+}
+
+// BestEffort é o melhor tempo da atividade numa distância padrão (400m, 1k, 5k...)
+type BestEffort struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	StartDate   time.Time `json:"start_date"`
+	ElapsedTime int       `json:"elapsed_time"`
+	MovingTime  int       `json:"moving_time"`
+	Distance    float64   `json:"distance"`
+	StartIndex  int       `json:"start_index"`
+	EndIndex    int       `json:"end_index"`
+	PRRank      *int      `json:"pr_rank"`
 }
 
 func NewClient(token *oauth2.Token) *Client {
@@ -57,6 +139,35 @@ func NewClient(token *oauth2.Token) *Client {
 	}
 }
 
+// GetAthlete retorna o atleta autenticado. O resultado fica em memória, pois um cliente
+// pertence sempre a um único atleta.
+func (c *Client) GetAthlete() (*Athlete, error) {
+	c.athleteMutex.Lock()
+	defer c.athleteMutex.Unlock()
+
+	if c.athlete != nil {
+		return c.athlete, nil
+	}
+
+	resp, err := c.httpClient.Get(c.baseURL + "/athlete")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao fazer requisição: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("resposta HTTP inválida: %d", resp.StatusCode)
+	}
+
+	var athlete Athlete
+	if err := json.NewDecoder(resp.Body).Decode(&athlete); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar resposta: %w", err)
+	}
+
+	c.athlete = &athlete
+	return c.athlete, nil
+}
+
 // GetActivitiesPage busca uma página específica de atividades
 func (c *Client) GetActivitiesPage(page, perPage int) ([]Activity, error) {
 	// Validação dos parâmetros
@@ -115,6 +226,42 @@ func (c *Client) GetAllActivities(maxPages int) ([]Activity, error) {
 	return allActivities, nil
 }
 
+// GetActivitiesInRange busca as atividades iniciadas entre after e before
+func (c *Client) GetActivitiesInRange(after, before time.Time) ([]Activity, error) {
+	var allActivities []Activity
+	maxPages := 5
+
+	for page := 1; page <= maxPages; page++ {
+		url := fmt.Sprintf("%s/athlete/activities?after=%d&before=%d&page=%d&per_page=30",
+			c.baseURL, after.Unix(), before.Unix(), page)
+
+		resp, err := c.httpClient.Get(url)
+		if err != nil {
+			return allActivities, fmt.Errorf("erro ao fazer requisição: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return allActivities, fmt.Errorf("resposta HTTP inválida: %d", resp.StatusCode)
+		}
+
+		var pageActivities []Activity
+		err = json.NewDecoder(resp.Body).Decode(&pageActivities)
+		resp.Body.Close()
+		if err != nil {
+			return allActivities, fmt.Errorf("erro ao decodificar resposta: %w", err)
+		}
+
+		allActivities = append(allActivities, pageActivities...)
+
+		if len(pageActivities) < 30 {
+			break
+		}
+	}
+
+	return allActivities, nil
+}
+
 // GetActivities - mantida para compatibilidade, busca primeira página
 func (c *Client) GetActivities() ([]Activity, error) {
 	page := 1
@@ -138,7 +285,7 @@ func (c *Client) GetActivities() ([]Activity, error) {
 }
 
 func (c *Client) GetActivityDetail(activityID int64) (*ActivityDetail, error) {
-	url := fmt.Sprintf("%s/activities/%d", c.baseURL, activityID)
+	url := fmt.Sprintf("%s/activities/%d?include_all_efforts=true", c.baseURL, activityID)
 
 	resp, err := c.httpClient.Get(url)
 	if err != nil {
@@ -154,9 +301,10 @@ func (c *Client) GetActivityDetail(activityID int64) (*ActivityDetail, error) {
 	return &detail, nil
 }
 
-func (c *Client) GetActivityStreams(activityID int64) (map[string]ActivityStream, error) {
-	url := fmt.Sprintf("%s/activities/%d/streams?keys=time,latlng,velocity_smooth,altitude&key_by_type=true",
-		c.baseURL, activityID)
+// GetActivityStreams busca e decodifica os streams GPS da atividade na resolução pedida
+func (c *Client) GetActivityStreams(activityID int64, opts StreamOptions) (*StreamSet, error) {
+	url := fmt.Sprintf("%s/activities/%d/streams?keys=%s&key_by_type=true%s",
+		c.baseURL, activityID, strings.Join(streamKeys, ","), opts.query())
 
 	resp, err := c.httpClient.Get(url)
 	if err != nil {
@@ -164,10 +312,9 @@ func (c *Client) GetActivityStreams(activityID int64) (map[string]ActivityStream
 	}
 	defer resp.Body.Close()
 
-	var streams map[string]ActivityStream
-	if err := json.NewDecoder(resp.Body).Decode(&streams); err != nil {
-		return nil, err
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("resposta HTTP inválida: %d", resp.StatusCode)
 	}
 
-	return streams, nil
+	return DecodeStreams(resp.Body)
 }
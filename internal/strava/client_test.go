@@ -0,0 +1,46 @@
+package strava
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetAthleteFetchesOnce(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"id": 42, "firstname": "Ana", "lastname": "Silva"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{httpClient: server.Client(), baseURL: server.URL}
+
+	// Chamadas simultâneas (ex.: status de autenticação e listagem de contas) buscam o atleta uma vez
+	var wg sync.WaitGroup
+	athletes := make([]*Athlete, 8)
+	for i := range athletes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			athlete, err := client.GetAthlete()
+			if err != nil {
+				t.Errorf("GetAthlete: %v", err)
+				return
+			}
+			athletes[i] = athlete
+		}(i)
+	}
+	wg.Wait()
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("esperava 1 requisição ao Strava, foram %d", got)
+	}
+	for i, athlete := range athletes {
+		if athlete != athletes[0] || athlete.ID != 42 {
+			t.Errorf("chamada %d retornou %+v", i, athlete)
+		}
+	}
+}
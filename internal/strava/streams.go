@@ -0,0 +1,299 @@
+package strava
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// Chaves dos streams solicitados ao Strava
+const (
+	StreamTime      = "time"
+	StreamLatLng    = "latlng"
+	StreamVelocity  = "velocity_smooth"
+	StreamAltitude  = "altitude"
+	StreamMoving    = "moving"
+	StreamHeartRate = "heartrate"
+)
+
+// streamKeys são os streams pedidos ao Strava; os ausentes na atividade são simplesmente omitidos
+var streamKeys = []string{StreamTime, StreamLatLng, StreamVelocity, StreamAltitude, StreamMoving, StreamHeartRate}
+
+// Resoluções aceitas pelo endpoint de streams. Sem resolução o Strava devolve todas as amostras
+// originais; as demais limitam a trilha a cerca de 100, 1000 e 10000 pontos.
+const (
+	ResolutionOriginal = ""
+	ResolutionLow      = "low"
+	ResolutionMedium   = "medium"
+	ResolutionHigh     = "high"
+)
+
+// Eixos de amostragem aceitos quando a resolução reduz a trilha
+const (
+	SeriesTypeTime     = "time"
+	SeriesTypeDistance = "distance"
+)
+
+// StreamOptions controla a resolução e o eixo de amostragem dos streams solicitados
+type StreamOptions struct {
+	Resolution string
+	SeriesType string
+}
+
+// DefaultStreamOptions pede a trilha original, amostrada no tempo para sincronizar com o vídeo
+func DefaultStreamOptions() StreamOptions {
+	return StreamOptions{Resolution: ResolutionOriginal, SeriesType: SeriesTypeTime}
+}
+
+// ParseStreamOptions valida a resolução e o eixo configurados, usando o padrão para os inválidos
+func ParseStreamOptions(resolution, seriesType string) (StreamOptions, error) {
+	opts := DefaultStreamOptions()
+	var errs []string
+
+	switch resolution = strings.ToLower(strings.TrimSpace(resolution)); resolution {
+	case ResolutionOriginal, ResolutionLow, ResolutionMedium, ResolutionHigh:
+		opts.Resolution = resolution
+	case "original":
+		opts.Resolution = ResolutionOriginal
+	default:
+		errs = append(errs, fmt.Sprintf("resolução de streams inválida: %q", resolution))
+	}
+
+	switch seriesType = strings.ToLower(strings.TrimSpace(seriesType)); seriesType {
+	case "":
+	case SeriesTypeTime, SeriesTypeDistance:
+		opts.SeriesType = seriesType
+	default:
+		errs = append(errs, fmt.Sprintf("series_type inválido: %q", seriesType))
+	}
+
+	if len(errs) > 0 {
+		return opts, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return opts, nil
+}
+
+// query monta os parâmetros de resolução e eixo da URL de streams
+func (o StreamOptions) query() string {
+	var query string
+	if o.Resolution != ResolutionOriginal {
+		query += "&resolution=" + o.Resolution
+	}
+	if o.SeriesType != "" {
+		query += "&series_type=" + o.SeriesType
+	}
+	return query
+}
+
+// StreamMeta são os metadados que o Strava envia junto com cada stream
+type StreamMeta struct {
+	Type         string `json:"type"`
+	SeriesType   string `json:"series_type"`
+	OriginalSize int    `json:"original_size"`
+	Resolution   string `json:"resolution"`
+}
+
+// IntStream é um stream de inteiros, como os segundos desde o início em "time"
+type IntStream struct {
+	StreamMeta
+	Data []int `json:"data"`
+}
+
+// FloatStream é um stream de valores reais, como velocidade e altitude
+type FloatStream struct {
+	StreamMeta
+	Data []float64 `json:"data"`
+}
+
+// LatLngStream é o stream de coordenadas, com um par [lat, lng] por amostra
+type LatLngStream struct {
+	StreamMeta
+	Data [][2]float64 `json:"data"`
+}
+
+// UnmarshalJSON exige exatamente dois valores por coordenada, pois um array de tamanho fixo
+// aceitaria pares incompletos ou com valores a mais sem reclamar
+func (s *LatLngStream) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		StreamMeta
+		Data [][]float64 `json:"data"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	s.StreamMeta = raw.StreamMeta
+	s.Data = make([][2]float64, len(raw.Data))
+	for i, pair := range raw.Data {
+		if len(pair) != 2 {
+			return fmt.Errorf("coordenada %d com %d valores, esperado 2", i, len(pair))
+		}
+		s.Data[i] = [2]float64{pair[0], pair[1]}
+	}
+	return nil
+}
+
+// BoolStream é um stream booleano, como "moving"
+type BoolStream struct {
+	StreamMeta
+	Data []bool `json:"data"`
+}
+
+// StreamSet reúne os streams de uma atividade já decodificados e validados. Time e LatLng são
+// obrigatórios e têm o mesmo tamanho; os demais ficam nil quando o Strava não os envia e nunca
+// são mais longos que Time, mas podem ser mais curtos (as amostras finais ficam sem o valor)
+type StreamSet struct {
+	Time      *IntStream
+	LatLng    *LatLngStream
+	Velocity  *FloatStream
+	Altitude  *FloatStream
+	Moving    *BoolStream
+	HeartRate *FloatStream
+}
+
+// Len retorna o número de amostras dos streams
+func (s *StreamSet) Len() int {
+	return len(s.Time.Data)
+}
+
+// Resolution retorna a resolução informada pelo Strava para a trilha (low, medium ou high)
+func (s *StreamSet) Resolution() string {
+	return s.LatLng.Resolution
+}
+
+// SeriesType retorna o eixo usado pelo Strava para amostrar os streams (time ou distance)
+func (s *StreamSet) SeriesType() string {
+	return s.LatLng.SeriesType
+}
+
+// DecodeStreams lê a resposta do endpoint de streams. Aceita tanto o formato key_by_type
+// (objeto indexado pelo tipo) quanto a lista de streams, e retorna erro em vez de entrar em
+// pânico quando algum stream vem com formato inesperado ou quando latlng e time divergem
+func DecodeStreams(r io.Reader) (*StreamSet, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler streams: %w", err)
+	}
+
+	raw, err := streamsByType(body)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &StreamSet{}
+	if err := decodeStream(raw, StreamTime, &set.Time); err != nil {
+		return nil, err
+	}
+	if err := decodeStream(raw, StreamLatLng, &set.LatLng); err != nil {
+		return nil, err
+	}
+	if err := decodeStream(raw, StreamVelocity, &set.Velocity); err != nil {
+		return nil, err
+	}
+	if err := decodeStream(raw, StreamAltitude, &set.Altitude); err != nil {
+		return nil, err
+	}
+	if err := decodeStream(raw, StreamMoving, &set.Moving); err != nil {
+		return nil, err
+	}
+	if err := decodeStream(raw, StreamHeartRate, &set.HeartRate); err != nil {
+		return nil, err
+	}
+
+	if err := set.validate(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// streamsByType indexa os streams brutos pelo tipo, independente do formato da resposta
+func streamsByType(body []byte) (map[string]json.RawMessage, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, fmt.Errorf("resposta de streams vazia")
+	}
+
+	if body[0] == '[' {
+		var list []json.RawMessage
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar lista de streams: %w", err)
+		}
+
+		raw := make(map[string]json.RawMessage, len(list))
+		for i, item := range list {
+			var meta StreamMeta
+			if err := json.Unmarshal(item, &meta); err != nil {
+				return nil, fmt.Errorf("stream %d inválido: %w", i, err)
+			}
+			raw[meta.Type] = item
+		}
+		return raw, nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar streams: %w", err)
+	}
+	return raw, nil
+}
+
+// decodeStream decodifica o stream key em target, deixando-o nil quando ausente ou null
+func decodeStream[T any](raw map[string]json.RawMessage, key string, target **T) error {
+	data, ok := raw[key]
+	if !ok || bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	var stream T
+	if err := json.Unmarshal(data, &stream); err != nil {
+		return fmt.Errorf("stream %s inválido: %w", key, err)
+	}
+	*target = &stream
+	return nil
+}
+
+// validate garante a presença dos streams obrigatórios, com o mesmo número de amostras. Os
+// opcionais mais curtos são aceitos, como em gps.StreamData, e os mais longos são cortados no
+// tamanho de time, pois as amostras excedentes não têm instante
+func (s *StreamSet) validate() error {
+	if s.Time == nil || len(s.Time.Data) == 0 {
+		return fmt.Errorf("stream %s ausente ou vazio", StreamTime)
+	}
+	if s.LatLng == nil || len(s.LatLng.Data) == 0 {
+		return fmt.Errorf("stream %s ausente ou vazio", StreamLatLng)
+	}
+
+	expected := len(s.Time.Data)
+	if len(s.LatLng.Data) != expected {
+		return fmt.Errorf("stream %s com %d amostras, esperado %d", StreamLatLng, len(s.LatLng.Data), expected)
+	}
+
+	if s.Velocity != nil {
+		s.Velocity.Data = fitOptionalStream(StreamVelocity, s.Velocity.Data, expected)
+	}
+	if s.Altitude != nil {
+		s.Altitude.Data = fitOptionalStream(StreamAltitude, s.Altitude.Data, expected)
+	}
+	if s.Moving != nil {
+		s.Moving.Data = fitOptionalStream(StreamMoving, s.Moving.Data, expected)
+	}
+	if s.HeartRate != nil {
+		s.HeartRate.Data = fitOptionalStream(StreamHeartRate, s.HeartRate.Data, expected)
+	}
+	return nil
+}
+
+// fitOptionalStream corta um stream opcional mais longo que time e avisa quando é mais curto
+func fitOptionalStream[T any](key string, data []T, expected int) []T {
+	switch {
+	case len(data) > expected:
+		log.Printf("⚠️ Stream %s com %d amostras, esperado %d: excedentes descartadas", key, len(data), expected)
+		return data[:expected]
+	case len(data) < expected:
+		log.Printf("⚠️ Stream %s com %d amostras, esperado %d: as últimas ficam sem o valor", key, len(data), expected)
+	}
+	return data
+}
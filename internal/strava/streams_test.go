@@ -0,0 +1,120 @@
+package strava
+
+import (
+	"io"
+	"log"
+	"strings"
+	"testing"
+)
+
+// quietLogs silencia os avisos de streams durante o teste
+func quietLogs(tb testing.TB) {
+	previous := log.Writer()
+	log.SetOutput(io.Discard)
+	tb.Cleanup(func() { log.SetOutput(previous) })
+}
+
+const keyByTypeStreams = `{
+	"time": {"data": [0, 1, 2], "series_type": "time", "original_size": 3, "resolution": "high"},
+	"latlng": {"data": [[-23.55, -46.63], [-23.5501, -46.6301], [-23.5502, -46.6302]], "series_type": "time", "original_size": 3, "resolution": "high"},
+	"velocity_smooth": {"data": [5.1, 5.3, 5.2]},
+	"moving": {"data": [true, true, false]}
+}`
+
+const listStreams = `[
+	{"type": "time", "data": [0, 1, 2]},
+	{"type": "latlng", "data": [[-23.55, -46.63], [-23.5501, -46.6301], [-23.5502, -46.6302]]},
+	{"type": "altitude", "data": [700, 701, 702]}
+]`
+
+func TestDecodeStreamsFormats(t *testing.T) {
+	for name, body := range map[string]string{"key_by_type": keyByTypeStreams, "lista": listStreams} {
+		t.Run(name, func(t *testing.T) {
+			set, err := DecodeStreams(strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("DecodeStreams: %v", err)
+			}
+			if set.Len() != 3 || len(set.LatLng.Data) != 3 {
+				t.Fatalf("esperava 3 amostras, obteve time=%d latlng=%d", set.Len(), len(set.LatLng.Data))
+			}
+			if set.HeartRate != nil {
+				t.Error("stream ausente deveria ficar nil")
+			}
+		})
+	}
+}
+
+func TestDecodeStreamsOptionalLengths(t *testing.T) {
+	quietLogs(t)
+
+	shorter := `{"time": {"data": [0, 1, 2]}, "latlng": {"data": [[1, 1], [1, 2], [1, 3]]}, "heartrate": {"data": [120]}}`
+	set, err := DecodeStreams(strings.NewReader(shorter))
+	if err != nil {
+		t.Fatalf("stream opcional mais curto deveria ser aceito: %v", err)
+	}
+	if len(set.HeartRate.Data) != 1 {
+		t.Errorf("o stream mais curto deveria ser mantido como veio, obteve %d amostras", len(set.HeartRate.Data))
+	}
+
+	longer := `{"time": {"data": [0, 1]}, "latlng": {"data": [[1, 1], [1, 2]]}, "altitude": {"data": [700, 701, 702, 703]}}`
+	set, err = DecodeStreams(strings.NewReader(longer))
+	if err != nil {
+		t.Fatalf("stream opcional mais longo deveria ser aceito: %v", err)
+	}
+	if len(set.Altitude.Data) != 2 {
+		t.Errorf("o stream mais longo deveria ser cortado em 2 amostras, obteve %d", len(set.Altitude.Data))
+	}
+}
+
+func TestDecodeStreamsRejectsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"vazio":             ``,
+		"sem time":          `{"latlng": {"data": [[1, 1]]}}`,
+		"sem latlng":        `{"time": {"data": [0]}}`,
+		"latlng divergente": `{"time": {"data": [0, 1]}, "latlng": {"data": [[1, 1]]}}`,
+		"par incompleto":    `{"time": {"data": [0]}, "latlng": {"data": [[1]]}}`,
+		"tipo errado":       `{"time": {"data": ["a"]}, "latlng": {"data": [[1, 1]]}}`,
+		"lista inválida":    `[{"type": 1}]`,
+	}
+
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeStreams(strings.NewReader(body)); err == nil {
+				t.Errorf("DecodeStreams(%q) deveria falhar", body)
+			}
+		})
+	}
+}
+
+func FuzzDecodeStreams(f *testing.F) {
+	quietLogs(f)
+	f.Add(keyByTypeStreams)
+	f.Add(listStreams)
+	f.Add(`{"time": {"data": [0, 1]}, "latlng": {"data": [[1, 1], [1, 2]]}, "moving": {"data": [true, false, true]}}`)
+	f.Add(`{"time": null, "latlng": {"data": []}}`)
+	f.Add(`[]`)
+
+	f.Fuzz(func(t *testing.T, body string) {
+		set, err := DecodeStreams(strings.NewReader(body))
+		if err != nil {
+			return
+		}
+
+		n := set.Len()
+		if n == 0 || len(set.LatLng.Data) != n {
+			t.Fatalf("streams obrigatórios inconsistentes: time=%d latlng=%d", n, len(set.LatLng.Data))
+		}
+		if set.Velocity != nil && len(set.Velocity.Data) > n {
+			t.Fatalf("velocity_smooth mais longo que time: %d > %d", len(set.Velocity.Data), n)
+		}
+		if set.Altitude != nil && len(set.Altitude.Data) > n {
+			t.Fatalf("altitude mais longo que time: %d > %d", len(set.Altitude.Data), n)
+		}
+		if set.Moving != nil && len(set.Moving.Data) > n {
+			t.Fatalf("moving mais longo que time: %d > %d", len(set.Moving.Data), n)
+		}
+		if set.HeartRate != nil && len(set.HeartRate.Data) > n {
+			t.Fatalf("heartrate mais longo que time: %d > %d", len(set.HeartRate.Data), n)
+		}
+	})
+}
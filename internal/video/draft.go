@@ -0,0 +1,91 @@
+package video
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DraftSettings configura a renderização rápida de rascunho: vídeo reduzido, menos quadros
+// por segundo, preset ultrafast e apenas um trecho do vídeo
+type DraftSettings struct {
+	Height    int           // Lado menor do vídeo de saída em pixels (480 = 480p)
+	FrameRate float64       // Quadros por segundo do rascunho
+	Start     time.Duration // Início do trecho, em tempo do vídeo
+	Duration  time.Duration // Duração do trecho; zero vai até o fim do vídeo
+}
+
+// DefaultDraftSettings retorna 480p a 15 fps com os primeiros 30 segundos do vídeo
+func DefaultDraftSettings() DraftSettings {
+	return DraftSettings{
+		Height:    480,
+		FrameRate: 15,
+		Duration:  30 * time.Second,
+	}
+}
+
+// ParseDraftSettings interpreta a altura ("480" ou "480p"), a taxa de quadros e a duração padrão
+// no formato de time.ParseDuration. Valores vazios mantêm o padrão.
+func ParseDraftSettings(height, frameRate, duration string) (DraftSettings, error) {
+	settings := DefaultDraftSettings()
+
+	if height = strings.TrimSuffix(strings.TrimSpace(height), "p"); height != "" {
+		h, err := strconv.Atoi(height)
+		if err != nil || h < 144 {
+			return DefaultDraftSettings(), fmt.Errorf("altura de rascunho inválida '%s'", height)
+		}
+		settings.Height = h
+	}
+
+	if frameRate = strings.TrimSpace(frameRate); frameRate != "" {
+		fps, err := strconv.ParseFloat(frameRate, 64)
+		if err != nil || fps <= 0 {
+			return DefaultDraftSettings(), fmt.Errorf("taxa de quadros de rascunho inválida '%s'", frameRate)
+		}
+		settings.FrameRate = fps
+	}
+
+	if duration = strings.TrimSpace(duration); duration != "" {
+		d, err := time.ParseDuration(duration)
+		if err != nil || d <= 0 {
+			return DefaultDraftSettings(), fmt.Errorf("duração de rascunho inválida '%s'", duration)
+		}
+		settings.Duration = d
+	}
+
+	return settings, nil
+}
+
+// Window ajusta o trecho do rascunho à duração do vídeo
+func (d DraftSettings) Window(videoDuration time.Duration) (time.Duration, time.Duration, error) {
+	start := max(d.Start, 0)
+	if start >= videoDuration {
+		return 0, 0, fmt.Errorf("início do rascunho (%.1fs) após o fim do vídeo (%.1fs)",
+			start.Seconds(), videoDuration.Seconds())
+	}
+
+	duration := videoDuration - start
+	if d.Duration > 0 && d.Duration < duration {
+		duration = d.Duration
+	}
+	return start, duration, nil
+}
+
+// FrameRateFor retorna a taxa de quadros do rascunho, sem ultrapassar a do vídeo original
+func (d DraftSettings) FrameRateFor(videoFrameRate float64) float64 {
+	if d.FrameRate <= 0 || (videoFrameRate > 0 && videoFrameRate < d.FrameRate) {
+		return videoFrameRate
+	}
+	return d.FrameRate
+}
+
+// Scale retorna o fator de redução para que o lado menor do vídeo fique com Height pixels;
+// vídeos já menores não são ampliados
+func (d DraftSettings) Scale(width, height int) float64 {
+	shortSide := min(width, height)
+	if d.Height <= 0 || shortSide <= d.Height {
+		return 1
+	}
+	return float64(d.Height) / float64(shortSide)
+}
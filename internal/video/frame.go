@@ -0,0 +1,118 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os/exec"
+	"strings"
+	"time"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// PreviewMaxSize é o maior lado, em pixels, da imagem de pré-visualização. Um quadro 4K em PNG
+// passa de 10 MB em base64; reduzido e em JPEG fica em torno de 250 KB (ver BenchmarkPreview4K).
+const PreviewMaxSize = 1280
+
+// previewJPEGQuality equilibra nitidez do texto do overlay e tamanho da pré-visualização
+const previewJPEGQuality = 85
+
+// ExtractFrame decodifica um único quadro do vídeo no instante indicado. O -ss antes da
+// entrada busca pelo keyframe anterior e decodifica até o quadro exato, sem percorrer o vídeo.
+func ExtractFrame(ctx context.Context, videoPath string, at time.Duration) (image.Image, error) {
+	if at < 0 {
+		at = 0
+	}
+
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-v", "error",
+		"-ss", fmt.Sprintf("%.3f", at.Seconds()),
+		"-i", videoPath,
+		"-frames:v", "1",
+		"-f", "image2pipe",
+		"-c:v", "png",
+		"pipe:1",
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg falhou ao extrair quadro: %w. Output: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("nenhum quadro em %.3fs (após o fim do vídeo?)", at.Seconds())
+	}
+
+	frame, err := png.Decode(&stdout)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao decodificar quadro: %w", err)
+	}
+	return frame, nil
+}
+
+// CompositeOverlay sobrepõe o overlay ao quadro na mesma posição usada pela renderização
+// (ver calculateOverlayCoordinates)
+func CompositeOverlay(frame, overlay image.Image, position string) *image.RGBA {
+	out := image.NewRGBA(image.Rect(0, 0, frame.Bounds().Dx(), frame.Bounds().Dy()))
+	draw.Draw(out, out.Bounds(), frame, frame.Bounds().Min, draw.Src)
+
+	origin := overlayOrigin(position, out.Bounds().Size(), overlay.Bounds().Size())
+	target := image.Rectangle{Min: origin, Max: origin.Add(overlay.Bounds().Size())}
+	draw.Draw(out, target, overlay, overlay.Bounds().Min, draw.Over)
+	return out
+}
+
+// overlayOrigin calcula o canto superior esquerdo do overlay no quadro
+func overlayOrigin(position string, frame, overlay image.Point) image.Point {
+	margin := overlayMarginFor(frame.X, frame.Y)
+	left, top := margin, margin
+	right := frame.X - overlay.X - margin
+	bottom := frame.Y - overlay.Y - margin
+
+	switch position {
+	case "top-left":
+		return image.Pt(left, top)
+	case "top-right":
+		return image.Pt(right, top)
+	case "bottom-right":
+		return image.Pt(right, bottom)
+	default:
+		return image.Pt(left, bottom)
+	}
+}
+
+// EncodePreview reduz a imagem para caber em PreviewMaxSize × PreviewMaxSize, mantendo a
+// proporção, e a codifica em JPEG. Imagens menores não são ampliadas.
+func EncodePreview(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, fitWithin(img, PreviewMaxSize), &jpeg.Options{Quality: previewJPEGQuality}); err != nil {
+		return nil, fmt.Errorf("erro ao codificar pré-visualização: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fitWithin reduz a imagem para que o maior lado tenha no máximo maxSize pixels
+func fitWithin(img image.Image, maxSize int) image.Image {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width <= maxSize && height <= maxSize {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*maxSize/width)
+		width = maxSize
+	} else {
+		width = max(1, width*maxSize/height)
+		height = maxSize
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.ApproxBiLinear.Scale(scaled, scaled.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return scaled
+}
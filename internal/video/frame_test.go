@@ -0,0 +1,127 @@
+package video
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"testing"
+)
+
+// syntheticFrame gera um quadro com gradiente e ruído, para que o tamanho codificado se
+// aproxime do de um quadro real
+func syntheticFrame(width, height int) *image.RGBA {
+	rng := rand.New(rand.NewSource(1))
+	frame := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			noise := uint8(rng.Intn(24))
+			frame.SetRGBA(x, y, color.RGBA{
+				R: uint8(x*255/width) + noise,
+				G: uint8(y*255/height) + noise,
+				B: 96 + noise,
+				A: 255,
+			})
+		}
+	}
+	return frame
+}
+
+// syntheticOverlay gera um overlay semitransparente do tamanho indicado
+func syntheticOverlay(size int) *image.RGBA {
+	overlay := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			overlay.SetRGBA(x, y, color.RGBA{R: 200, G: 40, B: 40, A: 160})
+		}
+	}
+	return overlay
+}
+
+func TestEncodePreview(t *testing.T) {
+	tests := []struct {
+		name                  string
+		width, height         int
+		wantWidth, wantHeight int
+	}{
+		{"4K", 3840, 2160, 1280, 720},
+		{"1080p", 1920, 1080, 1280, 720},
+		{"retrato 1080x1920", 1080, 1920, 720, 1280},
+		{"menor que o limite", 640, 360, 640, 360},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodePreview(syntheticFrame(tt.width, tt.height))
+			if err != nil {
+				t.Fatalf("EncodePreview: %v", err)
+			}
+
+			config, format, err := image.DecodeConfig(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("pré-visualização inválida: %v", err)
+			}
+			if format != "jpeg" {
+				t.Errorf("formato: esperado jpeg, obtido %s", format)
+			}
+			if config.Width != tt.wantWidth || config.Height != tt.wantHeight {
+				t.Errorf("dimensões: esperado %dx%d, obtido %dx%d", tt.wantWidth, tt.wantHeight, config.Width, config.Height)
+			}
+		})
+	}
+}
+
+func TestEncodePreviewKeepsOverlayVisible(t *testing.T) {
+	frame := image.NewRGBA(image.Rect(0, 0, 3840, 2160))
+	composite := CompositeOverlay(frame, syntheticOverlay(680), "top-left")
+
+	data, err := EncodePreview(composite)
+	if err != nil {
+		t.Fatalf("EncodePreview: %v", err)
+	}
+	preview, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("pré-visualização inválida: %v", err)
+	}
+
+	// O overlay começa na margem do quadro 4K; na imagem reduzida (1/3) deve estar no mesmo lugar
+	margin := overlayMarginFor(3840, 2160)
+	center := image.Pt((margin+340)/3, (margin+340)/3)
+	r, g, _, _ := preview.At(center.X, center.Y).RGBA()
+	if r>>8 < 80 || g>>8 > 60 {
+		t.Errorf("overlay ausente na pré-visualização reduzida em %v: r=%d g=%d", center, r>>8, g>>8)
+	}
+}
+
+// BenchmarkPreview4K mede composição e codificação de um quadro 4K (sem a extração pelo
+// ffmpeg), comparando o PNG em resolução original com a pré-visualização reduzida
+func BenchmarkPreview4K(b *testing.B) {
+	frame := syntheticFrame(3840, 2160)
+	overlay := syntheticOverlay(680)
+
+	b.Run("png-original", func(b *testing.B) {
+		var size int
+		for i := 0; i < b.N; i++ {
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, CompositeOverlay(frame, overlay, "bottom-left")); err != nil {
+				b.Fatal(err)
+			}
+			size = buf.Len()
+		}
+		b.ReportMetric(float64(size)/1024, "KB")
+	})
+
+	b.Run("jpeg-reduzido", func(b *testing.B) {
+		var size int
+		for i := 0; i < b.N; i++ {
+			data, err := EncodePreview(CompositeOverlay(frame, overlay, "bottom-left"))
+			if err != nil {
+				b.Fatal(err)
+			}
+			size = len(data)
+		}
+		b.ReportMetric(float64(size)/1024, "KB")
+	})
+}
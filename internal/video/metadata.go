@@ -3,6 +3,7 @@ package video
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -12,9 +13,11 @@ import (
 type VideoMetadata struct {
 	CreationTime time.Time
 	Duration     time.Duration
-	Width        int
+	Width        int // Dimensões de exibição, já com a rotação aplicada (como o ffmpeg decodifica)
 	Height       int
+	Rotation     int // Rotação em graus gravada pelo celular (0, 90, 180 ou 270)
 	FrameRate    float64
+	HasAudio     bool
 }
 
 type FFProbeOutput struct {
@@ -23,9 +26,14 @@ type FFProbeOutput struct {
 		Tags     map[string]string `json:"tags"`
 	} `json:"format"`
 	Streams []struct {
-		Width      int    `json:"width"`
-		Height     int    `json:"height"`
-		RFrameRate string `json:"r_frame_rate"`
+		CodecType  string            `json:"codec_type"`
+		Width      int               `json:"width"`
+		Height     int               `json:"height"`
+		RFrameRate string            `json:"r_frame_rate"`
+		Tags       map[string]string `json:"tags"`
+		SideData   []struct {
+			Rotation float64 `json:"rotation"`
+		} `json:"side_data_list"`
 	} `json:"streams"`
 }
 
@@ -78,12 +86,31 @@ func GetVideoMetadata(filePath string) (*VideoMetadata, error) {
 		}
 	}
 
+	for _, stream := range probe.Streams {
+		if stream.CodecType == "audio" {
+			metadata.HasAudio = true
+		}
+	}
+
 	if len(probe.Streams) > 0 {
 		for _, stream := range probe.Streams {
 			if stream.Width > 0 && stream.Height > 0 {
 				metadata.Width = stream.Width
 				metadata.Height = stream.Height
 
+				// Celulares gravam vídeos verticais como horizontais com uma matriz de rotação,
+				// na tag "rotate" (ffprobe antigo) ou no side data (ffprobe 5+)
+				rotation, _ := strconv.ParseFloat(stream.Tags["rotate"], 64)
+				for _, side := range stream.SideData {
+					if side.Rotation != 0 {
+						rotation = side.Rotation
+					}
+				}
+				metadata.Rotation = ((int(math.Round(rotation)) % 360) + 360) % 360
+				if metadata.Rotation == 90 || metadata.Rotation == 270 {
+					metadata.Width, metadata.Height = metadata.Height, metadata.Width
+				}
+
 				if stream.RFrameRate != "" {
 					parts := strings.Split(stream.RFrameRate, "/")
 					if len(parts) == 2 {
@@ -0,0 +1,360 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// TimedSample é uma amostra de metadados temporizados com sua duração na linha do tempo
+type TimedSample struct {
+	Duration time.Duration
+	Data     []byte
+}
+
+// metadataTimescale é a escala de tempo (unidades por segundo) da faixa de metadados
+const metadataTimescale = 1000
+
+// mp4Box localiza uma caixa de primeiro nível do arquivo
+type mp4Box struct {
+	boxType string
+	offset  int64
+	size    int64
+}
+
+// AddTimedMetadataTrack acrescenta a um MP4 já codificado uma faixa de metadados temporizados
+// ('meta') com amostras no formato sampleFormat (ex.: "gpmd" para GPMF). As amostras vão num
+// novo mdat no fim do arquivo, seguido de uma cópia do moov com a faixa registrada; nenhum
+// offset das faixas existentes muda. Só depois que o novo moov está gravado em disco o antigo
+// vira uma caixa 'free', de modo que uma falha no meio do caminho deixa o vídeo original
+// reproduzível.
+func AddTimedMetadataTrack(path, sampleFormat, handlerName string, samples []TimedSample) error {
+	if len(samples) == 0 {
+		return fmt.Errorf("nenhuma amostra de metadados")
+	}
+	if len(sampleFormat) != 4 {
+		return fmt.Errorf("formato de amostra inválido: %q", sampleFormat)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir vídeo: %w", err)
+	}
+	defer file.Close()
+
+	boxes, err := readTopLevelBoxes(file)
+	if err != nil {
+		return err
+	}
+	moov, err := findMoov(boxes)
+	if err != nil {
+		return err
+	}
+	last := boxes[len(boxes)-1]
+	end := last.offset + last.size
+
+	moovData := make([]byte, moov.size)
+	if _, err := file.ReadAt(moovData, moov.offset); err != nil {
+		return fmt.Errorf("erro ao ler moov: %w", err)
+	}
+
+	movieTimescale, trackID, err := movieHeaderInfo(moovData)
+	if err != nil {
+		return err
+	}
+
+	// Novo mdat no fim do arquivo, seguido do moov com a faixa adicionada
+	var mdat bytes.Buffer
+	var payloadSize int64
+	for _, sample := range samples {
+		payloadSize += int64(len(sample.Data))
+	}
+	writeBoxHeader(&mdat, "mdat", 8+payloadSize)
+	offsets := make([]uint64, len(samples))
+	position := uint64(end) + uint64(mdat.Len())
+	for i, sample := range samples {
+		offsets[i] = position
+		position += uint64(len(sample.Data))
+		mdat.Write(sample.Data)
+	}
+
+	track := buildMetadataTrack(trackID, movieTimescale, sampleFormat, handlerName, samples, offsets)
+	newMoov := appendToMoov(moovData, track, trackID+1)
+
+	// Enquanto o moov antigo existir ele é o primeiro encontrado pelos players, então o arquivo
+	// continua válido mesmo se a gravação abaixo for interrompida
+	if err := appendBoxes(file, end, mdat.Bytes(), newMoov); err != nil {
+		if truncErr := file.Truncate(end); truncErr != nil {
+			return fmt.Errorf("%w (e não foi possível restaurar o tamanho original: %v)", err, truncErr)
+		}
+		return err
+	}
+
+	// Ponto de confirmação: um único cabeçalho troca o moov antigo por uma caixa 'free'
+	if _, err := file.WriteAt([]byte("free"), moov.offset+4); err != nil {
+		return fmt.Errorf("erro ao substituir o moov antigo: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("erro ao gravar o vídeo: %w", err)
+	}
+	return nil
+}
+
+// findMoov retorna a única caixa moov do arquivo
+func findMoov(boxes []mp4Box) (mp4Box, error) {
+	var moov []mp4Box
+	for _, box := range boxes {
+		if box.boxType == "moov" {
+			moov = append(moov, box)
+		}
+	}
+	if len(moov) != 1 {
+		return mp4Box{}, fmt.Errorf("esperado um moov no arquivo, encontrados %d; faixa de metadados não adicionada", len(moov))
+	}
+	return moov[0], nil
+}
+
+// appendBoxes grava as caixas a partir de offset e força a gravação em disco
+func appendBoxes(file *os.File, offset int64, boxes ...[]byte) error {
+	for _, box := range boxes {
+		if _, err := file.WriteAt(box, offset); err != nil {
+			return fmt.Errorf("erro ao gravar a faixa de metadados: %w", err)
+		}
+		offset += int64(len(box))
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("erro ao gravar a faixa de metadados: %w", err)
+	}
+	return nil
+}
+
+// readTopLevelBoxes lista as caixas de primeiro nível, tratando tamanhos de 64 bits e a caixa
+// que vai até o fim do arquivo (tamanho 0)
+func readTopLevelBoxes(file *os.File) ([]mp4Box, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var boxes []mp4Box
+	header := make([]byte, 16)
+	for offset := int64(0); offset < info.Size(); {
+		if _, err := file.ReadAt(header[:8], offset); err != nil {
+			return nil, fmt.Errorf("cabeçalho MP4 inválido em %d: %w", offset, err)
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		boxType := string(header[4:8])
+		switch size {
+		case 0:
+			size = info.Size() - offset
+		case 1:
+			if _, err := file.ReadAt(header[8:16], offset+8); err != nil {
+				return nil, fmt.Errorf("cabeçalho MP4 inválido em %d: %w", offset, err)
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+		}
+		if size < 8 || offset+size > info.Size() {
+			return nil, fmt.Errorf("caixa MP4 %q com tamanho inválido em %d", boxType, offset)
+		}
+
+		boxes = append(boxes, mp4Box{boxType: boxType, offset: offset, size: size})
+		offset += size
+	}
+
+	if len(boxes) == 0 {
+		return nil, fmt.Errorf("arquivo MP4 vazio")
+	}
+	return boxes, nil
+}
+
+// movieHeaderInfo lê a escala de tempo e o próximo ID de faixa do mvhd
+func movieHeaderInfo(moov []byte) (uint32, uint32, error) {
+	mvhd, ok := findChildBox(moov[8:], "mvhd")
+	if !ok || len(mvhd) < 12 {
+		return 0, 0, fmt.Errorf("mvhd não encontrado")
+	}
+
+	body := mvhd[8:]
+	// Versão 1 usa 64 bits para datas e duração
+	timescaleAt, nextTrackAt := 12, 96
+	if body[0] == 1 {
+		timescaleAt, nextTrackAt = 20, 108
+	}
+	if len(body) < nextTrackAt+4 {
+		return 0, 0, fmt.Errorf("mvhd truncado")
+	}
+
+	return binary.BigEndian.Uint32(body[timescaleAt:]), binary.BigEndian.Uint32(body[nextTrackAt:]), nil
+}
+
+// findChildBox retorna a primeira caixa do tipo indicado entre as caixas de data
+func findChildBox(data []byte, boxType string) ([]byte, bool) {
+	for offset := 0; offset+8 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[offset:]))
+		if size < 8 || offset+size > len(data) {
+			return nil, false
+		}
+		if string(data[offset+4:offset+8]) == boxType {
+			return data[offset : offset+size], true
+		}
+		offset += size
+	}
+	return nil, false
+}
+
+// appendToMoov acrescenta o trak ao moov e atualiza o next_track_ID do mvhd
+func appendToMoov(moov, track []byte, nextTrackID uint32) []byte {
+	updated := make([]byte, 0, len(moov)+len(track))
+	updated = append(updated, moov...)
+
+	if mvhd, ok := findChildBox(updated[8:], "mvhd"); ok {
+		body := mvhd[8:]
+		nextTrackAt := 96
+		if body[0] == 1 {
+			nextTrackAt = 108
+		}
+		binary.BigEndian.PutUint32(body[nextTrackAt:], nextTrackID)
+	}
+
+	updated = append(updated, track...)
+	binary.BigEndian.PutUint32(updated[:4], uint32(len(updated)))
+	return updated
+}
+
+// buildMetadataTrack monta o trak da faixa de metadados: uma amostra por chunk, com offsets de
+// 64 bits (co64) para funcionar também em arquivos acima de 4 GB
+func buildMetadataTrack(trackID, movieTimescale uint32, sampleFormat, handlerName string, samples []TimedSample, offsets []uint64) []byte {
+	var mediaDuration uint64
+	durations := make([]uint32, len(samples))
+	for i, sample := range samples {
+		durations[i] = uint32(sample.Duration.Milliseconds())
+		mediaDuration += uint64(durations[i])
+	}
+	movieDuration := mediaDuration * uint64(movieTimescale) / metadataTimescale
+
+	identity := []uint32{0x00010000, 0, 0, 0, 0x00010000, 0, 0, 0, 0x40000000}
+
+	tkhd := fullBox("tkhd", 0, 0x000001, func(b *bytes.Buffer) {
+		writeUint32s(b, 0, 0, trackID, 0, uint32(movieDuration))
+		b.Write(make([]byte, 8))     // reservado
+		writeUint16s(b, 0, 0, 0, 0)  // layer, alternate_group, volume, reservado
+		writeUint32s(b, identity...) // matriz
+		writeUint32s(b, 0, 0)        // largura e altura
+	})
+
+	mdhd := fullBox("mdhd", 0, 0, func(b *bytes.Buffer) {
+		writeUint32s(b, 0, 0, metadataTimescale, uint32(mediaDuration))
+		writeUint16s(b, 0x55C4, 0) // idioma "und"
+	})
+
+	hdlr := fullBox("hdlr", 0, 0, func(b *bytes.Buffer) {
+		writeUint32s(b, 0)
+		b.WriteString("meta")
+		b.Write(make([]byte, 12))
+		b.WriteString(handlerName)
+		b.WriteByte(0)
+	})
+
+	nmhd := fullBox("nmhd", 0, 0, func(b *bytes.Buffer) {})
+
+	dinf := box("dinf", fullBox("dref", 0, 0, func(b *bytes.Buffer) {
+		writeUint32s(b, 1)
+		b.Write(fullBox("url ", 0, 0x000001, func(b *bytes.Buffer) {})) // dados no próprio arquivo
+	}))
+
+	stsd := fullBox("stsd", 0, 0, func(b *bytes.Buffer) {
+		writeUint32s(b, 1)
+		entry := make([]byte, 8) // 6 bytes reservados + data_reference_index
+		binary.BigEndian.PutUint16(entry[6:], 1)
+		b.Write(box(sampleFormat, append(entry, 0, 0, 0, 0)))
+	})
+
+	stts := fullBox("stts", 0, 0, func(b *bytes.Buffer) {
+		type run struct{ count, delta uint32 }
+		var runs []run
+		for _, duration := range durations {
+			if n := len(runs); n > 0 && runs[n-1].delta == duration {
+				runs[n-1].count++
+				continue
+			}
+			runs = append(runs, run{1, duration})
+		}
+		writeUint32s(b, uint32(len(runs)))
+		for _, r := range runs {
+			writeUint32s(b, r.count, r.delta)
+		}
+	})
+
+	stsc := fullBox("stsc", 0, 0, func(b *bytes.Buffer) {
+		writeUint32s(b, 1, 1, 1, 1) // uma entrada: a partir do chunk 1, 1 amostra por chunk
+	})
+
+	stsz := fullBox("stsz", 0, 0, func(b *bytes.Buffer) {
+		writeUint32s(b, 0, uint32(len(samples)))
+		for _, sample := range samples {
+			writeUint32s(b, uint32(len(sample.Data)))
+		}
+	})
+
+	co64 := fullBox("co64", 0, 0, func(b *bytes.Buffer) {
+		writeUint32s(b, uint32(len(offsets)))
+		for _, offset := range offsets {
+			binary.Write(b, binary.BigEndian, offset)
+		}
+	})
+
+	stbl := box("stbl", stsd, stts, stsc, stsz, co64)
+	minf := box("minf", nmhd, dinf, stbl)
+	mdia := box("mdia", mdhd, hdlr, minf)
+	return box("trak", tkhd, mdia)
+}
+
+// box monta uma caixa com os filhos informados
+func box(boxType string, children ...[]byte) []byte {
+	var b bytes.Buffer
+	size := int64(8)
+	for _, child := range children {
+		size += int64(len(child))
+	}
+	writeBoxHeader(&b, boxType, size)
+	for _, child := range children {
+		b.Write(child)
+	}
+	return b.Bytes()
+}
+
+// fullBox monta uma caixa com versão e flags seguida do conteúdo escrito por body
+func fullBox(boxType string, version byte, flags uint32, body func(*bytes.Buffer)) []byte {
+	var content bytes.Buffer
+	writeUint32s(&content, uint32(version)<<24|flags&0xFFFFFF)
+	body(&content)
+	return box(boxType, content.Bytes())
+}
+
+// writeBoxHeader grava tamanho e tipo, usando o tamanho estendido de 64 bits quando necessário
+func writeBoxHeader(w io.Writer, boxType string, size int64) {
+	if size > 0xFFFFFFFF {
+		binary.Write(w, binary.BigEndian, uint32(1))
+		io.WriteString(w, boxType)
+		binary.Write(w, binary.BigEndian, uint64(size+8))
+		return
+	}
+	binary.Write(w, binary.BigEndian, uint32(size))
+	io.WriteString(w, boxType)
+}
+
+func writeUint32s(b *bytes.Buffer, values ...uint32) {
+	for _, value := range values {
+		binary.Write(b, binary.BigEndian, value)
+	}
+}
+
+func writeUint16s(b *bytes.Buffer, values ...uint16) {
+	for _, value := range values {
+		binary.Write(b, binary.BigEndian, value)
+	}
+}
@@ -0,0 +1,197 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// minimalMP4 monta um MP4 com ftyp, mdat e um moov contendo o mvhd e uma faixa de vídeo vazia
+func minimalMP4(t *testing.T) string {
+	t.Helper()
+
+	ftyp := box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41"))
+	mdat := box("mdat", bytes.Repeat([]byte{0xAB}, 256))
+	mvhd := fullBox("mvhd", 0, 0, func(b *bytes.Buffer) {
+		writeUint32s(b, 0, 0, 1000, 5000, 0x00010000)
+		writeUint16s(b, 0x0100)
+		b.Write(make([]byte, 10))
+		writeUint32s(b, 0x00010000, 0, 0, 0, 0x00010000, 0, 0, 0, 0x40000000)
+		b.Write(make([]byte, 24))
+		writeUint32s(b, 2)
+	})
+	trak := box("trak", fullBox("tkhd", 0, 3, func(b *bytes.Buffer) {
+		writeUint32s(b, 0, 0, 1, 0, 5000)
+	}))
+	moov := box("moov", mvhd, trak)
+
+	path := filepath.Join(t.TempDir(), "video.mp4")
+	data := append(append(append([]byte{}, ftyp...), mdat...), moov...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("erro ao criar MP4: %v", err)
+	}
+	return path
+}
+
+// topLevelBoxes lista as caixas de primeiro nível do arquivo
+func topLevelBoxes(t *testing.T, path string) []mp4Box {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("erro ao abrir MP4: %v", err)
+	}
+	defer file.Close()
+
+	boxes, err := readTopLevelBoxes(file)
+	if err != nil {
+		t.Fatalf("readTopLevelBoxes: %v", err)
+	}
+	return boxes
+}
+
+// childBoxes lista as caixas filhas contidas em data
+func childBoxes(data []byte) [][]byte {
+	var children [][]byte
+	for offset := 0; offset+8 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[offset:]))
+		if size < 8 || offset+size > len(data) {
+			break
+		}
+		children = append(children, data[offset:offset+size])
+		offset += size
+	}
+	return children
+}
+
+func boxTypes(boxes []mp4Box) []string {
+	types := make([]string, len(boxes))
+	for i, b := range boxes {
+		types[i] = b.boxType
+	}
+	return types
+}
+
+func TestAddTimedMetadataTrack(t *testing.T) {
+	path := minimalMP4(t)
+	original, _ := os.ReadFile(path)
+	before := topLevelBoxes(t, path)
+	oldMoov := before[len(before)-1]
+
+	samples := []TimedSample{
+		{Duration: time.Second, Data: []byte("amostra-1")},
+		{Duration: time.Second, Data: []byte("amostra-2-maior")},
+		{Duration: 500 * time.Millisecond, Data: []byte("3")},
+	}
+	if err := AddTimedMetadataTrack(path, "gpmd", "GoPro MET", samples); err != nil {
+		t.Fatalf("AddTimedMetadataTrack: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	boxes := topLevelBoxes(t, path)
+	want := []string{"ftyp", "mdat", "free", "mdat", "moov"}
+	if got := boxTypes(boxes); len(got) != len(want) {
+		t.Fatalf("caixas de primeiro nível: esperado %v, obtido %v", want, got)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("caixas de primeiro nível: esperado %v, obtido %v", want, got)
+			}
+		}
+	}
+
+	// Tudo antes do moov antigo fica intacto e o moov antigo só muda de tipo
+	if !bytes.Equal(data[:oldMoov.offset+4], original[:oldMoov.offset+4]) {
+		t.Error("os dados anteriores ao moov antigo foram alterados")
+	}
+	if boxes[2].size != oldMoov.size || !bytes.Equal(data[oldMoov.offset+8:oldMoov.offset+oldMoov.size], original[oldMoov.offset+8:]) {
+		t.Error("o moov antigo deveria virar uma caixa free do mesmo tamanho")
+	}
+
+	moov := data[boxes[4].offset : boxes[4].offset+boxes[4].size]
+	_, nextTrackID, err := movieHeaderInfo(moov)
+	if err != nil {
+		t.Fatalf("movieHeaderInfo: %v", err)
+	}
+	if nextTrackID != 3 {
+		t.Errorf("next_track_ID: esperado 3, obtido %d", nextTrackID)
+	}
+
+	var traks [][]byte
+	for _, child := range childBoxes(moov[8:]) {
+		if string(child[4:8]) == "trak" {
+			traks = append(traks, child)
+		}
+	}
+	if len(traks) != 2 {
+		t.Fatalf("esperava 2 faixas no novo moov, obtidas %d", len(traks))
+	}
+
+	// Os offsets do co64 apontam para as amostras gravadas no novo mdat
+	stbl := traks[1][8:]
+	for _, name := range []string{"mdia", "minf", "stbl"} {
+		child, ok := findChildBox(stbl, name)
+		if !ok {
+			t.Fatalf("caixa %s não encontrada na faixa de metadados", name)
+		}
+		stbl = child[8:]
+	}
+	co64, ok := findChildBox(stbl, "co64")
+	if !ok {
+		t.Fatal("co64 não encontrado na faixa de metadados")
+	}
+	if count := binary.BigEndian.Uint32(co64[12:]); int(count) != len(samples) {
+		t.Fatalf("co64 com %d offsets, esperado %d", count, len(samples))
+	}
+	for i, sample := range samples {
+		offset := binary.BigEndian.Uint64(co64[16+8*i:])
+		if offset < uint64(boxes[3].offset) || offset+uint64(len(sample.Data)) > uint64(boxes[3].offset+boxes[3].size) {
+			t.Fatalf("amostra %d fora do novo mdat: offset %d", i, offset)
+		}
+		if got := data[offset : offset+uint64(len(sample.Data))]; !bytes.Equal(got, sample.Data) {
+			t.Errorf("amostra %d: esperado %q, obtido %q", i, sample.Data, got)
+		}
+	}
+}
+
+func TestAddTimedMetadataTrackLeavesFileOnError(t *testing.T) {
+	samples := []TimedSample{{Duration: time.Second, Data: []byte("amostra")}}
+
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, path string)
+		format  string
+		samples []TimedSample
+	}{
+		{"sem amostras", func(*testing.T, string) {}, "gpmd", nil},
+		{"formato inválido", func(*testing.T, string) {}, "gpmf1", samples},
+		{"moov duplicado", func(t *testing.T, path string) {
+			data, _ := os.ReadFile(path)
+			boxes := topLevelBoxes(t, path)
+			last := boxes[len(boxes)-1]
+			data = append(data, data[last.offset:]...)
+			os.WriteFile(path, data, 0644)
+		}, "gpmd", samples},
+		{"sem moov", func(t *testing.T, path string) {
+			boxes := topLevelBoxes(t, path)
+			os.Truncate(path, boxes[len(boxes)-1].offset)
+		}, "gpmd", samples},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := minimalMP4(t)
+			tt.prepare(t, path)
+			original, _ := os.ReadFile(path)
+
+			if err := AddTimedMetadataTrack(path, tt.format, "GoPro MET", tt.samples); err == nil {
+				t.Fatal("esperava erro")
+			}
+			if data, _ := os.ReadFile(path); !bytes.Equal(data, original) {
+				t.Error("o arquivo foi alterado apesar do erro")
+			}
+		})
+	}
+}
@@ -5,21 +5,35 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 type Processor struct {
 	progressCallback func(progress float64)
-	cmd              *exec.Cmd // Para cancelamento
+	metadata         map[string]string
+	subtitles        []string     // Legendas incluídas como faixas no arquivo de saída
+	attachments      []Attachment // Arquivos anexados ao container (somente MKV)
+	windows          []TimeWindow // Trechos renderizados, em sequência; vazio = vídeo inteiro
+	cmd              *exec.Cmd    // Para cancelamento
 }
 
 type ProgressCallback func(progress float64)
 
+// Attachment é um arquivo anexado ao container de saída (ex.: telemetria em JSON no MKV)
+type Attachment struct {
+	Path     string
+	MimeType string
+	FileName string
+}
+
 func NewProcessor() *Processor {
 	return &Processor{}
 }
@@ -28,7 +42,32 @@ func (p *Processor) SetProgressCallback(callback ProgressCallback) {
 	p.progressCallback = callback
 }
 
-func (p *Processor) ApplyOverlaysWithPosition(ctx context.Context, inputVideo string, overlayImages []string, outputPath string, position string) error {
+// SetMetadata define tags gravadas no container de saída (ex.: atleta e atividade de origem)
+func (p *Processor) SetMetadata(metadata map[string]string) {
+	p.metadata = metadata
+}
+
+// SetSubtitles define arquivos de legenda (SRT, WebVTT ou ASS) incluídos como faixas de legenda
+func (p *Processor) SetSubtitles(paths []string) {
+	p.subtitles = paths
+}
+
+// SetAttachments define arquivos anexados ao vídeo de saída. Apenas o Matroska aceita anexos;
+// nos demais containers eles são ignorados.
+func (p *Processor) SetAttachments(attachments []Attachment) {
+	p.attachments = attachments
+}
+
+// SetWindows restringe a renderização aos trechos informados, concatenados na ordem dada
+// (ex.: um corte único ou um highlight reel)
+func (p *Processor) SetWindows(windows []TimeWindow) {
+	p.windows = windows
+}
+
+// ApplyOverlaysWithPosition aplica a sequência de overlays ao vídeo. Com trechos definidos
+// (SetWindows), apenas eles são renderizados, em sequência; os overlays devem cobrir os trechos
+// na mesma ordem. Com draft, o vídeo sai reduzido, com menos quadros e preset ultrafast.
+func (p *Processor) ApplyOverlaysWithPosition(ctx context.Context, inputVideo string, overlayImages []string, outputPath string, position string, draft *DraftSettings) error {
 	if len(overlayImages) == 0 {
 		return fmt.Errorf("nenhuma imagem de overlay fornecida")
 	}
@@ -38,10 +77,18 @@ func (p *Processor) ApplyOverlaysWithPosition(ctx context.Context, inputVideo st
 		return fmt.Errorf("erro ao obter metadados do vídeo: %w", err)
 	}
 
+	windows := clampWindows(p.windows, metadata.Duration)
+	if len(p.windows) > 0 && len(windows) == 0 {
+		return fmt.Errorf("nenhum trecho dentro da duração do vídeo (%.1fs)", metadata.Duration.Seconds())
+	}
+
 	tempDir := filepath.Dir(overlayImages[0])
 	listFile := filepath.Join(tempDir, "overlay_list.txt")
 
 	totalDuration := metadata.Duration.Seconds()
+	if len(windows) > 0 {
+		totalDuration = windowsDuration(windows).Seconds()
+	}
 	durationPerImage := totalDuration / float64(len(overlayImages))
 
 	err = p.createImageListWithDuration(overlayImages, listFile, durationPerImage)
@@ -49,29 +96,54 @@ func (p *Processor) ApplyOverlaysWithPosition(ctx context.Context, inputVideo st
 		return fmt.Errorf("erro ao criar a lista de imagens para o FFmpeg: %w", err)
 	}
 
-	overlayX, overlayY := p.calculateOverlayCoordinates(position)
-
-	filterComplex := fmt.Sprintf(
-		"[1:v]format=rgba,setpts=PTS-STARTPTS[ovr];[0:v][ovr]overlay=%s:%s",
-		overlayX, overlayY,
-	)
-
-	p.cmd = exec.CommandContext(ctx, "ffmpeg",
-		"-i", inputVideo,
+	// Entradas: o vídeo (uma vez por trecho), a lista de overlays e as legendas
+	videoInputs := max(len(windows), 1)
+	var args []string
+	if len(windows) == 0 {
+		args = append(args, "-i", inputVideo)
+	}
+	for _, window := range windows {
+		// -ss/-t como opções de entrada: o ffmpeg busca direto no trecho, com precisão de quadro
+		args = append(args,
+			"-ss", fmt.Sprintf("%.3f", window.Start.Seconds()),
+			"-t", fmt.Sprintf("%.3f", window.Duration().Seconds()),
+			"-i", inputVideo,
+		)
+	}
+	args = append(args,
 		"-f", "concat",
 		"-safe", "0",
 		"-i", listFile,
-		"-filter_complex", filterComplex,
-		"-map_metadata", "0",
-		"-c:a", "copy",
+	)
+	args = append(args, p.subtitleInputArgs()...)
+
+	filterComplex, audioLabel := p.buildFilterComplex(position, metadata, videoInputs, draft)
+	args = append(args, "-filter_complex", filterComplex)
+	args = append(args, p.streamMapArgs(outputPath, videoInputs, audioLabel)...)
+	args = append(args, "-map_metadata", "0")
+	args = append(args, p.metadataArgs(outputPath)...)
+	args = append(args, p.attachmentArgs(outputPath)...)
+	preset, crf := "fast", "18"
+	if draft != nil {
+		preset, crf = "ultrafast", "28"
+	}
+	audioCodec := "copy"
+	if audioLabel != "" {
+		// Áudio dos trechos concatenados passa pelo filtro e precisa ser recodificado
+		audioCodec = "aac"
+	}
+	args = append(args,
+		"-c:a", audioCodec,
 		"-c:v", "libx264",
-		"-preset", "fast",
-		"-crf", "18",
+		"-preset", preset,
+		"-crf", crf,
 		"-progress", "pipe:1",
 		"-y",
 		outputPath,
 	)
 
+	p.cmd = exec.CommandContext(ctx, "ffmpeg", args...)
+
 	stdout, err := p.cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("erro ao criar pipe stdout: %w", err)
@@ -109,6 +181,160 @@ func (p *Processor) ApplyOverlaysWithPosition(ctx context.Context, inputVideo st
 	return nil
 }
 
+// buildFilterComplex monta o filtro: concatena os trechos quando há mais de um, reduz o vídeo
+// no rascunho e sobrepõe os overlays. Retorna também o rótulo do áudio concatenado, vazio
+// quando o áudio vem direto da entrada.
+func (p *Processor) buildFilterComplex(position string, metadata *VideoMetadata, videoInputs int, draft *DraftSettings) (string, string) {
+	var filters []string
+	base, audioLabel := "[0:v]", ""
+
+	if videoInputs > 1 {
+		var segments strings.Builder
+		for i := 0; i < videoInputs; i++ {
+			segments.WriteString(fmt.Sprintf("[%d:v]", i))
+			if metadata.HasAudio {
+				segments.WriteString(fmt.Sprintf("[%d:a]", i))
+			}
+		}
+		if metadata.HasAudio {
+			filters = append(filters, fmt.Sprintf("%sconcat=n=%d:v=1:a=1[cat][acat]", segments.String(), videoInputs))
+			audioLabel = "[acat]"
+		} else {
+			filters = append(filters, fmt.Sprintf("%sconcat=n=%d:v=1:a=0[cat]", segments.String(), videoInputs))
+		}
+		base = "[cat]"
+	}
+
+	width, height := metadata.Width, metadata.Height
+	if draft != nil {
+		// O overlay já é gerado na resolução do rascunho (ver DraftSettings.Scale)
+		scale := draft.Scale(metadata.Width, metadata.Height)
+		filters = append(filters, fmt.Sprintf("%sfps=%g,scale=trunc(iw*%.6f/2)*2:trunc(ih*%.6f/2)*2[base]",
+			base, draft.FrameRateFor(metadata.FrameRate), scale, scale))
+		base = "[base]"
+		width, height = int(float64(width)*scale), int(float64(height)*scale)
+	}
+
+	overlayX, overlayY := p.calculateOverlayCoordinates(position, overlayMarginFor(width, height))
+	filters = append(filters,
+		fmt.Sprintf("[%d:v]format=rgba,setpts=PTS-STARTPTS[ovr]", videoInputs),
+		fmt.Sprintf("%s[ovr]overlay=%s:%s", base, overlayX, overlayY),
+	)
+
+	filterComplex := strings.Join(filters, ";")
+	if len(p.subtitles) > 0 || audioLabel != "" {
+		// Com legendas ou áudio concatenado os streams são mapeados explicitamente (ver streamMapArgs)
+		filterComplex += "[vout]"
+	}
+	return filterComplex, audioLabel
+}
+
+// subtitleInputArgs adiciona as legendas como entradas após o vídeo e os overlays
+func (p *Processor) subtitleInputArgs() []string {
+	var args []string
+	for _, path := range p.subtitles {
+		args = append(args, "-i", path)
+	}
+	return args
+}
+
+// streamMapArgs seleciona explicitamente vídeo, áudio e legendas quando o filtro não basta: a
+// seleção automática do ffmpeg incluiria no máximo uma faixa de legenda e ignoraria o áudio
+// concatenado. MP4/MOV só aceitam mov_text; nos demais containers (MKV) as legendas são
+// copiadas sem conversão, preservando o estilo do ASS.
+func (p *Processor) streamMapArgs(outputPath string, videoInputs int, audioLabel string) []string {
+	if len(p.subtitles) == 0 && audioLabel == "" {
+		return nil
+	}
+
+	args := []string{"-map", "[vout]"}
+	if audioLabel != "" {
+		args = append(args, "-map", audioLabel)
+	} else {
+		args = append(args, "-map", "0:a?")
+	}
+
+	if len(p.subtitles) == 0 {
+		return args
+	}
+
+	// Legendas vêm depois das entradas de vídeo e da lista de overlays
+	firstSubtitle := videoInputs + 1
+	for i, path := range p.subtitles {
+		format := strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))
+		args = append(args,
+			"-map", fmt.Sprintf("%d:s", firstSubtitle+i),
+			fmt.Sprintf("-metadata:s:s:%d", i), fmt.Sprintf("title=Telemetry (%s)", format),
+			fmt.Sprintf("-metadata:s:s:%d", i), "language=und",
+		)
+	}
+
+	codec := "copy"
+	if IsMOVContainer(outputPath) {
+		codec = "mov_text"
+	}
+	return append(args, "-c:s", codec)
+}
+
+// attachmentArgs anexa os arquivos ao MKV com o tipo MIME e o nome exibido de cada um
+func (p *Processor) attachmentArgs(outputPath string) []string {
+	if len(p.attachments) == 0 {
+		return nil
+	}
+	if !IsMKVContainer(outputPath) {
+		log.Printf("⚠️ Anexos ignorados: o container %s não aceita anexos", filepath.Ext(outputPath))
+		return nil
+	}
+
+	var args []string
+	for i, attachment := range p.attachments {
+		args = append(args,
+			"-attach", attachment.Path,
+			fmt.Sprintf("-metadata:s:t:%d", i), "mimetype="+attachment.MimeType,
+			fmt.Sprintf("-metadata:s:t:%d", i), "filename="+attachment.FileName,
+		)
+	}
+	return args
+}
+
+// IsMOVContainer indica se a saída usa a família MP4/MOV, que leva legendas mov_text, tags
+// personalizadas e a faixa de telemetria GPMF
+func IsMOVContainer(outputPath string) bool {
+	switch strings.ToLower(filepath.Ext(outputPath)) {
+	case ".mp4", ".mov", ".m4v":
+		return true
+	}
+	return false
+}
+
+// IsMKVContainer indica se a saída é Matroska, o único container que aceita anexos
+func IsMKVContainer(outputPath string) bool {
+	return strings.EqualFold(filepath.Ext(outputPath), ".mkv")
+}
+
+// metadataArgs converte as tags de metadados em argumentos do ffmpeg, em ordem estável
+func (p *Processor) metadataArgs(outputPath string) []string {
+	keys := make([]string, 0, len(p.metadata))
+	for key := range p.metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// use_metadata_tags permite gravar chaves personalizadas no MP4
+	var args []string
+	if IsMOVContainer(outputPath) {
+		args = append(args, "-movflags", "+use_metadata_tags")
+	}
+	for _, key := range keys {
+		args = append(args, "-metadata", fmt.Sprintf("%s=%s", key, p.metadata[key]))
+	}
+	return args
+}
+
 func (p *Processor) monitorFFmpegProgress(reader io.Reader, totalDuration float64) {
 	timeRegex := regexp.MustCompile(`out_time_ms=(\d+)`)
 
@@ -134,8 +360,20 @@ func (p *Processor) monitorFFmpegProgress(reader io.Reader, totalDuration float6
 	}
 }
 
-func (p *Processor) calculateOverlayCoordinates(position string) (string, string) {
-	margin := "10"
+// overlayMargin é a distância em pixels entre o overlay e as bordas de um vídeo 1080p
+const overlayMargin = 10
+
+// overlayMarginFor escala a margem pelo lado menor do vídeo, como o próprio overlay
+func overlayMarginFor(width, height int) int {
+	shortSide := min(width, height)
+	if shortSide <= 0 {
+		return overlayMargin
+	}
+	return max(2, int(math.Round(overlayMargin*float64(shortSide)/1080)))
+}
+
+func (p *Processor) calculateOverlayCoordinates(position string, marginPixels int) (string, string) {
+	margin := strconv.Itoa(marginPixels)
 
 	switch position {
 	case "top-left":
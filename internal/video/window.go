@@ -0,0 +1,36 @@
+package video
+
+import "time"
+
+// TimeWindow é um trecho do vídeo de entrada, em tempo do vídeo
+type TimeWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Duration retorna a duração do trecho
+func (w TimeWindow) Duration() time.Duration {
+	return w.End - w.Start
+}
+
+// windowsDuration soma a duração dos trechos, que no vídeo de saída ficam em sequência
+func windowsDuration(windows []TimeWindow) time.Duration {
+	var total time.Duration
+	for _, window := range windows {
+		total += window.Duration()
+	}
+	return total
+}
+
+// clampWindows limita os trechos à duração do vídeo, descartando os vazios ou fora dele
+func clampWindows(windows []TimeWindow, videoDuration time.Duration) []TimeWindow {
+	var clamped []TimeWindow
+	for _, window := range windows {
+		window.Start = max(window.Start, 0)
+		window.End = min(window.End, videoDuration)
+		if window.End > window.Start {
+			clamped = append(clamped, window)
+		}
+	}
+	return clamped
+}